@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"make-sync/internal/devsync"
+
+	"github.com/spf13/cobra"
+)
+
+var ctlJSON bool
+
+var ctlCmd = &cobra.Command{
+	Use:   "ctl",
+	Short: "Control a running devsync instance",
+	Long: `Talk to the devsync instance running in the current directory through its
+local control API (` + devsync.ControlSocketFile + `, a named pipe on Windows).`,
+}
+
+// newCtlOpCmd builds a ctl subcommand that sends a single op and prints the
+// reply. Ops taking a path (mute/unmute) pass withPath.
+func newCtlOpCmd(op, short string, withPath bool) *cobra.Command {
+	use, argsCheck := op, cobra.NoArgs
+	if withPath {
+		use, argsCheck = op+" <path>", cobra.ExactArgs(1)
+	}
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  argsCheck,
+		Run: func(cmd *cobra.Command, args []string) {
+			cwd, _ := os.Getwd()
+			req := devsync.ControlRequest{Op: op}
+			if withPath {
+				req.Path = args[0]
+			}
+			resp, err := devsync.SendControlRequest(cwd, req)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ No running devsync reachable: %v\n", err)
+				os.Exit(1)
+			}
+			if ctlJSON {
+				out, _ := json.Marshal(resp)
+				fmt.Println(string(out))
+			} else if resp.Status != nil {
+				printCtlStatus(resp.Status)
+			} else if resp.OK {
+				fmt.Printf("✅ %s\n", resp.Message)
+			}
+			if !resp.OK {
+				if !ctlJSON {
+					fmt.Fprintf(os.Stderr, "❌ %s\n", resp.Error)
+				}
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+var ctlEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Stream sync events until interrupted",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cwd, _ := os.Getwd()
+		stop := make(chan struct{})
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigCh
+			close(stop)
+		}()
+
+		err := devsync.StreamControlEvents(cwd, stop, func(ev devsync.ControlEvent) {
+			if ctlJSON {
+				out, _ := json.Marshal(ev)
+				fmt.Println(string(out))
+				return
+			}
+			line := fmt.Sprintf("[%s] %s", ev.Time.Format("15:04:05"), ev.Type)
+			if ev.Path != "" {
+				line += " " + ev.Path
+			}
+			if ev.Detail != "" {
+				line += " (" + ev.Detail + ")"
+			}
+			fmt.Println(line)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func printCtlStatus(st *devsync.ControlStatus) {
+	state := "idle (menu)"
+	if st.Watching {
+		state = "watching"
+	}
+	if st.Paused {
+		state += ", uploads paused"
+	}
+	if st.Reconciling {
+		state += ", reconciling"
+	}
+	muted := "none"
+	if len(st.Muted) > 0 {
+		muted = strings.Join(st.Muted, ", ")
+	}
+	lastSync := "never"
+	if !st.LastSync.IsZero() {
+		lastSync = fmt.Sprintf("%s ago (%s)", time.Since(st.LastSync).Round(time.Second), st.LastSyncPath)
+	}
+	agent := st.AgentHealth
+	if st.AgentPID != "" {
+		agent = fmt.Sprintf("pid %s, %s", st.AgentPID, st.AgentHealth)
+	}
+
+	fmt.Printf("📡 devsync pid %d — %s\n", st.PID, state)
+	fmt.Printf("📁 Watch path:  %s\n", st.WatchPath)
+	fmt.Printf("⏱️  Uptime:      %s\n", time.Since(st.StartedAt).Round(time.Second))
+	fmt.Printf("📬 Queue depth: %d\n", st.QueueDepth)
+	fmt.Printf("🔄 Last sync:   %s\n", lastSync)
+	fmt.Printf("💓 Agent:       %s\n", agent)
+	fmt.Printf("🔇 Muted:       %s\n", muted)
+	for _, t := range st.Targets {
+		line := fmt.Sprintf("%s, %d synced, %d failed, %d waiting for retry", t.State, t.Synced, t.Failed, t.Retrying)
+		if t.LastError != "" {
+			line += " — " + t.LastError
+		}
+		fmt.Printf("🎯 Target %s: %s\n", t.Name, line)
+	}
+	for _, sl := range st.Slots {
+		name := sl.Name
+		if name == "" {
+			name = sl.Command
+		}
+		if sl.Broadcast {
+			name += " 📡"
+		}
+		if sl.GaveUp {
+			name += fmt.Sprintf(" 🛑 crash loop, exit %d", *sl.ExitCode)
+		} else if sl.ExitCode != nil {
+			name += fmt.Sprintf(" (exit %d, %d restarts)", *sl.ExitCode, sl.Restarts)
+		}
+		fmt.Printf("🪟 Slot %d: %s\n", sl.Slot, name)
+	}
+}
+
+func init() {
+	ctlCmd.PersistentFlags().BoolVar(&ctlJSON, "json", false, "Print raw JSON responses")
+	ctlCmd.AddCommand(
+		newCtlOpCmd(devsync.ControlOpStatus, "Show queue depth, last sync and agent health", false),
+		newCtlOpCmd(devsync.ControlOpPause, "Pause uploads (events stay buffered)", false),
+		newCtlOpCmd(devsync.ControlOpResume, "Resume uploads and run the buffered catch-up sync", false),
+		newCtlOpCmd(devsync.ControlOpReconcile, "Reconcile local and remote changes in both directions", false),
+		newCtlOpCmd(devsync.ControlOpReload, "Reload the configuration", false),
+		newCtlOpCmd(devsync.ControlOpMute, "Ignore events under a path until it is unmuted", true),
+		newCtlOpCmd(devsync.ControlOpUnmute, "Stop ignoring a path and sync what changed meanwhile", true),
+		ctlEventsCmd,
+	)
+}
@@ -272,6 +272,30 @@ var devsyncCmd = &cobra.Command{
 		util.Default.Println("✅ Configuration is valid and rendered!")
 		util.Default.ClearLine()
 
+		if direction, _ := cmd.Flags().GetString("direction"); direction != "" {
+			if !config.ValidDirection(direction) {
+				util.Default.Printf("❌ Unknown --direction %q (use %s, %s or %s)\n", direction, config.DirectionBidirectional, config.DirectionPushOnly, config.DirectionPullOnly)
+				util.Default.ClearLine()
+				return
+			}
+			cfg.Devsync.SessionDirection = config.NormalizeDirection(direction)
+		}
+
+		if headless, _ := cmd.Flags().GetBool("headless"); headless {
+			logFile, _ := cmd.Flags().GetString("log-file")
+			if logFile == "" {
+				logFile = devsync.DefaultHeadlessLogFile
+			}
+			util.Default.Printf("🕶️  Running headless, logging to %s\n", logFile)
+			util.Default.ClearLine()
+			if err := devsync.RunHeadless(cmd.Context(), cfg, logFile); err != nil {
+				util.Default.Printf("❌ Headless devsync failed: %v\n", err)
+				util.Default.ClearLine()
+				os.Exit(1)
+			}
+			return
+		}
+
 		// Run devsync mode menu
 		devsync.ShowDevSyncModeMenu(cmd.Context(), cfg)
 	},
@@ -285,9 +309,16 @@ func init() {
 	// register exec command
 	rootCmd.AddCommand(execCmd)
 	// register devsync command
+	devsyncCmd.Flags().Bool("headless", false, "Run file watching and sync without menus or a TTY (SIGHUP reloads config, SIGTERM stops)")
+	devsyncCmd.Flags().String("log-file", "", "Log file for --headless (default "+devsync.DefaultHeadlessLogFile+")")
+	devsyncCmd.Flags().String("direction", "", "Restrict this session to push_only or pull_only (default: devsync.direction)")
 	rootCmd.AddCommand(devsyncCmd)
 	// register path-info command
 	rootCmd.AddCommand(pathinfoCmd)
+	// register ctl command (control API of a running devsync)
+	rootCmd.AddCommand(ctlCmd)
+	// register sessions command (recorded PTY slots)
+	rootCmd.AddCommand(sessionsCmd)
 }
 
 func showRecentWorkspacesMenu() {
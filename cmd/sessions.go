@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"make-sync/internal/cast"
+	"make-sync/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	sessionsDir     string
+	replaySpeed     float64
+	replayIdleLimit float64
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List and replay recorded PTY sessions",
+	Long: `Recordings are asciinema v2 .cast files written by devsync when
+devsync.recording.enabled is set or a slot command ends with ">>> name.cast".
+They can also be played with the asciinema player.`,
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recordings, newest first",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := recordingsDir()
+		infos, err := cast.List(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to list %s: %v\n", dir, err)
+			os.Exit(1)
+		}
+		if len(infos) == 0 {
+			fmt.Printf("No recordings in %s\n", dir)
+			return
+		}
+		fmt.Printf("🎥 Recordings in %s\n", dir)
+		for _, info := range infos {
+			started := time.Unix(info.Header.Timestamp, 0).Format("2006-01-02 15:04:05")
+			fmt.Printf("  %-36s %s  %8s  %dx%d  %s\n",
+				filepath.Base(info.Path), started, info.Duration.Round(time.Second),
+				info.Header.Width, info.Header.Height, info.Header.Title)
+		}
+	},
+}
+
+var sessionsReplayCmd = &cobra.Command{
+	Use:   "replay <file>",
+	Short: "Play a recording back in the terminal",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+		if _, err := os.Stat(path); err != nil {
+			// bare names refer to the recordings directory
+			path = filepath.Join(recordingsDir(), args[0])
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		stop := make(chan struct{})
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigCh
+			close(stop)
+		}()
+
+		opts := cast.PlayOptions{
+			Speed:     replaySpeed,
+			IdleLimit: time.Duration(replayIdleLimit * float64(time.Second)),
+		}
+		err = cast.Play(f, os.Stdout, opts, stop)
+		// leave the terminal in a sane state whatever the recording did
+		fmt.Print("\x1b[0m\x1b[?25h\n")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Replay failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// recordingsDir returns --dir, or the directory configured in make-sync.yaml
+// when one is present in the current directory.
+func recordingsDir() string {
+	if sessionsDir != "" {
+		return sessionsDir
+	}
+	if config.ConfigExists() {
+		if cfg, err := config.LoadAndValidateConfig(); err == nil {
+			// make-sync.yaml lives in the project root, like devsync's LocalPath
+			cfg.Devsync.Auth.LocalPath = "."
+			return cfg.RecordingDir()
+		}
+	}
+	return filepath.FromSlash(config.DefaultRecordingDir)
+}
+
+func init() {
+	sessionsCmd.PersistentFlags().StringVar(&sessionsDir, "dir", "", "Recordings directory (default devsync.recording.dir or "+config.DefaultRecordingDir+")")
+	sessionsReplayCmd.Flags().Float64Var(&replaySpeed, "speed", 1, "Playback speed multiplier")
+	sessionsReplayCmd.Flags().Float64Var(&replayIdleLimit, "idle-limit", 2, "Cap pauses between events to this many seconds (0 keeps them)")
+	sessionsCmd.AddCommand(sessionsListCmd, sessionsReplayCmd)
+}
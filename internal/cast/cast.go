@@ -0,0 +1,318 @@
+// Package cast writes and plays terminal recordings in the asciinema v2
+// .cast format: a JSON header line followed by one JSON array per event,
+// [seconds, type, data], where type is "o" (output), "i" (input) or "r"
+// (resize, data "COLSxROWS").
+package cast
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Event types of the v2 format.
+const (
+	EventOutput = "o"
+	EventInput  = "i"
+	EventResize = "r"
+)
+
+// Header is the first line of a .cast file.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder appends events to a .cast file. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	f       *os.File
+	w       *bufio.Writer
+	start   time.Time
+	width   int
+	height  int
+	pending map[string][]byte // incomplete UTF-8 tail per event type
+	closed  bool
+}
+
+// NewRecorder creates path (and its directory) and writes the header.
+func NewRecorder(path string, width, height int, title string) (*Recorder, error) {
+	if width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	hdr, err := json.Marshal(Header{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r := &Recorder{f: f, w: bufio.NewWriter(f), start: start, width: width, height: height, pending: make(map[string][]byte)}
+	r.w.Write(hdr)
+	r.w.WriteByte('\n')
+	return r, r.w.Flush()
+}
+
+// Path returns the file the recorder writes to.
+func (r *Recorder) Path() string {
+	return r.f.Name()
+}
+
+// Output records bytes written to the terminal.
+func (r *Recorder) Output(b []byte) {
+	r.write(EventOutput, b)
+}
+
+// Input records bytes typed by the user.
+func (r *Recorder) Input(b []byte) {
+	r.write(EventInput, b)
+}
+
+// Resize records a terminal size change; unchanged sizes are skipped.
+func (r *Recorder) Resize(width, height int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || width <= 0 || height <= 0 || (width == r.width && height == r.height) {
+		return
+	}
+	r.width, r.height = width, height
+	r.event(EventResize, fmt.Sprintf("%dx%d", width, height))
+	r.w.Flush()
+}
+
+func (r *Recorder) write(typ string, b []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || len(b) == 0 {
+		return
+	}
+	// Chunks may split a UTF-8 sequence; hold the incomplete tail back
+	data := append(r.pending[typ], b...)
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	r.pending[typ] = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return
+	}
+	r.event(typ, strings.ToValidUTF8(string(data[:cut]), "�"))
+	r.w.Flush()
+}
+
+func (r *Recorder) event(typ, data string) {
+	line, err := json.Marshal([]interface{}{time.Since(r.start).Seconds(), typ, data})
+	if err != nil {
+		return
+	}
+	r.w.Write(line)
+	r.w.WriteByte('\n')
+}
+
+// Close flushes pending bytes and closes the file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	for _, typ := range []string{EventOutput, EventInput} {
+		if p := r.pending[typ]; len(p) > 0 {
+			r.event(typ, strings.ToValidUTF8(string(p), "�"))
+		}
+	}
+	if err := r.w.Flush(); err != nil {
+		r.f.Close()
+		return err
+	}
+	return r.f.Close()
+}
+
+// Event is one recorded event.
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+// Reader reads a .cast stream.
+type Reader struct {
+	Header Header
+	sc     *bufio.Scanner
+}
+
+// NewReader reads the header of a .cast stream.
+func NewReader(in io.Reader) (*Reader, error) {
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("empty recording")
+	}
+	var hdr Header
+	if err := json.Unmarshal(sc.Bytes(), &hdr); err != nil {
+		return nil, fmt.Errorf("invalid header: %v", err)
+	}
+	if hdr.Version != 2 {
+		return nil, fmt.Errorf("unsupported asciicast version %d", hdr.Version)
+	}
+	return &Reader{Header: hdr, sc: sc}, nil
+}
+
+// Next returns the next event, or io.EOF at the end of the stream.
+func (r *Reader) Next() (Event, error) {
+	for r.sc.Scan() {
+		line := strings.TrimSpace(r.sc.Text())
+		if line == "" {
+			continue
+		}
+		var raw []interface{}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil || len(raw) < 3 {
+			return Event{}, fmt.Errorf("invalid event line: %s", line)
+		}
+		t, _ := raw[0].(float64)
+		typ, _ := raw[1].(string)
+		data, _ := raw[2].(string)
+		return Event{Time: t, Type: typ, Data: data}, nil
+	}
+	if err := r.sc.Err(); err != nil {
+		return Event{}, err
+	}
+	return Event{}, io.EOF
+}
+
+// Info summarizes a recording file.
+type Info struct {
+	Path     string
+	Header   Header
+	Duration time.Duration
+	Size     int64
+}
+
+// Stat reads path and returns its summary.
+func Stat(path string) (Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return Info{}, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		return Info{}, err
+	}
+	info := Info{Path: path, Header: r.Header, Size: st.Size()}
+	for {
+		ev, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return info, err
+		}
+		info.Duration = time.Duration(ev.Time * float64(time.Second))
+	}
+	return info, nil
+}
+
+// List returns the recordings in dir, newest first. Unreadable files are
+// skipped.
+func List(dir string) ([]Info, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.cast"))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Info, 0, len(matches))
+	for _, m := range matches {
+		if info, err := Stat(m); err == nil {
+			out = append(out, info)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Header.Timestamp > out[j].Header.Timestamp })
+	return out, nil
+}
+
+// PlayOptions control playback speed.
+type PlayOptions struct {
+	// Speed multiplies playback speed; <= 0 means 1
+	Speed float64
+	// IdleLimit caps the pause between two events; 0 keeps original pauses
+	IdleLimit time.Duration
+}
+
+// Play writes the output events of in to out with their original timing.
+// stop, when closed, ends playback early.
+func Play(in io.Reader, out io.Writer, opts PlayOptions, stop <-chan struct{}) error {
+	r, err := NewReader(in)
+	if err != nil {
+		return err
+	}
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	last := 0.0
+	for {
+		ev, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if ev.Type != EventOutput {
+			continue
+		}
+		wait := time.Duration((ev.Time - last) / speed * float64(time.Second))
+		last = ev.Time
+		if opts.IdleLimit > 0 && wait > opts.IdleLimit {
+			wait = opts.IdleLimit
+		}
+		if wait > 0 {
+			select {
+			case <-stop:
+				return nil
+			case <-time.After(wait):
+			}
+		}
+		if _, err := io.WriteString(out, ev.Data); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package cast
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec", "slot3.cast")
+	rec, err := NewRecorder(path, 120, 40, "bash")
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	// "é" split across two chunks must come out as one event
+	rec.Output([]byte("caf\xc3"))
+	rec.Output([]byte("\xa9\r\n"))
+	rec.Input([]byte("ls\r"))
+	rec.Resize(120, 40) // unchanged, skipped
+	rec.Resize(100, 30)
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := NewReader(f)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	if r.Header.Version != 2 || r.Header.Width != 120 || r.Header.Height != 40 || r.Header.Title != "bash" {
+		t.Fatalf("unexpected header %+v", r.Header)
+	}
+	var got []Event
+	for {
+		ev, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		got = append(got, ev)
+	}
+	want := []Event{{Type: "o", Data: "caf"}, {Type: "o", Data: "é\r\n"}, {Type: "i", Data: "ls\r"}, {Type: "r", Data: "100x30"}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i].Type != want[i].Type || got[i].Data != want[i].Data {
+			t.Errorf("event %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	var out bytes.Buffer
+	f.Seek(0, io.SeekStart)
+	if err := Play(f, &out, PlayOptions{Speed: 1000}, nil); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	if out.String() != "café\r\n" {
+		t.Fatalf("replay printed %q", out.String())
+	}
+}
@@ -46,9 +46,39 @@ type Devsync struct {
 	// ManualTransferIgnores stores per-path ignore rules parsed from
 	// object-style manual_transfer entries. Keys are raw configured paths.
 	ManualTransferIgnores map[string][]string `yaml:"-"`
-	Concurrency           int                 `yaml:"concurrency,omitempty"`
-	Script                Script              `yaml:"script"`
-	TriggerPerm           TriggerPermission   `yaml:"trigger_permission"`
+	// ManualTransferWarnSize is the selection size in MB above which Single
+	// Sync asks for confirmation; 0 = default (500 MB), negative disables.
+	ManualTransferWarnSize int               `yaml:"manual_transfer_warn_size,omitempty"`
+	Concurrency            int               `yaml:"concurrency,omitempty"`
+	Script                 Script            `yaml:"script"`
+	TriggerPerm            TriggerPermission `yaml:"trigger_permission"`
+	Heartbeat              Heartbeat         `yaml:"heartbeat,omitempty"`
+	Indexing               IndexingThrottle  `yaml:"indexing,omitempty"`
+	StartupReconcile       StartupReconcile  `yaml:"startup_reconcile,omitempty"`
+	Hooks                  []Hook            `yaml:"hooks,omitempty"`
+	// Mappings syncs several local roots to their own remote roots
+	Mappings []Mapping `yaml:"mappings,omitempty"`
+	// Mapping is set on configs narrowed by Config.ForMapping
+	Mapping *Mapping `yaml:"-"`
+	// Targets are extra remotes every local change is uploaded to
+	Targets []Target `yaml:"targets,omitempty"`
+	// Direction is bidirectional (default), push_only or pull_only;
+	// DirectionRules override it for matching paths
+	Direction      string          `yaml:"direction,omitempty"`
+	DirectionRules []DirectionRule `yaml:"direction_rules,omitempty"`
+	// SessionDirection is set by `devsync --direction` and restricts
+	// Direction for the running session
+	SessionDirection string `yaml:"-"`
+	// Recording writes PTY slots to asciinema .cast files
+	Recording Recording `yaml:"recording,omitempty"`
+	// Scrollback sizes the per-slot history of the scrollback viewer
+	Scrollback Scrollback `yaml:"scrollback,omitempty"`
+	// PersistentSessions keeps remote slots alive in tmux/screen
+	PersistentSessions PersistentSessions `yaml:"persistent_sessions,omitempty"`
+	// Sessions declare named PTY slots, optionally opened on start
+	Sessions []SessionSpec `yaml:"sessions,omitempty"`
+	// PathLinks links remote paths in slot output to local files
+	PathLinks PathLinks `yaml:"path_links,omitempty"`
 }
 
 // UnmarshalYAML supports dual manual_transfer format:
@@ -65,6 +95,21 @@ func (d *Devsync) UnmarshalYAML(value *yaml.Node) error {
 		Concurrency int               `yaml:"concurrency,omitempty"`
 		Script      Script            `yaml:"script"`
 		TriggerPerm TriggerPermission `yaml:"trigger_permission"`
+		Heartbeat   Heartbeat         `yaml:"heartbeat,omitempty"`
+
+		ManualTransferWarnSize int                `yaml:"manual_transfer_warn_size,omitempty"`
+		Indexing               IndexingThrottle   `yaml:"indexing,omitempty"`
+		StartupReconcile       StartupReconcile   `yaml:"startup_reconcile,omitempty"`
+		Hooks                  []Hook             `yaml:"hooks,omitempty"`
+		Mappings               []Mapping          `yaml:"mappings,omitempty"`
+		Targets                []Target           `yaml:"targets,omitempty"`
+		Direction              string             `yaml:"direction,omitempty"`
+		DirectionRules         []DirectionRule    `yaml:"direction_rules,omitempty"`
+		Recording              Recording          `yaml:"recording,omitempty"`
+		Scrollback             Scrollback         `yaml:"scrollback,omitempty"`
+		PersistentSessions     PersistentSessions `yaml:"persistent_sessions,omitempty"`
+		Sessions               []SessionSpec      `yaml:"sessions,omitempty"`
+		PathLinks              PathLinks          `yaml:"path_links,omitempty"`
 	}
 
 	var raw rawDevsync
@@ -85,9 +130,23 @@ func (d *Devsync) UnmarshalYAML(value *yaml.Node) error {
 	d.AgentWatchs = raw.AgentWatchs
 	d.ManualTransfer = manualPaths
 	d.ManualTransferIgnores = manualIgnores
+	d.ManualTransferWarnSize = raw.ManualTransferWarnSize
 	d.Concurrency = raw.Concurrency
 	d.Script = raw.Script
 	d.TriggerPerm = raw.TriggerPerm
+	d.Heartbeat = raw.Heartbeat
+	d.Indexing = raw.Indexing
+	d.StartupReconcile = raw.StartupReconcile
+	d.Hooks = raw.Hooks
+	d.Mappings = raw.Mappings
+	d.Targets = raw.Targets
+	d.Direction = raw.Direction
+	d.DirectionRules = raw.DirectionRules
+	d.Recording = raw.Recording
+	d.Scrollback = raw.Scrollback
+	d.PersistentSessions = raw.PersistentSessions
+	d.Sessions = raw.Sessions
+	d.PathLinks = raw.PathLinks
 
 	return nil
 }
@@ -210,11 +269,121 @@ type ScriptSection struct {
 	Commands []string `yaml:"commands,omitempty"`
 }
 
+// Hook runs Command after a synced file matching Glob was transferred or
+// deleted. Command is a template: {{path}} is the path on the side the
+// command runs on, {{rel}} the path relative to the project root and
+// {{event}} the sync event; each is quoted for the shell.
+type Hook struct {
+	Name string `yaml:"name,omitempty"`
+	// Glob uses the trigger_permission rule pattern syntax
+	Glob string `yaml:"glob"`
+	// Events limits the hook to upload, download, delete_remote,
+	// delete_local or move_remote; empty means all of them
+	Events []string `yaml:"events,omitempty"`
+	// Side is where Command runs: "remote" (default, from the remote path,
+	// on devsync.auth and on every devsync.targets entry the file reached)
+	// or "local" (from the local path, once)
+	Side    string `yaml:"side,omitempty"`
+	Command string `yaml:"command"`
+	// Debounce (ms) waits for matching events to settle and runs once for
+	// the last one
+	Debounce int `yaml:"debounce,omitempty"`
+	// Concurrency decides what happens when the hook fires while it is still
+	// running: "queue" (default, run once more afterwards), "skip" or
+	// "parallel"
+	Concurrency string `yaml:"concurrency,omitempty"`
+}
+
+// Hook concurrency policies.
+const (
+	HookQueue    = "queue"
+	HookSkip     = "skip"
+	HookParallel = "parallel"
+)
+
 type TriggerPermission struct {
 	UnlinkFolder bool `yaml:"unlink_folder"`
 	Unlink       bool `yaml:"unlink"`
 	Change       bool `yaml:"change"`
 	Add          bool `yaml:"add"`
+	// Rules override the flags above for matching paths (see Allows)
+	Rules []TriggerRule `yaml:"rules,omitempty"`
+}
+
+// Heartbeat controls remote agent liveness detection. The agent prints a
+// heartbeat every Interval seconds; after MaxMissed missed beats the watcher
+// kills and restarts it. Zero values use the defaults (10s, 3 beats); a
+// negative Interval disables heartbeats.
+type Heartbeat struct {
+	Interval  int `yaml:"interval,omitempty"`
+	MaxMissed int `yaml:"max_missed,omitempty"`
+}
+
+const (
+	DefaultHeartbeatInterval  = 10
+	DefaultHeartbeatMaxMissed = 3
+)
+
+// IntervalSeconds returns the effective heartbeat interval in seconds, or 0
+// when heartbeats are disabled.
+func (h Heartbeat) IntervalSeconds() int {
+	if h.Interval < 0 {
+		return 0
+	}
+	if h.Interval == 0 {
+		return DefaultHeartbeatInterval
+	}
+	return h.Interval
+}
+
+// MaxMissedBeats returns the effective number of missed beats tolerated
+// before the agent is considered unhealthy.
+func (h Heartbeat) MaxMissedBeats() int {
+	if h.MaxMissed <= 0 {
+		return DefaultHeartbeatMaxMissed
+	}
+	return h.MaxMissed
+}
+
+// IndexingThrottle limits the resources the remote agent uses while indexing.
+// Zero values keep the agent defaults (normal priority, one hashing worker,
+// unlimited read rate).
+type IndexingThrottle struct {
+	// Nice lowers CPU priority (1-19); on Windows > 0 means below normal and
+	// >= 15 means idle priority.
+	Nice int `yaml:"nice,omitempty"`
+	// IONice is "idle", "best-effort" or "best-effort:<0-7>"; on Windows
+	// "idle" enables background processing mode.
+	IONice string `yaml:"ionice,omitempty"`
+	// Workers is the maximum number of files hashed concurrently.
+	Workers int `yaml:"workers,omitempty"`
+	// ReadBytesPerSec caps the agent's read rate while hashing.
+	ReadBytesPerSec int64 `yaml:"read_bytes_per_sec,omitempty"`
+}
+
+// StartupReconcile makes devsync compare the remote index, the local file
+// cache and the local tree before it starts watching, and apply the changes
+// made on either side while it was not running. Paths changed on both sides
+// are reported as conflicts and left alone.
+type StartupReconcile struct {
+	Enabled bool `yaml:"enabled"`
+	// DryRun only prints the summary without transferring anything.
+	DryRun bool `yaml:"dry_run,omitempty"`
+}
+
+// DefaultManualTransferWarnSize is the default Single Sync warning threshold in MB.
+const DefaultManualTransferWarnSize = 500
+
+// ManualTransferWarnBytes returns the Single Sync size warning threshold in
+// bytes, or 0 when the warning is disabled.
+func (d Devsync) ManualTransferWarnBytes() int64 {
+	if d.ManualTransferWarnSize < 0 {
+		return 0
+	}
+	if d.ManualTransferWarnSize == 0 {
+		return DefaultManualTransferWarnSize * 1024 * 1024
+	}
+	return int64(d.ManualTransferWarnSize) * 1024 * 1024
 }
 
 type DirectAccess struct {
@@ -498,6 +667,41 @@ func ValidateConfig(cfg *Config) error {
 	if strings.TrimSpace(cfg.Devsync.OSTarget) == "" {
 		validationErrors = append(validationErrors, "devsync.os_target cannot be empty")
 	}
+	validationErrors = append(validationErrors, validateMappings(cfg.Devsync.Mappings)...)
+	if cfg.HasTargets() {
+		validationErrors = append(validationErrors, validateTargets(cfg)...)
+	}
+	validationErrors = append(validationErrors, validateDirections(cfg.Devsync)...)
+	validationErrors = append(validationErrors, validatePersistentSessions(cfg.Devsync)...)
+	validationErrors = append(validationErrors, validateSessions(cfg.Devsync.Sessions)...)
+	validationErrors = append(validationErrors, validatePathLinks(cfg.Devsync.PathLinks)...)
+	for i, h := range cfg.Devsync.Hooks {
+		idx := fmt.Sprintf("devsync.hooks[%d]", i)
+		if strings.TrimSpace(h.Glob) == "" {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s: glob cannot be empty", idx))
+		}
+		if strings.TrimSpace(h.Command) == "" {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s: command cannot be empty", idx))
+		}
+		if side := strings.ToLower(strings.TrimSpace(h.Side)); side != "" && side != "local" && side != "remote" {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s: side must be 'local' or 'remote'", idx))
+		}
+		switch strings.ToLower(strings.TrimSpace(h.Concurrency)) {
+		case "", HookQueue, HookSkip, HookParallel:
+		default:
+			validationErrors = append(validationErrors, fmt.Sprintf("%s: concurrency must be 'queue', 'skip' or 'parallel'", idx))
+		}
+		for _, ev := range h.Events {
+			switch strings.ToLower(strings.TrimSpace(ev)) {
+			case "upload", "download", "delete_remote", "delete_local", "move_remote":
+			default:
+				validationErrors = append(validationErrors, fmt.Sprintf("%s: unknown event %q", idx, ev))
+			}
+		}
+		if h.Debounce < 0 {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s: debounce cannot be negative", idx))
+		}
+	}
 
 	// If there are validation errors, return them
 	if len(validationErrors) > 0 {
@@ -768,6 +972,63 @@ func RenderTemplateVariablesInMemory(cfg *Config) (*Config, error) {
 		renderCount++
 	}
 
+	// Render mapping roots; copy first so the loaded config keeps its templates
+	if len(renderedCfg.Devsync.Mappings) > 0 {
+		renderedCfg.Devsync.Mappings = append([]Mapping(nil), renderedCfg.Devsync.Mappings...)
+	}
+	for i := range renderedCfg.Devsync.Mappings {
+		m := &renderedCfg.Devsync.Mappings[i]
+		if strings.HasPrefix(m.LocalPath, "=") {
+			oldValue := m.LocalPath
+			m.LocalPath = renderer.RenderComplexTemplates(m.LocalPath)
+			printer.Printf("🔧 Rendered Devsync.Mappings[%d].LocalPath: %s → %s\n", i, oldValue, m.LocalPath)
+			renderCount++
+		}
+		if strings.HasPrefix(m.RemotePath, "=") {
+			oldValue := m.RemotePath
+			m.RemotePath = renderer.RenderComplexTemplates(m.RemotePath)
+			printer.Printf("🔧 Rendered Devsync.Mappings[%d].RemotePath: %s → %s\n", i, oldValue, m.RemotePath)
+			renderCount++
+		}
+	}
+
+	// Render target connections
+	if len(renderedCfg.Devsync.Targets) > 0 {
+		renderedCfg.Devsync.Targets = append([]Target(nil), renderedCfg.Devsync.Targets...)
+	}
+	for i := range renderedCfg.Devsync.Targets {
+		t := &renderedCfg.Devsync.Targets[i]
+		for name, field := range map[string]*string{
+			"SSHConfig":       &t.SSHConfig,
+			"Auth.Username":   &t.Auth.Username,
+			"Auth.PrivateKey": &t.Auth.PrivateKey,
+			"Auth.Password":   &t.Auth.Password,
+			"Auth.Host":       &t.Auth.Host,
+			"Auth.Port":       &t.Auth.Port,
+			"Auth.RemotePath": &t.Auth.RemotePath,
+		} {
+			if strings.HasPrefix(*field, "=") {
+				oldValue := *field
+				*field = renderer.RenderComplexTemplates(*field)
+				printer.Printf("🔧 Rendered Devsync.Targets[%d].%s: %s → %s\n", i, name, oldValue, *field)
+				renderCount++
+			}
+		}
+	}
+
+	// Render devsync.sessions
+	for i := range renderedCfg.Devsync.Sessions {
+		sess := &renderedCfg.Devsync.Sessions[i]
+		for name, field := range map[string]*string{"Command": &sess.Command, "Dir": &sess.Dir} {
+			if strings.HasPrefix(*field, "=") {
+				oldValue := *field
+				*field = renderer.RenderComplexTemplates(*field)
+				printer.Printf("🔧 Rendered Devsync.Sessions[%d].%s: %s → %s\n", i, name, oldValue, *field)
+				renderCount++
+			}
+		}
+	}
+
 	// Render SSH commands
 	for i := range renderedCfg.DirectAccess.SSHCommands {
 		sshCmd := &renderedCfg.DirectAccess.SSHCommands[i]
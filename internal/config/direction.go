@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Sync directions for devsync.direction, direction_rules and mappings.
+const (
+	DirectionBidirectional = "bidirectional"
+	DirectionPushOnly      = "push_only"
+	DirectionPullOnly      = "pull_only"
+)
+
+// DirectionRule sets the sync direction of paths matching Pattern, which
+// uses the trigger_permission rule syntax relative to the project root.
+type DirectionRule struct {
+	Pattern   string `yaml:"pattern"`
+	Direction string `yaml:"direction"`
+}
+
+// NormalizeDirection lowercases d; empty means bidirectional.
+func NormalizeDirection(d string) string {
+	d = strings.ToLower(strings.TrimSpace(d))
+	if d == "" {
+		return DirectionBidirectional
+	}
+	return d
+}
+
+// ValidDirection reports whether d is a known sync direction.
+func ValidDirection(d string) bool {
+	switch NormalizeDirection(d) {
+	case DirectionBidirectional, DirectionPushOnly, DirectionPullOnly:
+		return true
+	}
+	return false
+}
+
+// DirectionFor returns the configured direction of rel (slash-separated,
+// relative to the project root) and what decided it. The first matching
+// direction_rules entry wins, then the direction of the deepest mapping
+// containing rel, then devsync.direction.
+func (c *Config) DirectionFor(rel string) (string, string) {
+	rel = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(rel, "\\", "/")), "/")
+	for i, r := range c.Devsync.DirectionRules {
+		if MatchTriggerPattern(r.Pattern, rel) {
+			return NormalizeDirection(r.Direction), fmt.Sprintf("direction_rules[%d] %q", i, r.Pattern)
+		}
+	}
+
+	best, bestLen := -1, -1
+	for i, m := range c.Devsync.Mappings {
+		if m.Direction == "" {
+			continue
+		}
+		root := strings.Trim(path.Clean(strings.ReplaceAll(strings.TrimSpace(m.LocalPath), "\\", "/")), "/")
+		if root != "." && rel != root && !strings.HasPrefix(rel, root+"/") {
+			continue
+		}
+		if len(root) > bestLen {
+			best, bestLen = i, len(root)
+		}
+	}
+	if best >= 0 {
+		return NormalizeDirection(c.Devsync.Mappings[best].Direction), fmt.Sprintf("mappings[%d].direction", best)
+	}
+	return NormalizeDirection(c.Devsync.Direction), "devsync.direction"
+}
+
+// AllowsPush reports whether local changes of rel may be uploaded, and what
+// decided. The session direction (--direction) applies on top of the config.
+func (c *Config) AllowsPush(rel string) (bool, string) {
+	if NormalizeDirection(c.Devsync.SessionDirection) == DirectionPullOnly {
+		return false, "session direction pull_only"
+	}
+	d, by := c.DirectionFor(rel)
+	return d != DirectionPullOnly, by
+}
+
+// AllowsPull reports whether remote changes of rel may be downloaded, and
+// what decided.
+func (c *Config) AllowsPull(rel string) (bool, string) {
+	if NormalizeDirection(c.Devsync.SessionDirection) == DirectionPushOnly {
+		return false, "session direction push_only"
+	}
+	d, by := c.DirectionFor(rel)
+	return d != DirectionPushOnly, by
+}
+
+// validateDirections checks devsync.direction, direction_rules and the
+// direction of every mapping.
+func validateDirections(d Devsync) []string {
+	var errs []string
+	invalid := func(field, v string) {
+		errs = append(errs, fmt.Sprintf("%s: unknown direction '%s' (use %s, %s or %s)", field, v, DirectionBidirectional, DirectionPushOnly, DirectionPullOnly))
+	}
+	if !ValidDirection(d.Direction) {
+		invalid("devsync.direction", d.Direction)
+	}
+	for i, r := range d.DirectionRules {
+		idx := fmt.Sprintf("devsync.direction_rules[%d]", i)
+		if strings.TrimSpace(r.Pattern) == "" {
+			errs = append(errs, fmt.Sprintf("%s: pattern cannot be empty", idx))
+		}
+		if !ValidDirection(r.Direction) {
+			invalid(idx, r.Direction)
+		}
+	}
+	for i, m := range d.Mappings {
+		if !ValidDirection(m.Direction) {
+			invalid(fmt.Sprintf("devsync.mappings[%d].direction", i), m.Direction)
+		}
+	}
+	return errs
+}
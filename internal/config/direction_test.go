@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDirectionFor(t *testing.T) {
+	yamlText := `
+devsync:
+  os_target: linux
+  direction: push_only
+  direction_rules:
+    - pattern: "storage/logs/**"
+      direction: pull_only
+    - pattern: "*.log"
+      direction: bidirectional
+  mappings:
+    - local_path: artifacts
+      remote_path: /srv/artifacts
+      direction: pull_only
+    - local_path: src
+      remote_path: /srv/src
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(yamlText), &cfg); err != nil {
+		t.Fatalf("yaml unmarshal failed: %v", err)
+	}
+
+	cases := []struct {
+		rel  string
+		want string
+		push bool
+		pull bool
+	}{
+		{"storage/logs/app.log", DirectionPullOnly, false, true},
+		{"tmp/debug.log", DirectionBidirectional, true, true},
+		{"artifacts/build/out.zip", DirectionPullOnly, false, true},
+		{"src/main.go", DirectionPushOnly, true, false},
+	}
+	for _, c := range cases {
+		got, by := cfg.DirectionFor(c.rel)
+		if got != c.want {
+			t.Errorf("%s: expected %s, got %s (%s)", c.rel, c.want, got, by)
+		}
+		if ok, _ := cfg.AllowsPush(c.rel); ok != c.push {
+			t.Errorf("%s: expected push allowed=%v", c.rel, c.push)
+		}
+		if ok, _ := cfg.AllowsPull(c.rel); ok != c.pull {
+			t.Errorf("%s: expected pull allowed=%v", c.rel, c.pull)
+		}
+	}
+
+	// the session direction narrows what the config allows
+	cfg.Devsync.SessionDirection = DirectionPullOnly
+	if ok, by := cfg.AllowsPush("tmp/debug.log"); ok || by != "session direction pull_only" {
+		t.Fatalf("expected session pull_only to block pushes, got %v (%s)", ok, by)
+	}
+}
+
+func TestValidateDirections(t *testing.T) {
+	d := Devsync{
+		Direction:      "sideways",
+		DirectionRules: []DirectionRule{{Pattern: "", Direction: "push_only"}},
+		Mappings:       []Mapping{{LocalPath: "a", RemotePath: "/a", Direction: "PULL_ONLY"}},
+	}
+	if errs := validateDirections(d); len(errs) != 2 {
+		t.Fatalf("expected 2 validation errors, got %d: %v", len(errs), errs)
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestHeartbeatParsingAndDefaults(t *testing.T) {
+	yamlText := `
+devsync:
+  os_target: linux
+  heartbeat:
+    interval: 5
+    max_missed: 4
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(yamlText), &cfg); err != nil {
+		t.Fatalf("yaml unmarshal failed: %v", err)
+	}
+	if got := cfg.Devsync.Heartbeat.IntervalSeconds(); got != 5 {
+		t.Fatalf("expected interval 5, got %d", got)
+	}
+	if got := cfg.Devsync.Heartbeat.MaxMissedBeats(); got != 4 {
+		t.Fatalf("expected max_missed 4, got %d", got)
+	}
+
+	var empty Heartbeat
+	if got := empty.IntervalSeconds(); got != DefaultHeartbeatInterval {
+		t.Fatalf("expected default interval %d, got %d", DefaultHeartbeatInterval, got)
+	}
+	if got := empty.MaxMissedBeats(); got != DefaultHeartbeatMaxMissed {
+		t.Fatalf("expected default max_missed %d, got %d", DefaultHeartbeatMaxMissed, got)
+	}
+
+	disabled := Heartbeat{Interval: -1}
+	if got := disabled.IntervalSeconds(); got != 0 {
+		t.Fatalf("expected negative interval to disable heartbeat, got %d", got)
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"make-sync/internal/util"
+)
+
+// Mapping syncs one local root of the project to one remote root. Projects
+// without devsync.mappings use auth.localPath → auth.remotePath as their
+// only mapping.
+type Mapping struct {
+	Name string `yaml:"name,omitempty"`
+	// LocalPath is relative to the project root.
+	LocalPath  string `yaml:"local_path"`
+	RemotePath string `yaml:"remote_path"`
+	// Ignores are matched relative to LocalPath, on top of .sync_ignore.
+	Ignores []string `yaml:"ignores,omitempty"`
+	// AgentWatchs are relative to RemotePath; empty watches RemotePath.
+	AgentWatchs []string `yaml:"agent_watchs,omitempty"`
+	// TriggerPerm replaces devsync.trigger_permission for this mapping.
+	TriggerPerm *TriggerPermission `yaml:"trigger_permission,omitempty"`
+	// Direction replaces devsync.direction for this mapping.
+	Direction string `yaml:"direction,omitempty"`
+}
+
+// Label is how a mapping is shown in output.
+func (m Mapping) Label() string {
+	if m.Name != "" {
+		return m.Name
+	}
+	return filepath.Base(m.LocalPath)
+}
+
+// HasMappings reports whether devsync.mappings is configured.
+func (c *Config) HasMappings() bool {
+	return len(c.Devsync.Mappings) > 0
+}
+
+// projectRoot is the local root mappings are resolved against.
+func (c *Config) projectRoot() string {
+	if c.LocalPath != "" {
+		return c.LocalPath
+	}
+	return c.Devsync.Auth.LocalPath
+}
+
+// PathMappings returns the effective mappings with LocalPath made absolute.
+func (c *Config) PathMappings() []Mapping {
+	root := c.projectRoot()
+	if !c.HasMappings() {
+		return []Mapping{{
+			LocalPath:   root,
+			RemotePath:  c.Devsync.Auth.RemotePath,
+			Ignores:     c.Devsync.Ignores,
+			AgentWatchs: c.Devsync.AgentWatchs,
+		}}
+	}
+	out := make([]Mapping, 0, len(c.Devsync.Mappings))
+	for _, m := range c.Devsync.Mappings {
+		local := filepath.FromSlash(strings.TrimSpace(m.LocalPath))
+		if !filepath.IsAbs(local) {
+			local = filepath.Join(root, local)
+		}
+		m.LocalPath = filepath.Clean(local)
+		m.RemotePath = cleanRemoteRoot(m.RemotePath)
+		out = append(out, m)
+	}
+	return out
+}
+
+// PathMap returns the mapping table used to translate paths between the
+// local and remote side.
+func (c *Config) PathMap() util.PathMap {
+	mappings := c.PathMappings()
+	pm := make(util.PathMap, 0, len(mappings))
+	for _, m := range mappings {
+		pm = append(pm, util.PathMapping{Local: m.LocalPath, Remote: m.RemotePath})
+	}
+	return pm
+}
+
+// AgentWatchPaths returns the paths the remote agent watches. agent_watchs
+// stays relative to auth.remotePath; mapping watch paths are resolved
+// against their remote root.
+func (c *Config) AgentWatchPaths() []string {
+	if !c.HasMappings() {
+		return c.Devsync.AgentWatchs
+	}
+	out := append([]string{}, c.Devsync.AgentWatchs...)
+	for _, m := range c.PathMappings() {
+		if len(m.AgentWatchs) == 0 {
+			out = append(out, m.RemotePath)
+			continue
+		}
+		for _, w := range m.AgentWatchs {
+			w = strings.ReplaceAll(strings.TrimSpace(w), "\\", "/")
+			if path.IsAbs(w) || filepath.IsAbs(w) {
+				out = append(out, w)
+			} else {
+				out = append(out, path.Join(m.RemotePath, w))
+			}
+		}
+	}
+	return out
+}
+
+// ForMapping returns a copy of c narrowed to m, so flows written for a single
+// root (pull, push, agent indexing) can run once per mapping. m must come
+// from PathMappings.
+func (c *Config) ForMapping(m Mapping) *Config {
+	cp := *c
+	root := c.projectRoot()
+	cp.LocalPath = m.LocalPath
+	cp.Devsync.Auth.LocalPath = m.LocalPath
+	cp.Devsync.Auth.RemotePath = m.RemotePath
+	cp.Devsync.Ignores = m.Ignores
+	cp.Devsync.AgentWatchs = m.AgentWatchs
+	cp.Devsync.Mappings = nil
+	mm := m
+	cp.Devsync.Mapping = &mm
+	if m.TriggerPerm != nil {
+		cp.Devsync.TriggerPerm = *m.TriggerPerm
+	}
+
+	// manual_transfer entries are relative to the project root; keep the
+	// ones inside this mapping, rebased onto its local root
+	cp.Devsync.ManualTransfer = nil
+	cp.Devsync.ManualTransferIgnores = make(map[string][]string)
+	for _, p := range c.Devsync.ManualTransfer {
+		rel, err := filepath.Rel(m.LocalPath, filepath.Join(root, filepath.FromSlash(p)))
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		cp.Devsync.ManualTransfer = append(cp.Devsync.ManualTransfer, rel)
+		if ign, ok := c.Devsync.ManualTransferIgnores[p]; ok {
+			cp.Devsync.ManualTransferIgnores[rel] = ign
+		}
+	}
+	return &cp
+}
+
+func cleanRemoteRoot(p string) string {
+	p = strings.TrimSpace(p)
+	if strings.Contains(p, "\\") {
+		// Windows remote roots keep their separators
+		return strings.TrimRight(p, "\\")
+	}
+	if p == "" {
+		return p
+	}
+	return path.Clean(p)
+}
+
+// validateMappings checks devsync.mappings; local roots must stay inside the
+// project and no two mappings may share a local or remote root.
+func validateMappings(mappings []Mapping) []string {
+	var errs []string
+	seenLocal := map[string]int{}
+	seenRemote := map[string]int{}
+	for i, m := range mappings {
+		idx := fmt.Sprintf("devsync.mappings[%d]", i)
+		local := strings.TrimSpace(m.LocalPath)
+		if local == "" {
+			errs = append(errs, fmt.Sprintf("%s: local_path cannot be empty", idx))
+		} else {
+			clean := path.Clean(strings.ReplaceAll(local, "\\", "/"))
+			if path.IsAbs(clean) || filepath.IsAbs(local) || clean == ".." || strings.HasPrefix(clean, "../") {
+				errs = append(errs, fmt.Sprintf("%s: local_path must be relative to the project root", idx))
+			} else if j, dup := seenLocal[clean]; dup {
+				errs = append(errs, fmt.Sprintf("%s: local_path duplicates devsync.mappings[%d]", idx, j))
+			} else {
+				seenLocal[clean] = i
+			}
+		}
+		remote := cleanRemoteRoot(m.RemotePath)
+		if remote == "" {
+			errs = append(errs, fmt.Sprintf("%s: remote_path cannot be empty", idx))
+		} else if j, dup := seenRemote[remote]; dup {
+			errs = append(errs, fmt.Sprintf("%s: remote_path duplicates devsync.mappings[%d]", idx, j))
+		} else {
+			seenRemote[remote] = i
+		}
+	}
+	return errs
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMappingsParseAndNarrow(t *testing.T) {
+	yamlText := `
+devsync:
+  os_target: linux
+  agent_watchs: [shared]
+  manual_transfer: [services/api/vendor, frontend/dist]
+  auth:
+    remotePath: /srv/root
+  mappings:
+    - name: api
+      local_path: services/api
+      remote_path: /srv/api/
+      ignores: [storage/logs]
+    - local_path: frontend
+      remote_path: /var/www/web
+      agent_watchs: [src]
+      trigger_permission:
+        add: true
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(yamlText), &cfg); err != nil {
+		t.Fatalf("yaml unmarshal failed: %v", err)
+	}
+	root := filepath.Join(string(filepath.Separator), "home", "me", "project")
+	cfg.LocalPath = root
+	if !cfg.HasMappings() || len(cfg.Devsync.Mappings) != 2 {
+		t.Fatalf("expected 2 mappings, got %d", len(cfg.Devsync.Mappings))
+	}
+
+	ms := cfg.PathMappings()
+	if ms[0].LocalPath != filepath.Join(root, "services", "api") || ms[0].RemotePath != "/srv/api" {
+		t.Fatalf("unexpected first mapping: %+v", ms[0])
+	}
+	if ms[1].Label() != "frontend" {
+		t.Fatalf("expected label from local_path, got %q", ms[1].Label())
+	}
+
+	watch := strings.Join(cfg.AgentWatchPaths(), ",")
+	if watch != "shared,/srv/api,/var/www/web/src" {
+		t.Fatalf("unexpected agent watch paths: %s", watch)
+	}
+
+	api := cfg.ForMapping(ms[0])
+	if api.Devsync.Auth.RemotePath != "/srv/api" || api.LocalPath != ms[0].LocalPath {
+		t.Fatalf("ForMapping did not narrow paths: %+v", api.Devsync.Auth)
+	}
+	if api.HasMappings() || api.Devsync.Mapping == nil || api.Devsync.Mapping.Name != "api" {
+		t.Fatalf("ForMapping should drop mappings and record the active one")
+	}
+	if len(api.Devsync.ManualTransfer) != 1 || api.Devsync.ManualTransfer[0] != "vendor" {
+		t.Fatalf("expected manual_transfer rebased to [vendor], got %v", api.Devsync.ManualTransfer)
+	}
+	web := cfg.ForMapping(ms[1])
+	if !web.Devsync.TriggerPerm.Add || web.Devsync.TriggerPerm.Change {
+		t.Fatalf("expected mapping trigger_permission to replace the global one")
+	}
+}
+
+func TestMappingsWithoutConfigFallBackToAuth(t *testing.T) {
+	var cfg Config
+	cfg.LocalPath = "/tmp/project"
+	cfg.Devsync.Auth.RemotePath = "/srv/project"
+	ms := cfg.PathMappings()
+	if len(ms) != 1 || ms[0].LocalPath != "/tmp/project" || ms[0].RemotePath != "/srv/project" {
+		t.Fatalf("unexpected fallback mapping: %+v", ms)
+	}
+}
+
+func TestValidateMappings(t *testing.T) {
+	errs := validateMappings([]Mapping{
+		{LocalPath: "a", RemotePath: "/srv/a"},
+		{LocalPath: "../outside", RemotePath: "/srv/b"},
+		{LocalPath: "a/", RemotePath: "/srv/c"},
+		{LocalPath: "d", RemotePath: "/srv/a/"},
+		{LocalPath: "", RemotePath: ""},
+	})
+	if len(errs) != 5 {
+		t.Fatalf("expected 5 validation errors, got %d: %v", len(errs), errs)
+	}
+}
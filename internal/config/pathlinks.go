@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PathLinks turns remote paths under auth.remotePath in PTY slot output into
+// clickable OSC 8 links to the synced local file. URL is the link target:
+// {{path}} is the local path (forward slashes, leading slash), {{line}} and
+// {{col}} the position printed after it. Empty uses DefaultPathLinkURL.
+// devsync.sessions[].path_links overrides Enabled per slot.
+type PathLinks struct {
+	Enabled bool   `yaml:"enabled,omitempty"`
+	URL     string `yaml:"url,omitempty"`
+}
+
+// DefaultPathLinkURL links to the local file itself.
+const DefaultPathLinkURL = "file://{{path}}"
+
+// URLTemplate returns the effective link target template.
+func (p PathLinks) URLTemplate() string {
+	if strings.TrimSpace(p.URL) == "" {
+		return DefaultPathLinkURL
+	}
+	return strings.TrimSpace(p.URL)
+}
+
+// PathLinksEnabled reports whether path links start enabled for slot.
+func (c *Config) PathLinksEnabled(slot int) bool {
+	if spec, ok := c.SessionForSlot(slot); ok && spec.PathLinks != nil {
+		return *spec.PathLinks
+	}
+	return c.Devsync.PathLinks.Enabled
+}
+
+// validatePathLinks checks devsync.path_links.
+func validatePathLinks(p PathLinks) []string {
+	if strings.TrimSpace(p.URL) != "" && !strings.Contains(p.URL, "{{path}}") {
+		return []string{fmt.Sprintf("devsync.path_links.url must contain {{path}}, got '%s'", p.URL)}
+	}
+	return nil
+}
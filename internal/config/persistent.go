@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Multiplexers for devsync.persistent_sessions.multiplexer.
+const (
+	MultiplexerAuto   = "auto"
+	MultiplexerTmux   = "tmux"
+	MultiplexerScreen = "screen"
+)
+
+// PersistentSessions runs remote slots (3..9) inside tmux or screen on the
+// remote host, so their processes survive make-sync exits and dropped SSH
+// links and can be reattached on the next devsync start.
+type PersistentSessions struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Multiplexer is auto (tmux, then screen), tmux or screen
+	Multiplexer string `yaml:"multiplexer,omitempty"`
+}
+
+// MultiplexerName returns the configured multiplexer, auto when empty.
+func (p PersistentSessions) MultiplexerName() string {
+	m := strings.ToLower(strings.TrimSpace(p.Multiplexer))
+	if m == "" {
+		return MultiplexerAuto
+	}
+	return m
+}
+
+// validatePersistentSessions checks devsync.persistent_sessions.
+func validatePersistentSessions(d Devsync) []string {
+	var errs []string
+	switch d.PersistentSessions.MultiplexerName() {
+	case MultiplexerAuto, MultiplexerTmux, MultiplexerScreen:
+	default:
+		errs = append(errs, fmt.Sprintf("devsync.persistent_sessions.multiplexer: unknown multiplexer '%s' (use %s, %s or %s)",
+			d.PersistentSessions.Multiplexer, MultiplexerAuto, MultiplexerTmux, MultiplexerScreen))
+	}
+	if d.PersistentSessions.Enabled && strings.Contains(strings.ToLower(d.OSTarget), "win") {
+		errs = append(errs, "devsync.persistent_sessions: not supported for windows targets")
+	}
+	return errs
+}
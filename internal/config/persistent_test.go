@@ -0,0 +1,18 @@
+package config
+
+import "testing"
+
+func TestValidatePersistentSessions(t *testing.T) {
+	d := Devsync{OSTarget: "linux", PersistentSessions: PersistentSessions{Enabled: true}}
+	if errs := validatePersistentSessions(d); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if d.PersistentSessions.MultiplexerName() != MultiplexerAuto {
+		t.Fatalf("expected auto multiplexer by default")
+	}
+
+	d = Devsync{OSTarget: "windows", PersistentSessions: PersistentSessions{Enabled: true, Multiplexer: "zellij"}}
+	if errs := validatePersistentSessions(d); len(errs) != 2 {
+		t.Fatalf("expected 2 validation errors, got %d: %v", len(errs), errs)
+	}
+}
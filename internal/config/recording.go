@@ -0,0 +1,39 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DefaultRecordingDir holds .cast recordings, relative to the project root.
+const DefaultRecordingDir = ".sync_temp/recordings"
+
+// Recording controls asciinema (.cast) recording of PTY slots. Slots opened
+// with a "command >>> name.cast" marker are recorded even when Enabled is
+// false.
+type Recording struct {
+	// Enabled records every slot opened from the menu
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Input also records keystrokes ("i" events); off by default since it
+	// captures passwords typed into the session
+	Input bool `yaml:"input,omitempty"`
+	// Dir is the recordings directory; relative paths are resolved against
+	// the project root
+	Dir string `yaml:"dir,omitempty"`
+}
+
+// RecordingDir returns the directory .cast recordings are written to.
+func (c *Config) RecordingDir() string {
+	root := c.Devsync.Auth.LocalPath
+	if root == "" {
+		root = "."
+	}
+	dir := strings.TrimSpace(c.Devsync.Recording.Dir)
+	if dir == "" {
+		dir = DefaultRecordingDir
+	}
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(root, filepath.FromSlash(dir))
+}
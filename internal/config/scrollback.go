@@ -0,0 +1,36 @@
+package config
+
+// Scrollback sizes the searchable history kept for every PTY slot. The
+// newest Memory KB stay in memory; older output spills to a file under
+// .sync_temp/scrollback until the slot holds Size MB in total. Zero values
+// use the defaults (1024 KB in memory, 16 MB in total).
+type Scrollback struct {
+	Memory int `yaml:"memory,omitempty"`
+	Size   int `yaml:"size,omitempty"`
+}
+
+const (
+	DefaultScrollbackMemoryKB = 1024
+	DefaultScrollbackSizeMB   = 16
+)
+
+// MemoryBytes returns how much scrollback is kept in memory.
+func (s Scrollback) MemoryBytes() int64 {
+	kb := s.Memory
+	if kb <= 0 {
+		kb = DefaultScrollbackMemoryKB
+	}
+	if mem, total := int64(kb)*1024, s.SizeBytes(); mem < total {
+		return mem
+	}
+	return s.SizeBytes()
+}
+
+// SizeBytes returns the total scrollback kept per slot, memory included.
+func (s Scrollback) SizeBytes() int64 {
+	mb := s.Size
+	if mb <= 0 {
+		mb = DefaultScrollbackSizeMB
+	}
+	return int64(mb) * 1024 * 1024
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sides of a devsync.sessions entry.
+const (
+	SessionSideRemote = "remote"
+	SessionSideLocal  = "local"
+)
+
+// SessionSpec declares a named PTY slot. Slots with AutoStart are opened
+// when devsync starts; the others open on Alt+<slot> without the command
+// picker.
+type SessionSpec struct {
+	Slot int    `yaml:"slot"`
+	Name string `yaml:"name,omitempty"`
+	// Side is remote (default) or local
+	Side    string `yaml:"side,omitempty"`
+	Command string `yaml:"command,omitempty"`
+	// Dir is the working directory; relative paths are resolved against
+	// auth.remotePath (remote) or the project root (local)
+	Dir       string            `yaml:"dir,omitempty"`
+	Env       map[string]string `yaml:"env,omitempty"`
+	AutoStart bool              `yaml:"auto_start,omitempty"`
+	// Triggers act on lines of the slot's output
+	Triggers []SessionTrigger `yaml:"triggers,omitempty"`
+	// Restart supervises Command: never (default), on-failure or always
+	Restart string `yaml:"restart,omitempty"`
+	// MaxRestarts gives up after this many restarts in a row (default 5)
+	MaxRestarts int `yaml:"max_restarts,omitempty"`
+	// RestartBackoff (ms) is the delay before the first restart; it doubles
+	// with every further restart up to MaxRestartBackoff (default 1000)
+	RestartBackoff int `yaml:"restart_backoff,omitempty"`
+	// PathLinks overrides devsync.path_links.enabled for this slot
+	PathLinks *bool `yaml:"path_links,omitempty"`
+}
+
+// Restart policies of a devsync.sessions entry.
+const (
+	RestartNever     = "never"
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+const (
+	DefaultMaxRestarts    = 5
+	DefaultRestartBackoff = 1000 // ms
+	MaxRestartBackoff     = time.Minute
+)
+
+// RestartPolicy returns the normalized restart policy of s.
+func (s SessionSpec) RestartPolicy() string {
+	p := strings.ToLower(strings.TrimSpace(s.Restart))
+	if p == "" {
+		return RestartNever
+	}
+	return p
+}
+
+// MaxRestartCount returns the effective number of restarts in a row.
+func (s SessionSpec) MaxRestartCount() int {
+	if s.MaxRestarts <= 0 {
+		return DefaultMaxRestarts
+	}
+	return s.MaxRestarts
+}
+
+// RestartDelay returns the delay before restart number n (0-based).
+func (s SessionSpec) RestartDelay(n int) time.Duration {
+	backoff := s.RestartBackoff
+	if backoff <= 0 {
+		backoff = DefaultRestartBackoff
+	}
+	d := time.Duration(backoff) * time.Millisecond
+	for i := 0; i < n && d < MaxRestartBackoff; i++ {
+		d *= 2
+	}
+	return min(d, MaxRestartBackoff)
+}
+
+// Actions of a devsync.sessions trigger.
+const (
+	TriggerNotify  = "notify"  // terminal bell and OSC 9 notification
+	TriggerFlash   = "flash"   // flash a message on the bottom line
+	TriggerLocal   = "local"   // run Command locally
+	TriggerRemote  = "remote"  // run Command on the remote
+	TriggerRestart = "restart" // interrupt the session command and run it again
+)
+
+// DefaultTriggerCooldown is the default minimum time (ms) between two runs
+// of the same trigger.
+const DefaultTriggerCooldown = 5000
+
+// SessionTrigger runs Action when a line of the slot's output, without
+// escape sequences, matches the regular expression Pattern. Command is a
+// template: {{match}} is the matched line (quoted for the shell) and
+// {{slot}} the slot number.
+type SessionTrigger struct {
+	Pattern string `yaml:"pattern"`
+	Action  string `yaml:"action"`
+	Command string `yaml:"command,omitempty"`
+	// Message replaces the matched line in notify and flash
+	Message string `yaml:"message,omitempty"`
+	// Cooldown (ms) ignores further matches after a run; 0 uses
+	// DefaultTriggerCooldown, negative disables it
+	Cooldown int `yaml:"cooldown,omitempty"`
+}
+
+// CooldownDuration returns the effective cooldown of t.
+func (t SessionTrigger) CooldownDuration() time.Duration {
+	if t.Cooldown < 0 {
+		return 0
+	}
+	if t.Cooldown == 0 {
+		return DefaultTriggerCooldown * time.Millisecond
+	}
+	return time.Duration(t.Cooldown) * time.Millisecond
+}
+
+// IsLocal reports whether the session runs on the local machine.
+func (s SessionSpec) IsLocal() bool {
+	return strings.EqualFold(strings.TrimSpace(s.Side), SessionSideLocal)
+}
+
+// Label returns the name of the session, or its command.
+func (s SessionSpec) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if s.Command != "" {
+		return s.Command
+	}
+	return "slot " + strconv.Itoa(s.Slot)
+}
+
+// SessionForSlot returns the devsync.sessions entry of slot.
+func (c *Config) SessionForSlot(slot int) (SessionSpec, bool) {
+	for _, s := range c.Devsync.Sessions {
+		if s.Slot == slot {
+			return s, true
+		}
+	}
+	return SessionSpec{}, false
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateSessions checks devsync.sessions.
+func validateSessions(sessions []SessionSpec) []string {
+	var errs []string
+	seen := make(map[int]int)
+	for i, s := range sessions {
+		idx := fmt.Sprintf("devsync.sessions[%d]", i)
+		if s.Slot < 3 || s.Slot > 9 {
+			errs = append(errs, fmt.Sprintf("%s: slot must be between 3 and 9, got %d", idx, s.Slot))
+		} else if prev, dup := seen[s.Slot]; dup {
+			errs = append(errs, fmt.Sprintf("%s: slot %d already used by devsync.sessions[%d]", idx, s.Slot, prev))
+		} else {
+			seen[s.Slot] = i
+		}
+		switch strings.ToLower(strings.TrimSpace(s.Side)) {
+		case "", SessionSideRemote, SessionSideLocal:
+		default:
+			errs = append(errs, fmt.Sprintf("%s: side must be '%s' or '%s', got '%s'", idx, SessionSideRemote, SessionSideLocal, s.Side))
+		}
+		for k := range s.Env {
+			if !envNamePattern.MatchString(k) {
+				errs = append(errs, fmt.Sprintf("%s: invalid env name '%s'", idx, k))
+			}
+		}
+		switch s.RestartPolicy() {
+		case RestartNever:
+		case RestartOnFailure, RestartAlways:
+			if strings.TrimSpace(s.Command) == "" {
+				errs = append(errs, fmt.Sprintf("%s: restart '%s' needs a command", idx, s.Restart))
+			}
+		default:
+			errs = append(errs, fmt.Sprintf("%s: restart must be '%s', '%s' or '%s', got '%s'", idx, RestartNever, RestartOnFailure, RestartAlways, s.Restart))
+		}
+		if s.MaxRestarts < 0 {
+			errs = append(errs, fmt.Sprintf("%s: max_restarts must not be negative", idx))
+		}
+		if s.RestartBackoff < 0 {
+			errs = append(errs, fmt.Sprintf("%s: restart_backoff must not be negative", idx))
+		}
+		for j, t := range s.Triggers {
+			errs = append(errs, validateSessionTrigger(fmt.Sprintf("%s.triggers[%d]", idx, j), s, t)...)
+		}
+	}
+	return errs
+}
+
+// validateSessionTrigger checks one trigger of session s.
+func validateSessionTrigger(idx string, s SessionSpec, t SessionTrigger) []string {
+	var errs []string
+	if t.Pattern == "" {
+		errs = append(errs, fmt.Sprintf("%s: pattern is required", idx))
+	} else if _, err := regexp.Compile(t.Pattern); err != nil {
+		errs = append(errs, fmt.Sprintf("%s: invalid pattern: %v", idx, err))
+	}
+	switch strings.ToLower(strings.TrimSpace(t.Action)) {
+	case TriggerNotify, TriggerFlash:
+	case TriggerLocal, TriggerRemote:
+		if strings.TrimSpace(t.Command) == "" {
+			errs = append(errs, fmt.Sprintf("%s: command is required for action '%s'", idx, t.Action))
+		}
+	case TriggerRestart:
+		if strings.TrimSpace(s.Command) == "" {
+			errs = append(errs, fmt.Sprintf("%s: action 'restart' needs a session command", idx))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("%s: action must be one of %s, %s, %s, %s or %s, got '%s'",
+			idx, TriggerNotify, TriggerFlash, TriggerLocal, TriggerRemote, TriggerRestart, t.Action))
+	}
+	return errs
+}
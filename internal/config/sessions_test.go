@@ -0,0 +1,93 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSessionsParse(t *testing.T) {
+	yamlText := `
+devsync:
+  os_target: linux
+  path_links:
+    enabled: true
+  sessions:
+    - slot: 3
+      name: logs
+      command: docker compose logs -f
+      auto_start: true
+      path_links: false
+      triggers:
+        - pattern: "address already in use"
+          action: restart
+        - pattern: "FATAL|Exception"
+          action: notify
+          cooldown: -1
+    - slot: 4
+      name: web
+      side: local
+      command: npm run dev
+      restart: on-failure
+      restart_backoff: 500
+      dir: web
+      env:
+        NODE_ENV: development
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(yamlText), &cfg); err != nil {
+		t.Fatalf("yaml unmarshal failed: %v", err)
+	}
+	logs, ok := cfg.SessionForSlot(3)
+	if !ok || !logs.AutoStart || logs.IsLocal() || logs.Label() != "logs" {
+		t.Fatalf("unexpected slot 3 session: %+v", logs)
+	}
+	web, ok := cfg.SessionForSlot(4)
+	if !ok || !web.IsLocal() || web.Env["NODE_ENV"] != "development" {
+		t.Fatalf("unexpected slot 4 session: %+v", web)
+	}
+	if len(logs.Triggers) != 2 || logs.Triggers[0].Action != TriggerRestart || logs.Triggers[0].CooldownDuration() != DefaultTriggerCooldown*time.Millisecond {
+		t.Fatalf("unexpected slot 3 triggers: %+v", logs.Triggers)
+	}
+	if logs.Triggers[1].CooldownDuration() != 0 {
+		t.Fatalf("negative cooldown should disable it: %+v", logs.Triggers[1])
+	}
+	if web.RestartPolicy() != RestartOnFailure || web.MaxRestartCount() != DefaultMaxRestarts {
+		t.Fatalf("unexpected slot 4 restart policy: %+v", web)
+	}
+	if web.RestartDelay(0) != 500*time.Millisecond || web.RestartDelay(2) != 2*time.Second || web.RestartDelay(20) != MaxRestartBackoff {
+		t.Fatalf("unexpected backoff: %v %v %v", web.RestartDelay(0), web.RestartDelay(2), web.RestartDelay(20))
+	}
+	if logs.RestartPolicy() != RestartNever {
+		t.Fatalf("restart should default to never: %q", logs.RestartPolicy())
+	}
+	if cfg.PathLinksEnabled(3) || !cfg.PathLinksEnabled(4) || !cfg.PathLinksEnabled(5) {
+		t.Fatalf("path_links override not applied")
+	}
+	if _, ok := cfg.SessionForSlot(5); ok {
+		t.Fatalf("slot 5 is not configured")
+	}
+	if errs := validateSessions(cfg.Devsync.Sessions); len(errs) != 0 {
+		t.Fatalf("unexpected validation errors: %v", errs)
+	}
+}
+
+func TestValidateSessions(t *testing.T) {
+	sessions := []SessionSpec{
+		{Slot: 2},
+		{Slot: 3, Side: "both"},
+		{Slot: 3, Env: map[string]string{"BAD-NAME": "x"}},
+		{Slot: 5, Triggers: []SessionTrigger{
+			{Pattern: "(", Action: TriggerNotify},
+			{Pattern: "x", Action: TriggerLocal},
+			{Pattern: "x", Action: TriggerRestart},
+			{Pattern: "x", Action: "beep"},
+		}},
+		{Slot: 6, Restart: "sometimes"},
+		{Slot: 7, Restart: RestartAlways, MaxRestarts: -1},
+	}
+	if errs := validateSessions(sessions); len(errs) != 11 {
+		t.Fatalf("expected 11 validation errors, got %d: %v", len(errs), errs)
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Target is an extra remote devsync uploads every local change to, next to
+// devsync.auth. The connection comes from Auth or from the
+// direct_access.ssh_configs entry named by SSHConfig; fields left empty fall
+// back to devsync.auth.
+type Target struct {
+	Name string `yaml:"name,omitempty"`
+	// SSHConfig is the Host of a direct_access.ssh_configs entry
+	SSHConfig string `yaml:"ssh_config,omitempty"`
+	Auth      Auth   `yaml:"auth,omitempty"`
+}
+
+// Label is how a target is shown in output.
+func (t Target) Label() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	if t.Auth.RemotePath != "" {
+		return t.Auth.Host + ":" + t.Auth.RemotePath
+	}
+	return t.Auth.Host
+}
+
+// HasTargets reports whether devsync.targets is configured.
+func (c *Config) HasTargets() bool {
+	return len(c.Devsync.Targets) > 0
+}
+
+// SyncTargets returns devsync.auth as the first target followed by every
+// entry of devsync.targets with its connection resolved.
+func (c *Config) SyncTargets() []Target {
+	out := []Target{{Auth: c.Devsync.Auth}}
+	for _, t := range c.Devsync.Targets {
+		out = append(out, c.resolveTarget(t))
+	}
+	return out
+}
+
+// ForTarget returns a copy of c that syncs with t instead of devsync.auth.
+// t must come from SyncTargets.
+func (c *Config) ForTarget(t Target) *Config {
+	cp := *c
+	cp.Devsync.Auth = t.Auth
+	cp.Devsync.Targets = nil
+	return &cp
+}
+
+func (c *Config) resolveTarget(t Target) Target {
+	a := t.Auth
+	if sc := c.sshConfigByHost(t.SSHConfig); sc != nil {
+		fill := func(dst *string, key string) {
+			if v, ok := sc[key].(string); ok && *dst == "" {
+				*dst = strings.TrimSpace(v)
+			}
+		}
+		fill(&a.Host, "HostName")
+		fill(&a.Username, "User")
+		fill(&a.Port, "Port")
+		fill(&a.PrivateKey, "IdentityFile")
+	}
+	base := c.Devsync.Auth
+	if a.Username == "" {
+		a.Username = base.Username
+	}
+	if a.PrivateKey == "" && a.Password == "" {
+		a.PrivateKey = base.PrivateKey
+		a.Password = base.Password
+	}
+	if a.Port == "" {
+		a.Port = base.Port
+	}
+	if a.LocalPath == "" {
+		a.LocalPath = base.LocalPath
+	}
+	if a.RemotePath == "" {
+		a.RemotePath = base.RemotePath
+	}
+	t.Auth = a
+	return t
+}
+
+func (c *Config) sshConfigByHost(host string) map[string]interface{} {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return nil
+	}
+	for _, sc := range c.DirectAccess.SSHConfigs {
+		if h, ok := sc["Host"].(string); ok && strings.TrimSpace(h) == host {
+			return sc
+		}
+	}
+	return nil
+}
+
+// validateTargets checks devsync.targets; every target needs a host and no
+// two targets (devsync.auth included) may share a host and remote path.
+func validateTargets(c *Config) []string {
+	var errs []string
+	seen := map[string]string{}
+	for i, t := range c.SyncTargets() {
+		idx := "devsync.auth"
+		if i > 0 {
+			idx = fmt.Sprintf("devsync.targets[%d]", i-1)
+			if ref := c.Devsync.Targets[i-1].SSHConfig; ref != "" && c.sshConfigByHost(ref) == nil {
+				errs = append(errs, fmt.Sprintf("%s: ssh_config '%s' not found in direct_access.ssh_configs", idx, ref))
+				continue
+			}
+			if strings.TrimSpace(t.Auth.Host) == "" {
+				errs = append(errs, fmt.Sprintf("%s: host cannot be empty (set auth.host or ssh_config)", idx))
+				continue
+			}
+		}
+		key := t.Auth.Host + ":" + t.Auth.Port + ":" + cleanRemoteRoot(t.Auth.RemotePath)
+		if prev, dup := seen[key]; dup {
+			errs = append(errs, fmt.Sprintf("%s: same host and remotePath as %s", idx, prev))
+			continue
+		}
+		seen[key] = idx
+	}
+	return errs
+}
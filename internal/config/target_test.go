@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSyncTargetsResolveConnection(t *testing.T) {
+	yamlText := `
+devsync:
+  os_target: linux
+  auth:
+    username: deploy
+    privateKey: /keys/id
+    host: 10.0.0.11
+    port: "22"
+    remotePath: /srv/app
+  targets:
+    - name: box2
+      auth:
+        host: 10.0.0.12
+    - ssh_config: box3
+      auth:
+        remotePath: /var/www/app
+direct_access:
+  ssh_configs:
+    - Host: box3
+      HostName: 10.0.0.13
+      User: ops
+      Port: "2222"
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(yamlText), &cfg); err != nil {
+		t.Fatalf("yaml unmarshal failed: %v", err)
+	}
+	if !cfg.HasTargets() {
+		t.Fatalf("expected targets to be parsed")
+	}
+
+	targets := cfg.SyncTargets()
+	if len(targets) != 3 {
+		t.Fatalf("expected auth plus 2 targets, got %d", len(targets))
+	}
+	if targets[0].Auth.Host != "10.0.0.11" {
+		t.Fatalf("first target must be devsync.auth, got %+v", targets[0].Auth)
+	}
+	box2 := targets[1].Auth
+	if box2.Username != "deploy" || box2.PrivateKey != "/keys/id" || box2.RemotePath != "/srv/app" {
+		t.Fatalf("expected box2 to fall back to devsync.auth, got %+v", box2)
+	}
+	box3 := targets[2].Auth
+	if box3.Host != "10.0.0.13" || box3.Username != "ops" || box3.Port != "2222" || box3.RemotePath != "/var/www/app" {
+		t.Fatalf("expected box3 resolved from ssh_config, got %+v", box3)
+	}
+	if targets[2].Label() != "10.0.0.13:/var/www/app" {
+		t.Fatalf("unexpected label %q", targets[2].Label())
+	}
+
+	narrowed := cfg.ForTarget(targets[1])
+	if narrowed.Devsync.Auth.Host != "10.0.0.12" || narrowed.HasTargets() {
+		t.Fatalf("ForTarget did not narrow the config: %+v", narrowed.Devsync.Auth)
+	}
+	if errs := validateTargets(&cfg); len(errs) != 0 {
+		t.Fatalf("unexpected validation errors: %v", errs)
+	}
+}
+
+func TestValidateTargets(t *testing.T) {
+	var cfg Config
+	cfg.Devsync.Auth = Auth{Host: "a", RemotePath: "/srv/app"}
+	cfg.Devsync.Targets = []Target{
+		{SSHConfig: "missing"},
+		{Auth: Auth{RemotePath: "/other"}},
+		{Auth: Auth{Host: "a"}},
+	}
+	if errs := validateTargets(&cfg); len(errs) != 3 {
+		t.Fatalf("expected 3 validation errors, got %d: %v", len(errs), errs)
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Trigger kinds checked against TriggerPermission.
+const (
+	TriggerAdd          = "add"
+	TriggerChange       = "change"
+	TriggerUnlink       = "unlink"
+	TriggerUnlinkFolder = "unlink_folder"
+)
+
+// TriggerRule overrides trigger permissions for paths matching Pattern.
+// Permissions left unset fall through to the next matching rule and finally
+// to the global trigger_permission flags.
+type TriggerRule struct {
+	// Pattern is relative to the project root; `**` matches any number of
+	// directories and a pattern without `/` matches the base name anywhere.
+	Pattern      string `yaml:"pattern"`
+	Add          *bool  `yaml:"add,omitempty"`
+	Change       *bool  `yaml:"change,omitempty"`
+	Unlink       *bool  `yaml:"unlink,omitempty"`
+	UnlinkFolder *bool  `yaml:"unlink_folder,omitempty"`
+}
+
+func (r TriggerRule) permission(kind string) *bool {
+	switch kind {
+	case TriggerAdd:
+		return r.Add
+	case TriggerChange:
+		return r.Change
+	case TriggerUnlink:
+		return r.Unlink
+	case TriggerUnlinkFolder:
+		return r.UnlinkFolder
+	}
+	return nil
+}
+
+// Allows reports whether an event of kind on rel (slash-separated, relative
+// to the project root) may trigger a sync, and which rule decided. Rules are
+// evaluated in order; the first matching rule that sets the permission wins.
+// A removed folder without an unlink_folder rule follows unlink, which is
+// also what the global block uses for folders.
+func (t TriggerPermission) Allows(rel, kind string) (bool, string) {
+	rel = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(rel, "\\", "/")), "/")
+	for i, r := range t.Rules {
+		if v := r.permission(kind); v != nil && MatchTriggerPattern(r.Pattern, rel) {
+			return *v, fmt.Sprintf("trigger_permission.rules[%d] %q", i, r.Pattern)
+		}
+	}
+
+	switch kind {
+	case TriggerAdd:
+		return t.Add, "trigger_permission.add"
+	case TriggerChange:
+		return t.Change, "trigger_permission.change"
+	case TriggerUnlink:
+		return t.Unlink, "trigger_permission.unlink"
+	case TriggerUnlinkFolder:
+		return t.Allows(rel, TriggerUnlink)
+	}
+	return false, fmt.Sprintf("unknown trigger %q", kind)
+}
+
+// MatchTriggerPattern matches rel against a trigger rule pattern. Like
+// .sync_ignore, a pattern matching a directory also matches everything
+// below it.
+func MatchTriggerPattern(pattern, rel string) bool {
+	pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+	if pattern == "" {
+		return false
+	}
+	if !strings.Contains(pattern, "/") && pattern != "**" {
+		pattern = "**/" + pattern
+	}
+	pat := strings.Split(pattern, "/")
+	segs := strings.Split(rel, "/")
+	for n := len(segs); n > 0; n-- {
+		if matchGlobSegments(pat, segs[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlobSegments matches path segments, letting `**` consume zero or more
+// of them. A trailing `**` also matches the directory itself.
+func matchGlobSegments(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			if len(pat) == 1 {
+				return true
+			}
+			for i := 0; i <= len(segs); i++ {
+				if matchGlobSegments(pat[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], segs[0]); !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestTriggerPermissionRules(t *testing.T) {
+	yamlText := `
+devsync:
+  os_target: linux
+  trigger_permission:
+    unlink_folder: false
+    unlink: false
+    change: true
+    add: true
+    rules:
+      - pattern: "src/**"
+        unlink: true
+      - pattern: "storage/**"
+        unlink: false
+        unlink_folder: false
+      - pattern: public/build/**
+        add: true
+        change: true
+        unlink: false
+      - pattern: "*.log"
+        add: false
+        change: false
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(yamlText), &cfg); err != nil {
+		t.Fatalf("yaml unmarshal failed: %v", err)
+	}
+	tp := cfg.Devsync.TriggerPerm
+	if len(tp.Rules) != 4 {
+		t.Fatalf("expected 4 rules, got %d", len(tp.Rules))
+	}
+
+	cases := []struct {
+		rel, kind string
+		allowed   bool
+		decidedBy string
+	}{
+		{"src/app/main.go", TriggerUnlink, true, `trigger_permission.rules[0] "src/**"`},
+		{"src", TriggerUnlinkFolder, true, `trigger_permission.rules[0] "src/**"`},
+		{"src/app/main.go", TriggerChange, true, "trigger_permission.change"},
+		{"storage/logs", TriggerUnlinkFolder, false, `trigger_permission.rules[1] "storage/**"`},
+		{"public/build/app.js", TriggerUnlink, false, `trigger_permission.rules[2] "public/build/**"`},
+		{"public/build/app.js", TriggerAdd, true, `trigger_permission.rules[2] "public/build/**"`},
+		{"src/debug.log", TriggerChange, false, `trigger_permission.rules[3] "*.log"`},
+		{"README.md", TriggerUnlink, false, "trigger_permission.unlink"},
+		{"README.md", TriggerUnlinkFolder, false, "trigger_permission.unlink"},
+	}
+	for _, c := range cases {
+		allowed, by := tp.Allows(c.rel, c.kind)
+		if allowed != c.allowed || by != c.decidedBy {
+			t.Errorf("Allows(%q, %q) = %v, %q; want %v, %q", c.rel, c.kind, allowed, by, c.allowed, c.decidedBy)
+		}
+	}
+}
+
+func TestMatchTriggerPattern(t *testing.T) {
+	cases := []struct {
+		pattern, rel string
+		want         bool
+	}{
+		{"src/**", "src", true},
+		{"src/**", "src/a/b.go", true},
+		{"src/**", "lib/src/a.go", false},
+		{"**/node_modules", "web/node_modules/x/y.js", true},
+		{"uploads", "uploads/2024/a.png", true},
+		{"uploads", "public/uploads/a.png", true},
+		{"docs/*.md", "docs/a.md", true},
+		{"docs/*.md", "docs/sub/a.md", false},
+		{"", "anything", false},
+	}
+	for _, c := range cases {
+		if got := MatchTriggerPattern(c.pattern, c.rel); got != c.want {
+			t.Errorf("MatchTriggerPattern(%q, %q) = %v, want %v", c.pattern, c.rel, got, c.want)
+		}
+	}
+}
@@ -32,6 +32,8 @@ type RemoteAgentConfig struct {
 		AgentWatchs    []string `json:"agent_watchs"`
 		ManualTransfer []string `json:"manual_transfer"`
 		WorkingDir     string   `json:"working_dir"`
+		// HeartbeatInterval in seconds; 0 disables agent heartbeats
+		HeartbeatInterval int `json:"heartbeat_interval"`
 	} `json:"devsync"`
 }
 
@@ -214,9 +216,10 @@ func uploadConfigJSON(client *sshclient.SSHClient, cfg *config.Config, remoteSyn
 	// Generate remote config
 	remoteConfig := &RemoteAgentConfig{}
 	remoteConfig.Devsync.Ignores = cfg.Devsync.Ignores
-	remoteConfig.Devsync.AgentWatchs = cfg.Devsync.AgentWatchs
+	remoteConfig.Devsync.AgentWatchs = cfg.AgentWatchPaths()
 	remoteConfig.Devsync.ManualTransfer = cfg.Devsync.ManualTransfer
 	remoteConfig.Devsync.WorkingDir = cfg.Devsync.Auth.RemotePath
+	remoteConfig.Devsync.HeartbeatInterval = cfg.Devsync.Heartbeat.IntervalSeconds()
 
 	// Convert to JSON
 	configJSON, err := json.MarshalIndent(remoteConfig, "", "  ")
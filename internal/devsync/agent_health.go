@@ -0,0 +1,115 @@
+package devsync
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"make-sync/internal/config"
+	"make-sync/internal/deployagent"
+)
+
+// heartbeatSettings returns the configured heartbeat interval and the number
+// of missed beats tolerated. A zero interval means heartbeats are disabled.
+func (w *Watcher) heartbeatSettings() (time.Duration, int) {
+	w.configMu.RLock()
+	hb := w.config.Devsync.Heartbeat
+	w.configMu.RUnlock()
+	return time.Duration(hb.IntervalSeconds()) * time.Second, hb.MaxMissedBeats()
+}
+
+// resetAgentHealth marks the agent healthy; called whenever a new watch
+// command starts so a fresh agent gets a full grace period.
+func (w *Watcher) resetAgentHealth() {
+	w.agentHealthMu.Lock()
+	w.agentLastHeartbeat = time.Now()
+	w.agentQueueDepth = 0
+	w.agentLastEvent = ""
+	w.agentMissedBeats = 0
+	w.agentUnhealthy = false
+	w.agentHealthMu.Unlock()
+}
+
+// recordAgentHeartbeat parses a heartbeat line (HEARTBEAT|queue_depth|last_event)
+// and refreshes the agent health state.
+func (w *Watcher) recordAgentHeartbeat(content string) {
+	parts := strings.Split(content, "|")
+	if len(parts) < 3 {
+		return
+	}
+	depth, _ := strconv.Atoi(strings.TrimSpace(parts[1]))
+
+	w.agentHealthMu.Lock()
+	recovered := w.agentMissedBeats > 0
+	w.agentLastHeartbeat = time.Now()
+	w.agentQueueDepth = depth
+	w.agentLastEvent = strings.TrimSpace(parts[2])
+	w.agentMissedBeats = 0
+	w.agentUnhealthy = false
+	w.agentHealthMu.Unlock()
+
+	if recovered {
+		w.safeStatusln("💓 Agent heartbeat restored (queue=%d)", depth)
+	}
+}
+
+// checkAgentHeartbeat updates the missed-beat counter and reports whether the
+// agent exceeded the tolerated number of missed beats.
+func (w *Watcher) checkAgentHeartbeat(interval time.Duration, maxMissed int) bool {
+	w.agentHealthMu.Lock()
+	missed := int(time.Since(w.agentLastHeartbeat) / interval)
+	changed := missed != w.agentMissedBeats
+	w.agentMissedBeats = missed
+	if missed >= maxMissed {
+		w.agentUnhealthy = true
+	}
+	unhealthy := w.agentUnhealthy
+	w.agentHealthMu.Unlock()
+
+	if changed && missed > 0 && !unhealthy {
+		w.safeStatusln("⏳ Agent heartbeat missed (%d/%d)", missed, maxMissed)
+	}
+	return unhealthy
+}
+
+// killUnhealthyAgent kills the hung remote agent so the monitoring loop can
+// start a fresh one.
+func (w *Watcher) killUnhealthyAgent() {
+	w.emitControlEvent("agent_unhealthy", "", w.agentPID)
+	localConfig, err := config.GetOrCreateLocalConfig()
+	if err != nil {
+		w.safePrintf("⚠️  Failed to load local config: %v\n", err)
+		return
+	}
+	if err := deployagent.KillExistingAgent(w.sshClient, localConfig.Devsync.AgentName, w.config.Devsync.OSTarget); err != nil {
+		w.safePrintf("⚠️  Failed to kill unhealthy agent: %v\n", err)
+	}
+	_ = w.sshClient.StopAgentSession()
+}
+
+// agentHealthSummary returns a one-line description of the agent health state.
+func (w *Watcher) agentHealthSummary() string {
+	interval, maxMissed := w.heartbeatSettings()
+	if interval <= 0 {
+		return "heartbeat disabled"
+	}
+
+	w.agentHealthMu.Lock()
+	defer w.agentHealthMu.Unlock()
+	if w.agentLastHeartbeat.IsZero() {
+		return "waiting for agent"
+	}
+	state := "healthy"
+	if w.agentUnhealthy {
+		state = "unhealthy"
+	} else if w.agentMissedBeats > 0 {
+		state = fmt.Sprintf("missed %d/%d beats", w.agentMissedBeats, maxMissed)
+	}
+	lastEvent := w.agentLastEvent
+	if lastEvent == "" {
+		lastEvent = "-"
+	}
+	return fmt.Sprintf("%s (queue=%d, last event=%s, last beat %s ago)",
+		state, w.agentQueueDepth, lastEvent, time.Since(w.agentLastHeartbeat).Round(time.Second))
+}
@@ -0,0 +1,69 @@
+package devsync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The remote agent runs detached and journals its events; the client streams
+// them with `agent tail --cursor <seq>` and persists the last processed
+// sequence so a reconnect replays everything that happened while offline.
+
+// agentCursorFileName returns the path of the local cursor file.
+func (w *Watcher) agentCursorFileName() string {
+	return filepath.Join(w.config.LocalPath, ".sync_temp", "agent_cursor")
+}
+
+// loadAgentCursor reads the last acknowledged journal sequence, or -1 when
+// none was recorded yet (the agent then follows from the journal end).
+func (w *Watcher) loadAgentCursor() int64 {
+	data, err := os.ReadFile(w.agentCursorFileName())
+	if err != nil {
+		return -1
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// ackAgentCursor records seq as processed and persists it (best-effort).
+func (w *Watcher) ackAgentCursor(seq int64) {
+	if seq == w.agentCursor {
+		return
+	}
+	w.agentCursor = seq
+	path := w.agentCursorFileName()
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(strconv.FormatInt(seq, 10)), 0644); err != nil {
+		w.safePrintf("⚠️  Failed to save agent cursor: %v\n", err)
+	}
+}
+
+// splitJournalLine strips the "<seq> " prefix that `agent tail` puts in front
+// of journaled lines. ok is false for lines that are not journal entries.
+func splitJournalLine(line string) (int64, string, bool) {
+	sp := strings.IndexByte(line, ' ')
+	if sp <= 0 {
+		return 0, line, false
+	}
+	seq, err := strconv.ParseInt(line[:sp], 10, 64)
+	if err != nil {
+		return 0, line, false
+	}
+	return seq, strings.TrimSpace(line[sp+1:]), true
+}
+
+// agentStreamCommand builds the remote command that ensures the detached
+// watcher is running and then tails its journal from the current cursor.
+func (w *Watcher) agentStreamCommand(remoteBase, remoteAgentPath string) string {
+	cursor := w.agentCursor
+	if strings.Contains(strings.ToLower(w.config.Devsync.OSTarget), "win") {
+		return fmt.Sprintf(`cmd.exe /C "cd /d "%s" && "%s" watch --detach && "%s" tail --cursor %d"`, remoteBase, remoteAgentPath, remoteAgentPath, cursor)
+	}
+	return fmt.Sprintf(`%s watch --detach && %s tail --cursor %d`, remoteAgentPath, remoteAgentPath, cursor)
+}
@@ -14,6 +14,10 @@ type Bridge interface {
 	// may accept an initial command via constructor/factory; the manager
 	// passes stdin callbacks via the SetStdinCallback family.
 	StartInteractiveShell() error
+	// StartInBackground starts the session like StartInteractiveShell, but
+	// paused: output only reaches the cache and the output tap, and the
+	// terminal mode and stdin are left alone until the first Resume.
+	StartInBackground() error
 
 	// Pause stops output to terminal and caches subsequent output for Resume.
 	Pause() error
@@ -51,6 +55,10 @@ type Bridge interface {
 	// needed.
 	SetOnInputListener(func([]byte))
 	SetOnInputHitCodeListener(func(string))
+
+	// SetWindowSize pins the PTY to cols x rows (split panes); cols or rows
+	// <= 0 makes it follow the terminal size again.
+	SetWindowSize(cols, rows int) error
 }
 
 // compile-time assertions that existing bridge implementations satisfy Bridge
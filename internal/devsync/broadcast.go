@@ -0,0 +1,195 @@
+package devsync
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+
+	"make-sync/internal/util"
+)
+
+// StartBroadcast mirrors keyboard input typed in any of slots to the others.
+// At least two open slots are required.
+func (m *PTYManager) StartBroadcast(slots []int) error {
+	set := make(map[int]bool, len(slots))
+	for _, slot := range slots {
+		if !m.HasSlot(slot) {
+			return fmt.Errorf("no session in slot %d", slot)
+		}
+		set[slot] = true
+	}
+	if len(set) < 2 {
+		return fmt.Errorf("select at least two slots to broadcast to")
+	}
+	m.broadcastMu.Lock()
+	m.broadcast = set
+	m.broadcastMu.Unlock()
+	log.Println("PTYManager: broadcasting input to slots", slots)
+	return nil
+}
+
+// StopBroadcast turns broadcast mode off.
+func (m *PTYManager) StopBroadcast() {
+	m.broadcastMu.Lock()
+	m.broadcast = nil
+	m.broadcastMu.Unlock()
+}
+
+// BroadcastSlots returns the slots input is broadcast to, sorted; empty when
+// broadcast mode is off.
+func (m *PTYManager) BroadcastSlots() []int {
+	m.broadcastMu.Lock()
+	defer m.broadcastMu.Unlock()
+	out := make([]int, 0, len(m.broadcast))
+	for slot := range m.broadcast {
+		out = append(out, slot)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// IsBroadcasting reports whether input typed in slot is broadcast.
+func (m *PTYManager) IsBroadcasting(slot int) bool {
+	m.broadcastMu.Lock()
+	defer m.broadcastMu.Unlock()
+	return m.broadcast[slot]
+}
+
+// dropFromBroadcast removes a closed slot; broadcast ends when fewer than two
+// slots are left.
+func (m *PTYManager) dropFromBroadcast(slot int) {
+	m.broadcastMu.Lock()
+	defer m.broadcastMu.Unlock()
+	if !m.broadcast[slot] {
+		return
+	}
+	delete(m.broadcast, slot)
+	if len(m.broadcast) < 2 {
+		m.broadcast = nil
+	}
+}
+
+// broadcastInput writes stdin bytes typed in slot to the stdin writers of the
+// other broadcast slots. The focused bridge writes to its own PTY itself.
+// Chunks carrying an Alt+digit shortcut are swallowed by the bridge and are
+// not broadcast either.
+func (m *PTYManager) broadcastInput(slot int, b []byte) {
+	if len(b) == 0 || !m.IsBroadcasting(slot) || hasHitCode(b) {
+		return
+	}
+	for _, other := range m.BroadcastSlots() {
+		if other == slot {
+			continue
+		}
+		m.mu.RLock()
+		s, ok := m.sessions[other]
+		m.mu.RUnlock()
+		// slots opened in the background have no shell until first focus
+		if !ok || s == nil || s.Bridge == nil || !s.started {
+			continue
+		}
+		w := s.Bridge.GetStdinWriter()
+		if w == nil {
+			continue
+		}
+		if _, err := w.Write(b); err != nil {
+			log.Printf("PTYManager: broadcast to slot %d failed: %v", other, err)
+			continue
+		}
+		s.recordInputBytes(b)
+	}
+}
+
+// hasHitCode reports whether b contains ESC+digit (Alt+0..9).
+func hasHitCode(b []byte) bool {
+	for i := 0; i < len(b)-1; i++ {
+		if b[i] == 0x1b && b[i+1] >= '0' && b[i+1] <= '9' {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcastIndicator is shown while input typed in the focused slot is
+// broadcast.
+func (m *PTYManager) broadcastIndicator(slot int) string {
+	return fmt.Sprintf("📡 Broadcasting input to slots %s — Alt+%d stops", joinSlots(m.BroadcastSlots()), slot)
+}
+
+func joinSlots(slots []int) string {
+	parts := make([]string, len(slots))
+	for i, slot := range slots {
+		parts[i] = strconv.Itoa(slot)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// showBroadcastMenu lets the user pick the slots to broadcast to, slot
+// preselected. Callers hold promptMu.
+func (w *Watcher) showBroadcastMenu(slot int) {
+	slots := w.ptyMgr.ListSlots()
+	sort.Ints(slots)
+	selected := map[int]bool{slot: true}
+	for _, s := range w.ptyMgr.BroadcastSlots() {
+		selected[s] = true
+	}
+	w.pickSlots("📡 Broadcast input to which slots? (Enter toggles)", "Start broadcast", slots, selected, func(picked []int) error {
+		if err := w.ptyMgr.StartBroadcast(picked); err != nil {
+			return err
+		}
+		util.Default.MenuPrintf("📡 Broadcasting input to slots %s\n", joinSlots(picked))
+		return nil
+	})
+}
+
+// pickSlots shows slots as a checklist; Enter toggles a slot, confirm calls
+// apply with the checked slots. An error from apply is shown and the list
+// stays open. It returns false when the user cancelled. Callers hold
+// promptMu.
+func (w *Watcher) pickSlots(label, confirm string, slots []int, selected map[int]bool, apply func([]int) error) bool {
+	cursor := 0
+	for {
+		items := make([]string, 0, len(slots)+2)
+		for _, s := range slots {
+			mark := "[ ]"
+			if selected[s] {
+				mark = "[x]"
+			}
+			items = append(items, fmt.Sprintf("%s %s", mark, w.slotLabel(s)))
+		}
+		items = append(items, confirm, "Cancel")
+
+		sel := promptui.Select{
+			Label:     label,
+			Items:     items,
+			Size:      len(items),
+			CursorPos: cursor,
+			HideHelp:  true,
+		}
+		i, _, err := sel.Run()
+		if err != nil || i == len(items)-1 {
+			return false
+		}
+		if i < len(slots) {
+			selected[slots[i]] = !selected[slots[i]]
+			cursor = i
+			continue
+		}
+		var picked []int
+		for _, s := range slots {
+			if selected[s] {
+				picked = append(picked, s)
+			}
+		}
+		if err := apply(picked); err != nil {
+			util.Default.MenuPrintf("❌ %v\n", err)
+			cursor = len(slots)
+			continue
+		}
+		return true
+	}
+}
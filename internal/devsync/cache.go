@@ -210,6 +210,112 @@ func (fc *FileCache) DeleteFileMetadata(filePath string) error {
 	return nil
 }
 
+// ListFilesUnder returns the absolute paths of all cached files at or below
+// dirPath (the whole cache when dirPath is the watch path).
+func (fc *FileCache) ListFilesUnder(dirPath string) ([]string, error) {
+	relDir, err := filepath.Rel(fc.watchPath, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	if err := fc.db.Model(&FileMetadata{}).Pluck("path", &paths).Error; err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, p := range paths {
+		if relDir == "." || p == relDir || strings.HasPrefix(p, relDir+string(filepath.Separator)) {
+			out = append(out, filepath.Join(fc.watchPath, p))
+		}
+	}
+	return out, nil
+}
+
+// GetHash returns the cached hash for filePath, if any.
+func (fc *FileCache) GetHash(filePath string) (string, bool) {
+	relPath, err := filepath.Rel(fc.watchPath, filePath)
+	if err != nil {
+		return "", false
+	}
+	var records []FileMetadata
+	silentDB := fc.db.Session(&gorm.Session{Logger: fc.db.Logger.LogMode(0)})
+	if err := silentDB.Where("path = ?", relPath).Limit(1).Find(&records).Error; err != nil || len(records) == 0 {
+		return "", false
+	}
+	return records[0].Hash, true
+}
+
+// HashesByPath returns every cached hash keyed by the slash-separated path
+// relative to the watch path.
+func (fc *FileCache) HashesByPath() (map[string]string, error) {
+	var records []FileMetadata
+	silentDB := fc.db.Session(&gorm.Session{Logger: fc.db.Logger.LogMode(0)})
+	if err := silentDB.Select("path", "hash").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(records))
+	for _, rec := range records {
+		out[filepath.ToSlash(rec.Path)] = rec.Hash
+	}
+	return out, nil
+}
+
+// RenamePath moves the metadata of oldPath (a file or a whole directory
+// subtree) to newPath after a rename/move, replacing entries already stored
+// under newPath.
+func (fc *FileCache) RenamePath(oldPath, newPath string) error {
+	oldRel, err := filepath.Rel(fc.watchPath, oldPath)
+	if err != nil {
+		return err
+	}
+	newRel, err := filepath.Rel(fc.watchPath, newPath)
+	if err != nil {
+		return err
+	}
+	sep := string(filepath.Separator)
+
+	return fc.db.Transaction(func(tx *gorm.DB) error {
+		var records []FileMetadata
+		if err := tx.Find(&records).Error; err != nil {
+			return err
+		}
+		moved := false
+		for _, rec := range records {
+			if rec.Path == oldRel || strings.HasPrefix(rec.Path, oldRel+sep) {
+				moved = true
+				break
+			}
+		}
+		if !moved {
+			// nothing cached under oldPath (or already renamed)
+			return nil
+		}
+		for _, rec := range records {
+			if rec.Path == newRel || strings.HasPrefix(rec.Path, newRel+sep) {
+				if err := tx.Unscoped().Delete(&FileMetadata{}, rec.ID).Error; err != nil {
+					return err
+				}
+			}
+		}
+		for _, rec := range records {
+			var target string
+			switch {
+			case rec.Path == oldRel:
+				target = newRel
+			case strings.HasPrefix(rec.Path, oldRel+sep):
+				target = newRel + strings.TrimPrefix(rec.Path, oldRel)
+			default:
+				continue
+			}
+			if err := tx.Model(&FileMetadata{}).Where("id = ?", rec.ID).Update("path", target).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // GetFileStats returns statistics about cached files
 func (fc *FileCache) GetFileStats() (totalFiles int64, totalSize int64, err error) {
 	var count int64
@@ -0,0 +1,222 @@
+package devsync
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/manifoldco/promptui"
+
+	"make-sync/internal/devsync/localclient"
+	"make-sync/internal/util"
+)
+
+// defaultCopyLines is offered by "Copy last N lines".
+const defaultCopyLines = 50
+
+// maxOSC52Bytes keeps the copied text within what common terminals accept
+// in one OSC 52 sequence (hterm, tmux); older lines are dropped beyond it.
+const maxOSC52Bytes = 74994
+
+// lastLines returns the last n lines, ignoring trailing blank ones.
+func lastLines(lines []string, n int) []string {
+	lines = trimBlankTail(lines)
+	if n < len(lines) {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// lastCommandOutput returns the output of the last command in lines. The
+// last line is taken as the shell prompt; the output is everything after the
+// previous line that starts with that prompt (the command line itself).
+func lastCommandOutput(lines []string) ([]string, error) {
+	lines = trimBlankTail(lines)
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("no command output in the scrollback")
+	}
+	prompt := strings.TrimRight(lines[len(lines)-1], " ")
+	if prompt == "" {
+		return nil, fmt.Errorf("no shell prompt on the last line")
+	}
+	for i := len(lines) - 2; i >= 0; i-- {
+		if strings.HasPrefix(lines[i], prompt) {
+			out := trimBlankTail(lines[i+1 : len(lines)-1])
+			if len(out) == 0 {
+				return nil, fmt.Errorf("the last command printed nothing")
+			}
+			return out, nil
+		}
+	}
+	return nil, fmt.Errorf("no previous command found for prompt %q", prompt)
+}
+
+// matchingLines returns the lines matching expr; expr is a regular
+// expression, or a plain substring when it does not compile.
+func matchingLines(lines []string, expr string) ([]string, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		re = regexp.MustCompile(regexp.QuoteMeta(expr))
+	}
+	var out []string
+	for _, l := range lines {
+		if re.MatchString(l) {
+			out = append(out, l)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no line matches %q", expr)
+	}
+	return out, nil
+}
+
+func trimBlankTail(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// clipLines drops leading lines until lines fit into max bytes joined by
+// newlines. When the last line alone is too long, only its end is kept and
+// cut is set.
+func clipLines(lines []string, max int) (out []string, dropped int, cut bool) {
+	size := -1
+	for _, l := range lines {
+		size += len(l) + 1
+	}
+	for len(lines) > 1 && size > max {
+		size -= len(lines[0]) + 1
+		lines = lines[1:]
+		dropped++
+	}
+	if len(lines) == 1 && len(lines[0]) > max {
+		last := strings.ToValidUTF8(lines[0][len(lines[0])-max:], "")
+		return []string{last}, dropped, true
+	}
+	return lines, dropped, false
+}
+
+// copyToClipboard sends text to the clipboard of the terminal make-sync runs
+// in with OSC 52, wrapped for tmux or screen when running inside one. For
+// local slots the native clipboard is written as well, for terminals that do
+// not support OSC 52. It returns how the text was copied.
+func copyToClipboard(text string, native bool) (string, error) {
+	seq := osc52.New(text)
+	switch term := os.Getenv("TERM"); {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(term, "screen"):
+		seq = seq.Screen()
+	}
+	var via []string
+	if _, err := seq.WriteTo(os.Stdout); err == nil {
+		via = append(via, "OSC 52")
+	}
+	if native {
+		if clipboard.Unsupported {
+			if len(via) == 0 {
+				return "", fmt.Errorf("no native clipboard tool found (xclip, xsel, wl-copy)")
+			}
+		} else if err := clipboard.WriteAll(text); err != nil {
+			if len(via) == 0 {
+				return "", err
+			}
+		} else {
+			via = append(via, "native clipboard")
+		}
+	}
+	if len(via) == 0 {
+		return "", fmt.Errorf("could not write to the terminal")
+	}
+	return strings.Join(via, " + "), nil
+}
+
+// isLocalSlot reports whether slot runs on this machine.
+func (m *PTYManager) isLocalSlot(slot int) bool {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil {
+		return false
+	}
+	_, local := s.Bridge.(*localclient.PTYLocalBridge)
+	return local
+}
+
+// showCopyMenu copies part of the scrollback of slot to the clipboard.
+// Callers hold promptMu.
+func (w *Watcher) showCopyMenu(slot int) {
+	lines, err := w.ptyMgr.ScrollbackLines(slot)
+	if err != nil {
+		util.Default.MenuPrintf("❌ %v\n", err)
+		return
+	}
+	items := []string{"Last N lines", "Last command's output", "Lines matching a search", "Cancel"}
+	sel := promptui.Select{
+		Label:    fmt.Sprintf("📋 Copy from slot %d to the clipboard", slot),
+		Items:    items,
+		Size:     len(items),
+		HideHelp: true,
+	}
+	i, _, err := sel.Run()
+	if err != nil {
+		return
+	}
+	var picked []string
+	switch i {
+	case 0:
+		prompt := promptui.Prompt{
+			Label:   "Lines",
+			Default: strconv.Itoa(defaultCopyLines),
+			Validate: func(s string) error {
+				if n, err := strconv.Atoi(strings.TrimSpace(s)); err != nil || n < 1 {
+					return fmt.Errorf("enter a positive number")
+				}
+				return nil
+			},
+		}
+		s, err := prompt.Run()
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(s))
+		picked = lastLines(lines, n)
+	case 1:
+		picked, err = lastCommandOutput(lines)
+	case 2:
+		prompt := promptui.Prompt{Label: "Search (regexp)"}
+		expr, perr := prompt.Run()
+		if perr != nil || strings.TrimSpace(expr) == "" {
+			return
+		}
+		picked, err = matchingLines(lines, expr)
+	default:
+		return
+	}
+	if err != nil {
+		util.Default.MenuPrintf("❌ %v\n", err)
+		return
+	}
+	if len(picked) == 0 {
+		util.Default.MenuPrintf("❌ Nothing to copy\n")
+		return
+	}
+	picked, dropped, cut := clipLines(picked, maxOSC52Bytes)
+	via, err := copyToClipboard(strings.Join(picked, "\n"), w.ptyMgr.isLocalSlot(slot))
+	if err != nil {
+		util.Default.MenuPrintf("❌ Copy failed: %v\n", err)
+		return
+	}
+	util.Default.MenuPrintf("📋 Copied %d lines via %s\n", len(picked), via)
+	switch {
+	case cut:
+		util.Default.MenuPrintf("⚠️  Over the %d byte clipboard limit: copied only the end of the last line, %d lines before it dropped\n", maxOSC52Bytes, dropped)
+	case dropped > 0:
+		util.Default.MenuPrintf("⚠️  Over the %d byte clipboard limit: the first %d lines were dropped\n", maxOSC52Bytes, dropped)
+	}
+}
@@ -0,0 +1,67 @@
+package devsync
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLastLines(t *testing.T) {
+	lines := []string{"a", "b", "c", "", "  "}
+	if got := lastLines(lines, 2); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("got %q", got)
+	}
+	if got := lastLines(lines, 10); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestLastCommandOutput(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  []string
+		err   bool
+	}{
+		{[]string{"u@h:~$ ls", "a", "u@h:~$ make", "x", "y", "", "u@h:~$ ", ""}, []string{"x", "y"}, false},
+		{[]string{"$ make", "out", "$"}, []string{"out"}, false},
+		{[]string{"u@h:~$ true", "u@h:~$ "}, nil, true},        // printed nothing
+		{[]string{"some output", "more output"}, nil, true},    // no earlier prompt
+		{[]string{"u@h:~$ "}, nil, true},                       // no command at all
+		{[]string{"u@h:~$ make", "out", "", "   "}, nil, true}, // no prompt on the last line
+	}
+	for _, tt := range tests {
+		got, err := lastCommandOutput(tt.lines)
+		if (err != nil) != tt.err || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %q, %v", tt.lines, got, err)
+		}
+	}
+}
+
+func TestMatchingLines(t *testing.T) {
+	lines := []string{"GET /a 200", "GET /b 500", "POST /c 502", "oops (x"}
+	if got, _ := matchingLines(lines, ` 5\d\d$`); !reflect.DeepEqual(got, []string{"GET /b 500", "POST /c 502"}) {
+		t.Errorf("regexp: got %q", got)
+	}
+	// not a valid regexp: matched as plain text
+	if got, _ := matchingLines(lines, "(x"); !reflect.DeepEqual(got, []string{"oops (x"}) {
+		t.Errorf("literal: got %q", got)
+	}
+	if _, err := matchingLines(lines, "DELETE"); err == nil {
+		t.Error("no match: want an error")
+	}
+}
+
+func TestClipLines(t *testing.T) {
+	got, dropped, cut := clipLines([]string{"aaaa", "bb", "cc"}, 5)
+	if !reflect.DeepEqual(got, []string{"bb", "cc"}) || dropped != 1 || cut {
+		t.Errorf("got %q, dropped %d, cut %v", got, dropped, cut)
+	}
+	got, dropped, cut = clipLines([]string{"a", strings.Repeat("x", 8)}, 5)
+	if !reflect.DeepEqual(got, []string{"xxxxx"}) || dropped != 1 || !cut {
+		t.Errorf("got %q, dropped %d, cut %v", got, dropped, cut)
+	}
+	got, dropped, cut = clipLines([]string{"ab", "cd"}, 5)
+	if len(got) != 2 || dropped != 0 || cut {
+		t.Errorf("fitting lines changed: %q", got)
+	}
+}
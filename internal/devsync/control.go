@@ -0,0 +1,383 @@
+package devsync
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ControlSocketFile is the control endpoint of a running devsync instance,
+// relative to the directory devsync was started from. On Windows a named
+// pipe derived from that directory is used instead (see control_windows.go).
+const ControlSocketFile = ".sync_temp/devsync.sock"
+
+// Control operations understood by the control API.
+const (
+	ControlOpStatus    = "status"
+	ControlOpPause     = "pause"
+	ControlOpResume    = "resume"
+	ControlOpReconcile = "reconcile"
+	ControlOpReload    = "reload"
+	ControlOpEvents    = "events"
+	ControlOpMute      = "mute"
+	ControlOpUnmute    = "unmute"
+)
+
+// ControlRequest is a single JSON line sent by a control client.
+type ControlRequest struct {
+	Op string `json:"op"`
+	// Path is the subtree for mute/unmute (absolute or relative to the watch path)
+	Path string `json:"path,omitempty"`
+}
+
+// ControlStatus describes what a running watcher is doing.
+type ControlStatus struct {
+	PID          int       `json:"pid"`
+	WatchPath    string    `json:"watch_path"`
+	StartedAt    time.Time `json:"started_at"`
+	Watching     bool      `json:"watching"`
+	Paused       bool      `json:"paused"`
+	Muted        []string  `json:"muted"`
+	Reconciling  bool      `json:"reconciling"`
+	QueueDepth   int       `json:"queue_depth"`
+	LastSync     time.Time `json:"last_sync"`
+	LastSyncPath string    `json:"last_sync_path,omitempty"`
+	AgentPID     string    `json:"agent_pid,omitempty"`
+	AgentHealth  string    `json:"agent_health"`
+	// Targets are the extra devsync.targets, devsync.auth excluded
+	Targets []TargetStatus `json:"targets,omitempty"`
+	// Slots are the open PTY slots
+	Slots []SlotStatus `json:"slots,omitempty"`
+}
+
+// SlotStatus describes an open PTY slot.
+type SlotStatus struct {
+	Slot    int    `json:"slot"`
+	Name    string `json:"name,omitempty"`
+	Command string `json:"command"`
+	// Broadcast is set while keyboard input is mirrored to this slot
+	Broadcast bool `json:"broadcast,omitempty"`
+	// ExitCode, Restarts and GaveUp describe a supervised session command
+	ExitCode *int `json:"exit_code,omitempty"`
+	Restarts int  `json:"restarts,omitempty"`
+	GaveUp   bool `json:"gave_up,omitempty"`
+}
+
+// ControlEvent is streamed to clients subscribed with the events op.
+type ControlEvent struct {
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"`
+	Path   string    `json:"path,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// ControlResponse is the JSON line answering a ControlRequest. For the
+// events op the first response acknowledges the subscription and every
+// following line carries one Event.
+type ControlResponse struct {
+	OK      bool           `json:"ok"`
+	Error   string         `json:"error,omitempty"`
+	Message string         `json:"message,omitempty"`
+	Status  *ControlStatus `json:"status,omitempty"`
+	Event   *ControlEvent  `json:"event,omitempty"`
+}
+
+// controlServer accepts control connections for one watcher.
+type controlServer struct {
+	w    *Watcher
+	ln   net.Listener
+	mu   sync.Mutex
+	subs map[chan ControlEvent]struct{}
+	done chan struct{}
+}
+
+// startControlServer opens the control endpoint. Failures are reported but
+// never stop devsync from running.
+func (w *Watcher) startControlServer() {
+	ln, err := listenControl(w.workingDir)
+	if err != nil {
+		w.safePrintf("⚠️  Control API unavailable: %v\n", err)
+		return
+	}
+	cs := &controlServer{
+		w:    w,
+		ln:   ln,
+		subs: make(map[chan ControlEvent]struct{}),
+		done: make(chan struct{}),
+	}
+	w.control.Store(cs)
+	go cs.serve()
+}
+
+// stopControlServer closes the control endpoint and all event streams.
+func (w *Watcher) stopControlServer() {
+	cs := w.control.Swap(nil)
+	if cs == nil {
+		return
+	}
+	cs.mu.Lock()
+	select {
+	case <-cs.done:
+	default:
+		close(cs.done)
+	}
+	cs.mu.Unlock()
+	_ = cs.ln.Close()
+	removeControlEndpoint(w.workingDir)
+}
+
+func (cs *controlServer) serve() {
+	for {
+		conn, err := cs.ln.Accept()
+		if err != nil {
+			select {
+			case <-cs.done:
+				return
+			default:
+			}
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		go cs.handle(conn)
+	}
+}
+
+func (cs *controlServer) handle(conn net.Conn) {
+	defer conn.Close()
+	enc := json.NewEncoder(conn)
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && strings.TrimSpace(line) == "" {
+		return
+	}
+	var req ControlRequest
+	if err := json.Unmarshal([]byte(line), &req); err != nil {
+		_ = enc.Encode(ControlResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	w := cs.w
+	req.Op = strings.ToLower(strings.TrimSpace(req.Op))
+	switch req.Op {
+	case ControlOpStatus:
+		st := w.controlStatus()
+		_ = enc.Encode(ControlResponse{OK: true, Status: &st})
+	case ControlOpPause:
+		msg := "uploads paused"
+		if !w.PauseUploads() {
+			msg = "uploads already paused"
+		}
+		_ = enc.Encode(ControlResponse{OK: true, Message: msg})
+	case ControlOpResume:
+		msg := "uploads resumed"
+		if !w.ResumeUploads() {
+			msg = "uploads were not paused"
+		}
+		_ = enc.Encode(ControlResponse{OK: true, Message: msg})
+	case ControlOpReconcile:
+		if err := w.TriggerReconcile(); err != nil {
+			_ = enc.Encode(ControlResponse{Error: err.Error()})
+			return
+		}
+		_ = enc.Encode(ControlResponse{OK: true, Message: "reconcile started"})
+	case ControlOpReload:
+		if err := w.reload(); err != nil {
+			_ = enc.Encode(ControlResponse{Error: err.Error()})
+			return
+		}
+		_ = enc.Encode(ControlResponse{OK: true, Message: "configuration reloaded"})
+	case ControlOpMute, ControlOpUnmute:
+		fn, verb := w.MutePath, "muted"
+		if req.Op == ControlOpUnmute {
+			fn, verb = w.UnmutePath, "unmuted"
+		}
+		abs, err := fn(req.Path)
+		if err != nil {
+			_ = enc.Encode(ControlResponse{Error: err.Error()})
+			return
+		}
+		_ = enc.Encode(ControlResponse{OK: true, Message: verb + " " + abs})
+	case ControlOpEvents:
+		cs.stream(conn, enc)
+	default:
+		_ = enc.Encode(ControlResponse{Error: fmt.Sprintf("unknown op %q", req.Op)})
+	}
+}
+
+// stream forwards watcher events to conn until the client disconnects or
+// the server stops.
+func (cs *controlServer) stream(conn net.Conn, enc *json.Encoder) {
+	ch := make(chan ControlEvent, 64)
+	cs.mu.Lock()
+	cs.subs[ch] = struct{}{}
+	cs.mu.Unlock()
+	defer func() {
+		cs.mu.Lock()
+		delete(cs.subs, ch)
+		cs.mu.Unlock()
+	}()
+
+	if err := enc.Encode(ControlResponse{OK: true, Message: "subscribed"}); err != nil {
+		return
+	}
+
+	// Detect client disconnects: clients never send anything after the request
+	gone := make(chan struct{})
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				close(gone)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case ev := <-ch:
+			if err := enc.Encode(ControlResponse{OK: true, Event: &ev}); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		case <-cs.done:
+			return
+		}
+	}
+}
+
+// publish sends ev to every subscriber without blocking; slow subscribers
+// lose events rather than stalling the watcher.
+func (cs *controlServer) publish(ev ControlEvent) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for ch := range cs.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// controlSyncEvents are the event types that count as a completed sync.
+var controlSyncEvents = map[string]bool{
+	"upload":        true,
+	"delete_remote": true,
+	"move_remote":   true,
+	"download":      true,
+	"delete_local":  true,
+}
+
+// emitControlEvent records sync progress, fires matching devsync.hooks for
+// completed syncs and forwards ev to control clients.
+func (w *Watcher) emitControlEvent(typ, path, detail string) {
+	ev := ControlEvent{Time: time.Now(), Type: typ, Path: path, Detail: detail}
+	if controlSyncEvents[typ] {
+		w.lastSyncMu.Lock()
+		w.lastSyncAt = ev.Time
+		w.lastSyncPath = path
+		w.lastSyncMu.Unlock()
+		w.triggerHooks(typ, path)
+	}
+	if cs := w.control.Load(); cs != nil {
+		cs.publish(ev)
+	}
+}
+
+// controlStatus snapshots the watcher state for the status op.
+func (w *Watcher) controlStatus() ControlStatus {
+	w.runningMu.Lock()
+	watching := w.running
+	w.runningMu.Unlock()
+
+	w.lastSyncMu.Lock()
+	lastSync, lastPath := w.lastSyncAt, w.lastSyncPath
+	w.lastSyncMu.Unlock()
+
+	return ControlStatus{
+		PID:          os.Getpid(),
+		WatchPath:    w.watchPath,
+		StartedAt:    w.startedAt,
+		Watching:     watching,
+		Paused:       w.uploadsPaused.Load(),
+		Muted:        w.MutedPaths(),
+		Reconciling:  w.reconciling.Load(),
+		QueueDepth:   w.queueDepth(),
+		LastSync:     lastSync,
+		LastSyncPath: lastPath,
+		AgentPID:     w.agentPID,
+		AgentHealth:  w.agentHealthSummary(),
+		Targets:      w.targetStatuses(),
+		Slots:        w.slotStatuses(),
+	}
+}
+
+// queueDepth counts events waiting in the debouncer and the upload queue.
+func (w *Watcher) queueDepth() int {
+	w.debounceMu.Lock()
+	n := len(w.debounceMap)
+	w.debounceMu.Unlock()
+	return n + len(w.eventQueue)
+}
+
+// SendControlRequest sends req to the devsync instance started from dir and
+// returns its response.
+func SendControlRequest(dir string, req ControlRequest) (*ControlResponse, error) {
+	conn, err := dialControl(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	var resp ControlResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+	return &resp, nil
+}
+
+// StreamControlEvents subscribes to the events of the devsync instance
+// started from dir and calls fn for each one until the connection closes or
+// stop is closed.
+func StreamControlEvents(dir string, stop <-chan struct{}, fn func(ControlEvent)) error {
+	conn, err := dialControl(dir)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	go func() {
+		<-stop
+		_ = conn.Close()
+	}()
+
+	if err := json.NewEncoder(conn).Encode(ControlRequest{Op: ControlOpEvents}); err != nil {
+		return fmt.Errorf("failed to send request: %v", err)
+	}
+	dec := json.NewDecoder(conn)
+	for {
+		var resp ControlResponse
+		if err := dec.Decode(&resp); err != nil {
+			select {
+			case <-stop:
+				return nil
+			default:
+			}
+			return fmt.Errorf("event stream closed: %v", err)
+		}
+		if !resp.OK {
+			return fmt.Errorf("%s", resp.Error)
+		}
+		if resp.Event != nil {
+			fn(*resp.Event)
+		}
+	}
+}
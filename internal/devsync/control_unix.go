@@ -0,0 +1,51 @@
+//go:build !windows
+// +build !windows
+
+package devsync
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// unixSocketPathMax is a conservative limit for sun_path (104 on macOS).
+const unixSocketPathMax = 100
+
+// controlEndpoint returns the socket path for dir, preferring a path relative
+// to the current directory when the absolute one would be too long.
+func controlEndpoint(dir string) string {
+	p := filepath.Join(dir, filepath.FromSlash(ControlSocketFile))
+	if len(p) <= unixSocketPathMax {
+		return p
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, p); err == nil && len(rel) < len(p) {
+			return rel
+		}
+	}
+	return p
+}
+
+func listenControl(dir string) (net.Listener, error) {
+	p := controlEndpoint(dir)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return nil, err
+	}
+	// A socket left by a killed predecessor (see handlePidSingleton) blocks bind
+	_ = os.Remove(p)
+	ln, err := net.Listen("unix", p)
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Chmod(p, 0600)
+	return ln, nil
+}
+
+func dialControl(dir string) (net.Conn, error) {
+	return net.Dial("unix", controlEndpoint(dir))
+}
+
+func removeControlEndpoint(dir string) {
+	_ = os.Remove(controlEndpoint(dir))
+}
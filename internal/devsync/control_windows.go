@@ -0,0 +1,141 @@
+//go:build windows
+// +build windows
+
+package devsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// controlEndpoint returns the named pipe used by the devsync instance started
+// from dir. The name is derived from the absolute directory so every project
+// gets its own pipe.
+func controlEndpoint(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(abs)))
+	return `\\.\pipe\make-sync-` + hex.EncodeToString(sum[:8])
+}
+
+// pipeAddr implements net.Addr for named pipes.
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
+
+// pipeConn adapts a connected pipe handle to net.Conn. Deadlines are not
+// supported; the control protocol does not need them.
+type pipeConn struct {
+	*os.File
+	addr pipeAddr
+}
+
+func (c *pipeConn) LocalAddr() net.Addr                { return c.addr }
+func (c *pipeConn) RemoteAddr() net.Addr               { return c.addr }
+func (c *pipeConn) SetDeadline(t time.Time) error      { return nil }
+func (c *pipeConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *pipeConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// pipeListener accepts clients by creating one pipe instance per connection.
+type pipeListener struct {
+	name   string
+	mu     sync.Mutex
+	closed bool
+}
+
+func listenControl(dir string) (net.Listener, error) {
+	l := &pipeListener{name: controlEndpoint(dir)}
+	// Fail early when another instance owns the pipe
+	h, err := l.create(true)
+	if err != nil {
+		return nil, err
+	}
+	_ = windows.CloseHandle(h)
+	return l, nil
+}
+
+func (l *pipeListener) create(first bool) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(l.name)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+	flags := uint32(windows.PIPE_ACCESS_DUPLEX)
+	if first {
+		flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	return windows.CreateNamedPipe(name, flags,
+		windows.PIPE_TYPE_BYTE|windows.PIPE_READMODE_BYTE|windows.PIPE_WAIT,
+		windows.PIPE_UNLIMITED_INSTANCES, 4096, 4096, 0, nil)
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	h, err := l.create(false)
+	if err != nil {
+		return nil, err
+	}
+	if err := windows.ConnectNamedPipe(h, nil); err != nil && !errors.Is(err, windows.ERROR_PIPE_CONNECTED) {
+		_ = windows.CloseHandle(h)
+		return nil, err
+	}
+	l.mu.Lock()
+	closed := l.closed
+	l.mu.Unlock()
+	if closed {
+		_ = windows.DisconnectNamedPipe(h)
+		_ = windows.CloseHandle(h)
+		return nil, net.ErrClosed
+	}
+	return &pipeConn{File: os.NewFile(uintptr(h), l.name), addr: pipeAddr(l.name)}, nil
+}
+
+func (l *pipeListener) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+	// Wake a pending ConnectNamedPipe so Accept can observe the close
+	if c, err := dialControlPipe(l.name); err == nil {
+		_ = c.Close()
+	}
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr { return pipeAddr(l.name) }
+
+// dialControlPipe opens the pipe, retrying briefly while the server is
+// between two Accept calls and all instances are busy.
+func dialControlPipe(name string) (net.Conn, error) {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		f, err := os.OpenFile(name, os.O_RDWR, 0)
+		if err == nil {
+			return &pipeConn{File: f, addr: pipeAddr(name)}, nil
+		}
+		if !errors.Is(err, windows.ERROR_PIPE_BUSY) || time.Now().After(deadline) {
+			return nil, err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func dialControl(dir string) (net.Conn, error) {
+	return dialControlPipe(controlEndpoint(dir))
+}
+
+// removeControlEndpoint is a no-op: named pipes vanish with their last handle.
+func removeControlEndpoint(dir string) {}
@@ -139,6 +139,12 @@ mainMenuLoop:
 		case 1: // Sync: Pull (from remote)
 			util.ResetRaw(oldStage)
 
+			// With devsync.targets, pull from the one chosen as source of truth
+			pullCfg, ok := choosePullTarget(cfg)
+			if !ok {
+				continue
+			}
+
 			// Mode submenu: show fast menu first, defer SSH connect until user confirms
 			// Note: bypass options removed from top-level Pull menu (bypass remains available in other flows)
 			mode, err := tui.ShowMenuWithPrints([]string{"Safe (no deletes)", "Force (may delete remote/local)", "Back"}, "Pull — choose mode")
@@ -163,7 +169,7 @@ mainMenuLoop:
 			}
 
 			// Now connect SSH (may be slower) only when we're actually going to run the operation
-			sshClient, err := createSSHClient(cfg)
+			sshClient, err := createSSHClient(pullCfg)
 			if err != nil {
 				util.Default.Printf("❌ Failed to connect SSH: %v\n", err)
 				return "error"
@@ -174,7 +180,7 @@ mainMenuLoop:
 			// operation from the post-operation menu without reconnecting.
 		pullLoop:
 			for {
-				result := syncdata.RunPullWithMode(cfg, sshClient, mode)
+				result := syncdata.RunPullWithMode(pullCfg, sshClient, mode)
 				if !result.Success {
 					util.Default.Printf("❌ Pull failed: %v\n", result.Error)
 					return "error"
@@ -215,30 +221,39 @@ mainMenuLoop:
 				}
 			}
 
-			// Connect only when needed
-			sshClient, err := createSSHClient(cfg)
-			if err != nil {
-				util.Default.Printf("❌ Failed to connect SSH: %v\n", err)
-				return "error"
-			}
-			defer sshClient.Close()
+			// Push to devsync.auth and then to every entry of devsync.targets
+			for _, t := range cfg.SyncTargets() {
+				pushCfg := cfg.ForTarget(t)
+				if cfg.HasTargets() {
+					util.Default.Printf("🎯 Push to target %s\n", t.Label())
+				}
 
-			// Run push with chosen mode. Allow retry from the post-operation menu
-			// so user can re-run the same push without reconnecting.
-		pushLoop:
-			for {
-				result := syncdata.RunPushWithMode(cfg, sshClient, mode)
-				if !result.Success {
-					util.Default.Printf("❌ Push failed: %v\n", result.Error)
+				// Connect only when needed
+				sshClient, err := createSSHClient(pushCfg)
+				if err != nil {
+					util.Default.Printf("❌ Failed to connect SSH: %v\n", err)
 					return "error"
 				}
 
-				action := syncdata.ShowPostSafePushMenu()
-				if action == syncdata.RetryOperation {
-					util.Default.Println("🔄 Retrying safe push as requested by post-menu...")
-					continue pushLoop
+				// Run push with chosen mode. Allow retry from the post-operation menu
+				// so user can re-run the same push without reconnecting.
+			pushLoop:
+				for {
+					result := syncdata.RunPushWithMode(pushCfg, sshClient, mode)
+					if !result.Success {
+						util.Default.Printf("❌ Push failed: %v\n", result.Error)
+						sshClient.Close()
+						return "error"
+					}
+
+					action := syncdata.ShowPostSafePushMenu()
+					if action == syncdata.RetryOperation {
+						util.Default.Println("🔄 Retrying safe push as requested by post-menu...")
+						continue pushLoop
+					}
+					break
 				}
-				break
+				sshClient.Close()
 			}
 			continue mainMenuLoop
 		case 3: // force_manual_sync
@@ -400,6 +415,31 @@ func basicNewSessionSSH(cfg *config.Config) error {
 // platform-specific implementations of flushStdin() and sendEnter()
 // are provided in separate files with build tags (termio_windows.go / termio_unix.go)
 
+// choosePullTarget asks which target pull treats as the source of truth
+// when devsync.targets is configured. ok is false when the user went back.
+func choosePullTarget(cfg *config.Config) (*config.Config, bool) {
+	if !cfg.HasTargets() {
+		return cfg, true
+	}
+	targets := cfg.SyncTargets()
+	items := make([]string, 0, len(targets)+1)
+	for i, t := range targets {
+		items = append(items, fmt.Sprintf("%d. %s", i+1, t.Label()))
+	}
+	items = append(items, "Back")
+	choice, err := tui.ShowMenuWithPrints(items, "Pull — choose the source of truth")
+	if err != nil {
+		util.Default.Printf("❌ Target selection cancelled: %v\n", err)
+		return nil, false
+	}
+	for i, it := range items[:len(targets)] {
+		if it == choice {
+			return cfg.ForTarget(targets[i]), true
+		}
+	}
+	return nil, false
+}
+
 // createSSHClient creates and connects an SSH client using values from cfg.Devsync.Auth
 func createSSHClient(cfg *config.Config) (*sshclient.SSHClient, error) {
 	auth := cfg.Devsync.Auth
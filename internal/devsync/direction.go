@@ -0,0 +1,59 @@
+package devsync
+
+import (
+	"path/filepath"
+)
+
+// allowsPush reports whether a local change of localPath may be uploaded
+// under the configured sync direction. Refusals go to control clients.
+func (w *Watcher) allowsPush(localPath string) bool {
+	return w.checkDirection(localPath, true)
+}
+
+// allowsPull reports whether a remote change of localPath may be applied
+// locally under the configured sync direction.
+func (w *Watcher) allowsPull(localPath string) bool {
+	return w.checkDirection(localPath, false)
+}
+
+func (w *Watcher) checkDirection(localPath string, push bool) bool {
+	w.configMu.RLock()
+	cfg := w.config
+	w.configMu.RUnlock()
+	if cfg == nil {
+		return true
+	}
+	rel, err := filepath.Rel(w.watchPath, localPath)
+	if err != nil {
+		rel = localPath
+	}
+	allowed, decidedBy := cfg.AllowsPull(filepath.ToSlash(rel))
+	if push {
+		allowed, decidedBy = cfg.AllowsPush(filepath.ToSlash(rel))
+	}
+	if !allowed {
+		w.emitControlEvent("direction_blocked", localPath, decidedBy)
+	}
+	return allowed
+}
+
+// applyDirections moves the changes the sync direction does not allow out
+// of the reconcile plan.
+func (w *Watcher) applyDirections(p *reconcilePlan) {
+	keep := func(rels []string, push bool) []string {
+		out := rels[:0]
+		for _, rel := range rels {
+			abs := filepath.Join(w.watchPath, filepath.FromSlash(rel))
+			if w.checkDirection(abs, push) {
+				out = append(out, rel)
+			} else {
+				p.oneWay = append(p.oneWay, rel)
+			}
+		}
+		return out
+	}
+	p.upload = keep(p.upload, true)
+	p.deleteRemote = keep(p.deleteRemote, true)
+	p.download = keep(p.download, false)
+	p.deleteLocal = keep(p.deleteLocal, false)
+}
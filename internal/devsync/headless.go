@@ -0,0 +1,114 @@
+package devsync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"make-sync/internal/config"
+	"make-sync/internal/util"
+)
+
+// DefaultHeadlessLogFile is where `make-sync devsync --headless` writes its
+// output when no --log-file is given (relative to the working directory).
+const DefaultHeadlessLogFile = ".sync_temp/logs/devsync-headless.log"
+
+// RunHeadless runs file watching, agent monitoring and sync without any TTY
+// requirements (no menus, raw mode, keyboard handling or PTY slots). All
+// output goes to logPath. SIGHUP reloads the configuration; SIGTERM and
+// SIGINT (or ctx cancellation) stop the watcher through gracefulShutdown.
+func RunHeadless(ctx context.Context, cfg *config.Config, logPath string) error {
+	if logPath == "" {
+		logPath = DefaultHeadlessLogFile
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %v", err)
+	}
+	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	defer logFile.Close()
+
+	// Route the shared printer (without terminal control sequences) and any
+	// direct stdout/stderr writes into the log file.
+	origStdout, origStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = logFile, logFile
+	util.Default.SetOutput(logFile, true)
+	defer func() {
+		util.Default.SetOutput(nil, false)
+		os.Stdout, os.Stderr = origStdout, origStderr
+	}()
+
+	util.Default.Printf("🕶️  [%s] Headless devsync starting (pid %d)\n", time.Now().Format("2006-01-02 15:04:05"), os.Getpid())
+
+	// Subscribe before the watcher starts so early signals are not lost
+	sigCh := make(chan os.Signal, 4)
+	signal.Notify(sigCh, syscall.SIGHUP, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(sigCh)
+
+	// NewWatcher runs handlePidSingleton, so a previous instance for this
+	// project is stopped before the agent is deployed.
+	w, err := NewWatcher(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %v", err)
+	}
+	defer w.removeOwnPidFile()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- w.StartHeadless()
+	}()
+
+	shutdown := func(reason string) error {
+		util.Default.Printf("🛑 [%s] %s, shutting down\n", time.Now().Format("2006-01-02 15:04:05"), reason)
+		w.gracefulShutdown()
+		select {
+		case err := <-runErr:
+			return err
+		case <-time.After(10 * time.Second):
+			util.Default.Println("⚠️  Timeout waiting for watcher to stop")
+			return nil
+		}
+	}
+
+	for {
+		select {
+		case sig := <-sigCh:
+			if sig == syscall.SIGHUP {
+				util.Default.Printf("🔁 [%s] SIGHUP received, reloading configuration\n", time.Now().Format("2006-01-02 15:04:05"))
+				w.HandleReloadCommand()
+				continue
+			}
+			return shutdown(fmt.Sprintf("%v received", sig))
+		case <-ctx.Done():
+			return shutdown("context cancelled")
+		case err := <-runErr:
+			// watcher stopped on its own (e.g. notify failure)
+			if err != nil {
+				util.Default.Printf("❌ Watcher exited with error: %v\n", err)
+			}
+			w.gracefulShutdown()
+			return err
+		}
+	}
+}
+
+// removeOwnPidFile deletes the PID file written by handlePidSingleton if it
+// still belongs to this process.
+func (w *Watcher) removeOwnPidFile() {
+	pidFile := w.pidFileName()
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		return
+	}
+	if pid, _ := strconv.Atoi(strings.TrimSpace(string(data))); pid == os.Getpid() {
+		_ = os.Remove(pidFile)
+	}
+}
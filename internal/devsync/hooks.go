@@ -0,0 +1,283 @@
+package devsync
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"make-sync/internal/config"
+	"make-sync/internal/util"
+)
+
+// hookInvocation is one matching sync event for a hook.
+type hookInvocation struct {
+	event  string
+	local  string
+	remote string
+	rel    string
+	// target is the devsync.targets entry the event was synced to, nil for
+	// devsync.auth
+	target *targetSync
+}
+
+// hookRunner tracks the debounce timer and running state of one hook.
+type hookRunner struct {
+	mu      sync.Mutex
+	timer   *time.Timer
+	last    hookInvocation
+	running int
+	pending *hookInvocation
+}
+
+// hookKey identifies a hook across config reloads.
+func hookKey(h config.Hook) string {
+	return strings.Join([]string{h.Name, h.Glob, h.Side, h.Command}, "\x00")
+}
+
+// hookLabel is how a hook is shown in output and control events.
+func hookLabel(h config.Hook) string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Glob
+}
+
+// triggerHooks fires every configured hook matching a completed sync event.
+// localPath is the local side of the synced path.
+func (w *Watcher) triggerHooks(event, localPath string) {
+	w.configMu.RLock()
+	cfg := w.config
+	w.configMu.RUnlock()
+	if cfg == nil || len(cfg.Devsync.Hooks) == 0 || localPath == "" {
+		return
+	}
+
+	rel, err := filepath.Rel(w.watchPath, localPath)
+	if err != nil {
+		return
+	}
+	rel = filepath.ToSlash(rel)
+	inv := hookInvocation{event: event, local: localPath, remote: w.remotePathFor(rel), rel: rel}
+
+	for _, h := range cfg.Devsync.Hooks {
+		if !hookWantsEvent(h, event) || !config.MatchTriggerPattern(h.Glob, rel) {
+			continue
+		}
+		w.fireHook(h, inv)
+	}
+}
+
+func hookWantsEvent(h config.Hook, event string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if strings.EqualFold(strings.TrimSpace(e), event) {
+			return true
+		}
+	}
+	return false
+}
+
+// fireHook runs h now, or after its debounce delay for the last invocation.
+// Each target debounces and queues its runs of h separately.
+func (w *Watcher) fireHook(h config.Hook, inv hookInvocation) {
+	key := hookKey(h)
+	if inv.target != nil {
+		key += "\x00" + inv.target.target.Label()
+	}
+	w.hooksMu.Lock()
+	if w.hookRunners == nil {
+		w.hookRunners = make(map[string]*hookRunner)
+	}
+	r, ok := w.hookRunners[key]
+	if !ok {
+		r = &hookRunner{}
+		w.hookRunners[key] = r
+	}
+	w.hooksMu.Unlock()
+
+	if h.Debounce <= 0 {
+		w.startHook(h, r, inv)
+		return
+	}
+	r.mu.Lock()
+	r.last = inv
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.timer = time.AfterFunc(time.Duration(h.Debounce)*time.Millisecond, func() {
+		r.mu.Lock()
+		last := r.last
+		r.mu.Unlock()
+		w.startHook(h, r, last)
+	})
+	r.mu.Unlock()
+}
+
+// Outcomes of hookRunner.admit.
+const (
+	hookRun = iota
+	hookSkipped
+	hookQueued
+)
+
+// admit applies the concurrency policy of h to inv. On hookRun the caller
+// must run inv and call done afterwards.
+func (r *hookRunner) admit(h config.Hook, inv hookInvocation) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running > 0 {
+		switch strings.ToLower(strings.TrimSpace(h.Concurrency)) {
+		case config.HookSkip:
+			return hookSkipped
+		case config.HookParallel:
+		default:
+			// Queue: collapse everything that arrives meanwhile into one rerun
+			r.pending = &inv
+			return hookQueued
+		}
+	}
+	r.running++
+	return hookRun
+}
+
+// done marks a run finished and returns the queued invocation, if any.
+func (r *hookRunner) done() *hookInvocation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.running--
+	next := r.pending
+	r.pending = nil
+	return next
+}
+
+// startHook applies the concurrency policy and runs h in the background.
+func (w *Watcher) startHook(h config.Hook, r *hookRunner, inv hookInvocation) {
+	switch r.admit(h, inv) {
+	case hookSkipped:
+		w.safePrintf("⏭️  Hook %s still running, skipped for %s\n", hookLabel(h), inv.rel)
+		return
+	case hookQueued:
+		return
+	}
+
+	go func() {
+		w.runHook(h, inv)
+		if next := r.done(); next != nil {
+			w.startHook(h, r, *next)
+		}
+	}()
+}
+
+// renderHookCommand fills the {{path}}, {{rel}} and {{event}} placeholders,
+// each quoted for the shell the hook runs in (cmd.exe when windows).
+func renderHookCommand(h config.Hook, inv hookInvocation, windows bool) string {
+	p := inv.remote
+	if strings.EqualFold(strings.TrimSpace(h.Side), "local") {
+		p = inv.local
+	}
+	return strings.NewReplacer(
+		"{{path}}", quoteForShell(p, windows),
+		"{{rel}}", quoteForShell(inv.rel, windows),
+		"{{event}}", quoteForShell(inv.event, windows),
+	).Replace(h.Command)
+}
+
+// sideIsWindows reports whether commands for the local or the remote side
+// run under cmd.exe.
+func (w *Watcher) sideIsWindows(local bool) bool {
+	if local {
+		return runtime.GOOS == "windows"
+	}
+	return strings.Contains(strings.ToLower(w.config.Devsync.OSTarget), "win")
+}
+
+// quoteForShell quotes s as one argument for a POSIX shell, or for cmd.exe
+// when windows (which cannot escape '"' inside quotes, so it becomes ').
+func quoteForShell(s string, windows bool) string {
+	if windows {
+		return "\"" + escapeCmdExe(strings.ReplaceAll(s, "\"", "'")) + "\""
+	}
+	return shellEscape(s)
+}
+
+// runHook executes h on its side and reports the outcome.
+func (w *Watcher) runHook(h config.Hook, inv hookInvocation) {
+	local := strings.EqualFold(strings.TrimSpace(h.Side), "local")
+	cmd := renderHookCommand(h, inv, w.sideIsWindows(local))
+	label := hookLabel(h)
+	side := "remote"
+	if local {
+		side = "local"
+	} else if inv.target != nil {
+		label += " on " + inv.target.target.Label()
+	}
+	w.safePrintf("🪝 Hook %s (%s) for %s %s: %s\n", label, side, inv.event, inv.rel, cmd)
+
+	var out string
+	var err error
+	if inv.target != nil {
+		out, err = inv.target.runCommand(cmd, inv.local)
+	} else {
+		out, err = w.runSideCommand(local, cmd, inv.local)
+	}
+	if strings.TrimSpace(out) != "" {
+		util.Default.PrintBlock(strings.TrimRight(out, "\n"), true)
+	}
+	if err != nil {
+		w.safePrintf("❌ Hook %s failed: %v\n", label, err)
+		w.emitControlEvent("hook_failed", inv.local, label+": "+err.Error())
+		return
+	}
+	w.safePrintf("✅ Hook %s finished\n", label)
+	w.emitControlEvent("hook_finished", inv.local, label)
+}
+
+// runSideCommand runs cmd locally from the project root, or on the remote
+// from the remote root of the mapping owning localPath (the remote project
+// path when localPath is empty), and returns its combined output.
+func (w *Watcher) runSideCommand(local bool, cmd, localPath string) (string, error) {
+	if local {
+		var c *exec.Cmd
+		if runtime.GOOS == "windows" {
+			c = exec.Command("cmd", "/C", cmd)
+		} else {
+			c = exec.Command("bash", "-c", cmd)
+		}
+		c.Dir = w.watchPath
+		b, err := c.CombinedOutput()
+		return string(b), err
+	}
+	if w.sshClient == nil {
+		return "", fmt.Errorf("SSH client not available")
+	}
+	var stdout, stderr bytes.Buffer
+	err := w.sshClient.RunCommandWithIO(remoteHookCommand(w.config, cmd, localPath), nil, &stdout, &stderr)
+	return stdout.String() + stderr.String(), err
+}
+
+// remoteHookCommand runs cmd from the remote root of the mapping of cfg
+// owning localPath, or from the remote project path of cfg.
+func remoteHookCommand(cfg *config.Config, cmd, localPath string) string {
+	remoteBase := cfg.Devsync.Auth.RemotePath
+	if localPath != "" {
+		if i, _, ok := cfg.PathMap().MatchLocal(localPath); ok {
+			if m := cfg.PathMappings()[i]; m.RemotePath != "" {
+				remoteBase = m.RemotePath
+			}
+		}
+	}
+	if remoteBase == "" {
+		return cmd
+	}
+	if strings.Contains(strings.ToLower(cfg.Devsync.OSTarget), "win") {
+		return fmt.Sprintf("cmd.exe /C cd /d \"%s\" && %s", strings.ReplaceAll(remoteBase, "/", "\\"), cmd)
+	}
+	return fmt.Sprintf("cd %s && %s", shellEscape(remoteBase), cmd)
+}
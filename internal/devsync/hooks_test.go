@@ -0,0 +1,104 @@
+package devsync
+
+import (
+	"testing"
+
+	"make-sync/internal/config"
+)
+
+func TestRenderHookCommand(t *testing.T) {
+	inv := hookInvocation{
+		event:  "upload",
+		local:  "/src/app/it's here.txt",
+		remote: "/srv/app/$(rm -rf ~);x.txt",
+		rel:    "a b/100%.txt",
+	}
+	tests := []struct {
+		name    string
+		hook    config.Hook
+		windows bool
+		want    string
+	}{
+		{"remote path", config.Hook{Command: "cat {{path}}"}, false, `cat '/srv/app/$(rm -rf ~);x.txt'`},
+		{"local path", config.Hook{Side: "local", Command: "cat {{path}}"}, false, `cat '/src/app/it'\''s here.txt'`},
+		{"rel and event", config.Hook{Command: "./h.sh {{event}} {{rel}}"}, false, `./h.sh 'upload' 'a b/100%.txt'`},
+		{"cmd.exe", config.Hook{Side: "LOCAL", Command: "type {{rel}} {{path}}"}, true, `type "a b/100%%.txt" "/src/app/it's here.txt"`},
+		{"no placeholders", config.Hook{Command: "composer install"}, false, "composer install"},
+	}
+	for _, tt := range tests {
+		if got := renderHookCommand(tt.hook, inv, tt.windows); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHookMatching(t *testing.T) {
+	tests := []struct {
+		hook  config.Hook
+		event string
+		rel   string
+		want  bool
+	}{
+		{config.Hook{Glob: "composer.lock"}, "upload", "composer.lock", true},
+		{config.Hook{Glob: "composer.lock"}, "download", "composer.lock", true},
+		{config.Hook{Glob: "*.ini", Events: []string{"upload"}}, "upload", "php.ini", true},
+		{config.Hook{Glob: "*.ini", Events: []string{"upload"}}, "delete_remote", "php.ini", false},
+		{config.Hook{Glob: "*.ini", Events: []string{" Delete_Remote "}}, "delete_remote", "php.ini", true},
+		{config.Hook{Glob: "*.ini"}, "upload", "php.conf", false},
+	}
+	for _, tt := range tests {
+		got := hookWantsEvent(tt.hook, tt.event) && config.MatchTriggerPattern(tt.hook.Glob, tt.rel)
+		if got != tt.want {
+			t.Errorf("glob %q events %v on %s %s: got %v, want %v", tt.hook.Glob, tt.hook.Events, tt.event, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestHookRunnerAdmit(t *testing.T) {
+	a, b, c := hookInvocation{rel: "a"}, hookInvocation{rel: "b"}, hookInvocation{rel: "c"}
+	tests := []struct {
+		concurrency string
+		second      int
+		pending     string
+	}{
+		{"", hookQueued, "c"},
+		{config.HookQueue, hookQueued, "c"},
+		{config.HookSkip, hookSkipped, ""},
+		{config.HookParallel, hookRun, ""},
+	}
+	for _, tt := range tests {
+		h := config.Hook{Concurrency: tt.concurrency}
+		r := &hookRunner{}
+		if got := r.admit(h, a); got != hookRun {
+			t.Fatalf("%q: first invocation got %d, want run", tt.concurrency, got)
+		}
+		if got := r.admit(h, b); got != tt.second {
+			t.Fatalf("%q: second invocation got %d, want %d", tt.concurrency, got, tt.second)
+		}
+		r.admit(h, c)
+		next := r.done()
+		switch {
+		case tt.pending == "" && next != nil:
+			t.Errorf("%q: unexpected rerun of %s", tt.concurrency, next.rel)
+		case tt.pending != "" && (next == nil || next.rel != tt.pending):
+			t.Errorf("%q: rerun %v, want %s", tt.concurrency, next, tt.pending)
+		}
+	}
+}
+
+func TestRemoteHookCommand(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Devsync.Auth.RemotePath = "/srv/my app"
+	if got, want := remoteHookCommand(cfg, "make", ""), `cd '/srv/my app' && make`; got != want {
+		t.Errorf("linux: got %s, want %s", got, want)
+	}
+	cfg.Devsync.OSTarget = "windows"
+	cfg.Devsync.Auth.RemotePath = "C:/srv/app"
+	if got, want := remoteHookCommand(cfg, "make", ""), `cmd.exe /C cd /d "C:\srv\app" && make`; got != want {
+		t.Errorf("windows: got %s, want %s", got, want)
+	}
+	cfg.Devsync.Auth.RemotePath = ""
+	if got := remoteHookCommand(cfg, "make", ""); got != "make" {
+		t.Errorf("no remote path: got %s, want make", got)
+	}
+}
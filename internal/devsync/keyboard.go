@@ -52,7 +52,7 @@ func (w *Watcher) handleKeyboardInput() {
 					log.Println("DEBUG: handleKeyboardInput Ctrl+0 — force closing slot", slot)
 					util.Default.ClearLine()
 					util.Default.PrintBlock("🔌 Force closing slot "+strconv.Itoa(slot)+"...", true)
-					w.ptyMgr.CloseSlot(slot)
+					w.endSlot(slot)
 				}
 				one := 1
 				w.Slot = &one
@@ -129,6 +129,35 @@ func (w *Watcher) handleKeyboardInput() {
 				}
 				w.oldState = oldState
 			// case "Q", "q":
+			case "P", "p":
+				w.ToggleUploadsPaused()
+			case "M", "m":
+				util.ResetRaw(w.oldState)
+				w.showMuteMenu()
+				oldState, err := util.NewRaw()
+				if err != nil {
+					w.safePrintln("⚠️  keyboard handler: failed to re-enable raw mode:", err)
+					return
+				}
+				w.oldState = oldState
+			case "T", "t":
+				util.ResetRaw(w.oldState)
+				w.showDetachedSessionsMenu()
+				oldState, err := util.NewRaw()
+				if err != nil {
+					w.safePrintln("⚠️  keyboard handler: failed to re-enable raw mode:", err)
+					return
+				}
+				w.oldState = oldState
+			case "V", "v":
+				util.ResetRaw(w.oldState)
+				w.showSplitMenu()
+				oldState, err := util.NewRaw()
+				if err != nil {
+					w.safePrintln("⚠️  keyboard handler: failed to re-enable raw mode:", err)
+					return
+				}
+				w.oldState = oldState
 			case "S", "s":
 				// _ = util.RestoreGlobal()
 				// w.HandleShowStatsCommand()
@@ -194,7 +223,7 @@ func (w *Watcher) handleAltKey(input string) {
 			log.Println("DEBUG: handleAltKey Alt+0 — force closing slot", slot)
 			util.Default.ClearLine()
 			util.Default.PrintBlock("🔌 Force closing slot "+strconv.Itoa(slot)+"...", true)
-			w.ptyMgr.CloseSlot(slot)
+			w.endSlot(slot)
 		}
 		one := 1
 		w.Slot = &one
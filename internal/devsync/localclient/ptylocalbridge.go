@@ -2,6 +2,7 @@ package localclient
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"make-sync/internal/pty"
 	"os"
@@ -53,6 +54,15 @@ type PTYLocalBridge struct {
 
 	// output tap receives stdout/stderr bytes (err=false for stdout, true for stderr if implemented)
 	outputTap func([]byte, bool)
+	// outputFilter rewrites output before it reaches the terminal (path links)
+	outputFilter func([]byte) []byte
+
+	// background is set by StartInBackground
+	background bool
+
+	// pinned window size (split panes); zero follows the local terminal
+	sizeMu           sync.Mutex
+	pinCols, pinRows int
 }
 
 // cacheOutput adds output data to the cache with FIFO strategy (removes oldest data when full)
@@ -107,6 +117,37 @@ func NewPTYLocalBridge(initialCommand string) (*PTYLocalBridge, error) {
 	}, nil
 }
 
+// SetWindowSize pins the PTY to cols x rows (e.g. a split pane) so it no
+// longer follows the local terminal size. cols or rows <= 0 unpins it and
+// applies the terminal size again.
+func (b *PTYLocalBridge) SetWindowSize(cols, rows int) error {
+	pin := cols > 0 && rows > 0
+	b.sizeMu.Lock()
+	if pin {
+		b.pinCols, b.pinRows = cols, rows
+	} else {
+		b.pinCols, b.pinRows = 0, 0
+	}
+	b.sizeMu.Unlock()
+	if b.localPTY == nil {
+		return fmt.Errorf("local PTY not started")
+	}
+	if !pin {
+		w, h, err := term.GetSize(int(os.Stdin.Fd()))
+		if err != nil {
+			return err
+		}
+		cols, rows = w, h
+	}
+	return b.localPTY.SetSize(rows, cols)
+}
+
+func (b *PTYLocalBridge) sizePinned() bool {
+	b.sizeMu.Lock()
+	defer b.sizeMu.Unlock()
+	return b.pinCols > 0
+}
+
 // PushInput enqueues input bytes into the bridge's input buffer (if present).
 // It returns false if the buffer is not configured or the buffer is full.
 func (b *PTYLocalBridge) PushInput(data []byte) bool {
@@ -154,3 +195,11 @@ func (b *PTYLocalBridge) SetOutputTap(fn func([]byte, bool)) {
 	b.outputTap = fn
 	b.outputMu.Unlock()
 }
+
+// SetOutputFilter registers a filter applied to output shown on the terminal
+// (and cached for Resume). Taps still receive the raw output.
+func (b *PTYLocalBridge) SetOutputFilter(fn func([]byte) []byte) {
+	b.outputMu.Lock()
+	b.outputFilter = fn
+	b.outputMu.Unlock()
+}
@@ -28,16 +28,28 @@ func (b *PTYLocalBridge) StartInteractiveShell() error {
 	return b.startLocalWithCommand(b.initialCommand)
 }
 
+// StartInBackground starts the command paused; Resume attaches it to the
+// terminal and shows the output cached meanwhile.
+func (b *PTYLocalBridge) StartInBackground() error {
+	b.outputMu.Lock()
+	b.outputDisabled = true
+	b.outputMu.Unlock()
+	b.background = true
+	return b.startLocalWithCommand(b.initialCommand)
+}
+
 // startLocalWithCommand starts the provided command in a PTY and bridges IO to the terminal.
 // This is the existing implementation that accepts a shell command string.
 func (b *PTYLocalBridge) startLocalWithCommand(command string) error {
 	// Detect whether stdin/stdout are real terminals. Mirror SSH bridge behavior.
-	util.ResetRaw(b.oldState)
-	oldState, err := util.NewRaw()
-	if err != nil {
-		return fmt.Errorf("failed to enable raw mode: %w", err)
+	if !b.background {
+		util.ResetRaw(b.oldState)
+		oldState, err := util.NewRaw()
+		if err != nil {
+			return fmt.Errorf("failed to enable raw mode: %w", err)
+		}
+		b.oldState = oldState
 	}
-	b.oldState = oldState
 
 	// prepare command
 	cmd := exec.Command("/bin/sh", "-lc", command)
@@ -65,7 +77,9 @@ func (b *PTYLocalBridge) startLocalWithCommand(command string) error {
 	b.inputCancel = inCancel
 
 	b.ProcessPTYReadOutput(outCtx)
-	b.ProcessPTYReadInput(inCtx)
+	if !b.background {
+		b.ProcessPTYReadInput(inCtx)
+	}
 
 	b.localPTY.Write([]byte("\n")) // ensure prompt starts on new line
 
@@ -228,12 +242,17 @@ func (b *PTYLocalBridge) ProcessPTYReadOutput(ctx context.Context) error {
 					b.outputMu.Lock()
 					disabled := b.outputDisabled
 					tap := b.outputTap
+					filter := b.outputFilter
 					b.outputMu.Unlock()
+					shown := buf[:n]
+					if filter != nil {
+						shown = filter(shown)
+					}
 					if !disabled {
-						_, _ = os.Stdout.Write(buf[:n])
+						_, _ = os.Stdout.Write(shown)
 					}
 					// Always cache output as history buffer
-					b.cacheOutput(buf[:n])
+					b.cacheOutput(shown)
 					if tap != nil {
 						// Local PTY only provides a single output stream; mark as stdout (isErr=false)
 						// Invoke regardless of outputDisabled to keep background logging.
@@ -303,7 +322,7 @@ func (b *PTYLocalBridge) Resume() error {
 	b.inputCancel = inCancel
 	b.ProcessPTYReadInput(inCtx)
 
-	if b.localPTY != nil {
+	if b.localPTY != nil && !b.sizePinned() {
 		// try to update size on resume
 		if w, h, err := term.GetSize(int(os.Stdin.Fd())); err == nil {
 			_ = b.localPTY.SetSize(h, w)
@@ -26,15 +26,27 @@ func (b *PTYLocalBridge) StartInteractiveShell() error {
 	return b.StartInteractiveShellWithCommand(b.initialCommand)
 }
 
+// StartInBackground starts the command paused; Resume attaches it to the
+// terminal and shows the output cached meanwhile.
+func (b *PTYLocalBridge) StartInBackground() error {
+	b.outputMu.Lock()
+	b.outputDisabled = true
+	b.outputMu.Unlock()
+	b.background = true
+	return b.StartInteractiveShellWithCommand(b.initialCommand)
+}
+
 // StartInteractiveShellWithCommand starts the provided command in a ConPTY and
 // wires IO to the current process' stdout/stdin.
 func (b *PTYLocalBridge) StartInteractiveShellWithCommand(command string) error {
 
-	oldstate, err := term.MakeRaw(int(os.Stdin.Fd()))
-	if err != nil {
-		return fmt.Errorf("failed to snapshot terminal state: %v", err)
+	if !b.background {
+		oldstate, err := term.MakeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			return fmt.Errorf("failed to snapshot terminal state: %v", err)
+		}
+		b.oldState = oldstate
 	}
-	b.oldState = oldstate
 
 	if command == "" {
 		// default to system shell
@@ -103,7 +115,9 @@ func (b *PTYLocalBridge) StartInteractiveShellWithCommand(command string) error
 
 	b.outPipe = p.OutPipe()
 
-	b.ProcessPTYReadInput(inCtx)
+	if !b.background {
+		b.ProcessPTYReadInput(inCtx)
+	}
 	b.ProcessPTYReadOutput(outCtx)
 
 	// start a small resize watcher: poll terminal size and update PTY when it changes
@@ -117,6 +131,10 @@ func (b *PTYLocalBridge) StartInteractiveShellWithCommand(command string) error
 				if b.localPTY == nil {
 					continue
 				}
+				if b.sizePinned() {
+					prevW, prevH = 0, 0
+					continue
+				}
 				if w, h, err := term.GetSize(int(os.Stdin.Fd())); err == nil {
 					if w != prevW || h != prevH {
 						_ = b.localPTY.SetSize(h, w)
@@ -253,12 +271,17 @@ func (b *PTYLocalBridge) ProcessPTYReadOutput(ctx context.Context) error {
 					b.outputMu.Lock()
 					disabled := b.outputDisabled
 					tap := b.outputTap
+					filter := b.outputFilter
 					b.outputMu.Unlock()
+					shown := buf[:n]
+					if filter != nil {
+						shown = filter(shown)
+					}
 					if !disabled {
-						_, _ = os.Stdout.Write(buf[:n])
+						_, _ = os.Stdout.Write(shown)
 					}
 					// Always cache output as history buffer
-					b.cacheOutput(buf[:n])
+					b.cacheOutput(shown)
 					if tap != nil {
 						// ConPTY exposes a combined stream for stdout/stderr via OutPipe; treat as stdout here.
 						data := make([]byte, n)
@@ -0,0 +1,129 @@
+package devsync
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"make-sync/internal/config"
+	"make-sync/internal/deployagent"
+	"make-sync/internal/sshclient"
+	"make-sync/internal/syncdata"
+	"make-sync/internal/util"
+)
+
+// mappingFor returns the index of the mapping whose local root contains
+// localPath, the mapping itself and the slash-separated path relative to
+// its local root.
+func (w *Watcher) mappingFor(localPath string) (int, config.Mapping, string, bool) {
+	mappings := w.config.PathMappings()
+	i, rel, ok := w.config.PathMap().MatchLocal(localPath)
+	if !ok {
+		return -1, config.Mapping{}, "", false
+	}
+	return i, mappings[i], rel, true
+}
+
+// localToRemote maps a local path to its remote path through the mapping
+// table, using forward slashes.
+func (w *Watcher) localToRemote(localPath string) (string, error) {
+	_, m, rel, ok := w.mappingFor(localPath)
+	if !ok {
+		return "", fmt.Errorf("%s is outside every devsync mapping", localPath)
+	}
+	if rel == "." {
+		return m.RemotePath, nil
+	}
+	return w.joinRemotePath(m.RemotePath, rel), nil
+}
+
+// remoteToLocal maps a remote path reported by the agent back to local.
+func (w *Watcher) remoteToLocal(remotePath string) (string, error) {
+	return w.config.PathMap().RemoteToLocal(remotePath)
+}
+
+// outsideMappings reports whether path lies outside every configured
+// mapping; such paths are not synced at all.
+func (w *Watcher) outsideMappings(path string) bool {
+	if !w.config.HasMappings() {
+		return false
+	}
+	_, _, ok := w.config.PathMap().MatchLocal(path)
+	return !ok
+}
+
+// applyMappings makes the ignores of the current mappings visible to the
+// ignore matcher.
+func (w *Watcher) applyMappings() {
+	syncdata.UseMappingIgnores(w.config)
+	w.ignoresMu.Lock()
+	w.ignoreCache = nil
+	w.ignoresMu.Unlock()
+}
+
+// remoteConfigForMapping builds the config.json of the agent copy living in
+// a mapping's remote root, used for indexing that root.
+func (w *Watcher) remoteConfigForMapping(m config.Mapping) *RemoteAgentConfig {
+	mc := w.config.ForMapping(m)
+	cfg := &RemoteAgentConfig{}
+	cfg.Devsync.Ignores = append(w.collectPreprocessedIgnoresFromRoot(m.LocalPath), m.Ignores...)
+	cfg.Devsync.AgentWatchs = mc.Devsync.AgentWatchs
+	cfg.Devsync.ManualTransfer = mc.Devsync.ManualTransfer
+	cfg.Devsync.WorkingDir = m.RemotePath
+	cfg.Devsync.SizeLimit = mc.Devsync.SizeLimit
+	cfg.Devsync.HeartbeatInterval = mc.Devsync.Heartbeat.IntervalSeconds()
+	return cfg
+}
+
+// deployMappingAgents places an agent copy and its config.json in every
+// mapping's remote root of cfg so each root can be indexed on its own.
+func (w *Watcher) deployMappingAgents(cfg *config.Config, client *sshclient.SSHClient, projectRoot string) {
+	if !cfg.HasMappings() {
+		return
+	}
+	for _, m := range cfg.PathMappings() {
+		deployOpts := deployagent.UnifiedDeployOptions{
+			ProjectRoot:    projectRoot,
+			TargetOS:       cfg.Devsync.OSTarget,
+			Config:         cfg.ForMapping(m),
+			SSHClient:      client,
+			BuildIfMissing: true,
+			UploadAgent:    true,
+			UploadConfig:   true,
+		}
+		if _, err := deployagent.DeployAgentAndConfig(deployOpts); err != nil {
+			w.safePrintf("⚠️  Failed to deploy agent for mapping %s: %v\n", m.Label(), err)
+		}
+	}
+}
+
+// syncMappingConfigs uploads the per-mapping config.json files.
+func (w *Watcher) syncMappingConfigs() error {
+	if !w.config.HasMappings() {
+		return nil
+	}
+	targetOS := w.config.Devsync.OSTarget
+	for _, m := range w.config.PathMappings() {
+		configJSON, err := w.configToJSON(w.remoteConfigForMapping(m))
+		if err != nil {
+			return fmt.Errorf("failed to convert config of mapping %s to JSON: %v", m.Label(), err)
+		}
+		remoteSyncTemp := w.joinRemotePathOS(targetOS, m.RemotePath, ".sync_temp")
+		remoteConfigPath := w.joinRemotePathOS(targetOS, remoteSyncTemp, "config.json")
+		util.Default.Printf("📤 Syncing config of mapping %s to: %s\n", m.Label(), remoteConfigPath)
+		if err := w.uploadConfigToRemote(configJSON, remoteConfigPath); err != nil {
+			return fmt.Errorf("failed to upload config of mapping %s: %v", m.Label(), err)
+		}
+	}
+	return nil
+}
+
+// mappingLocalPrefix is the slash-separated path of m's local root relative
+// to the watch path ("" for the watch path itself).
+func (w *Watcher) mappingLocalPrefix(m config.Mapping) string {
+	rel, err := filepath.Rel(w.watchPath, m.LocalPath)
+	if err != nil || rel == "." {
+		return ""
+	}
+	return strings.TrimSuffix(filepath.ToSlash(rel), "/") + "/"
+}
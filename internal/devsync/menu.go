@@ -5,7 +5,6 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -31,13 +30,23 @@ func (w *Watcher) displayMainMenu() {
 		"R  - Reload configuration",
 		"S  - Show cache stats",
 		"A  - Deploy agent",
+		"P  - Pause/resume uploads",
+		"M  - Mute/unmute a subtree",
+		"T  - Detached remote sessions (reattach/kill)",
+		"V  - Split view (2-4 slots side by side / stacked)",
 		"Alt+1 - This menu",
 		"Alt+2 - New remote session (no menu)  (TBD)",
 		"Alt+3..9 - Command menus (dynamic per-config). Press one to open command picker.",
 		"Alt+B - Background current session",
 		"Alt+0 - Close current session",
-		"> ",
 	}
+	lines = append(lines, w.sessionMenuLines()...)
+	if w.ptyMgr != nil {
+		if slots := w.ptyMgr.BroadcastSlots(); len(slots) > 0 {
+			lines = append(lines, fmt.Sprintf("📡 Broadcasting input to slots %s (Alt+<slot> in one of them stops)", joinSlots(slots)))
+		}
+	}
+	lines = append(lines, "> ")
 	for i := range lines {
 		util.Default.Println(lines[i])
 		util.Default.ClearLine()
@@ -51,30 +60,110 @@ func (w *Watcher) showSlotControlMenu(slot int) string {
 	defer util.Default.Resume()
 	promptMu.Lock()
 	defer promptMu.Unlock()
-	prompt := promptui.Select{
-		Label: fmt.Sprintf("? Slot %d — What would you like to do?", slot),
-		Items: []string{"Continue (stay in slot)", "Exit to main menu"},
-		Size:  2,
-		Templates: &promptui.SelectTemplates{
-			Label:    "{{ . }}",
-			Active:   "▸ {{ . | cyan }}",
-			Inactive: "  {{ . }}",
-			Selected: "Selected: {{ . }}",
-		},
+	for {
+		broadcastItem := "Broadcast input to several slots..."
+		if slots := w.ptyMgr.BroadcastSlots(); len(slots) > 0 {
+			broadcastItem = fmt.Sprintf("Stop broadcast (slots %s)", joinSlots(slots))
+		}
+		linksItem := "Path links: off → link remote paths to local files"
+		if w.ptyMgr.PathLinksOn(slot) {
+			linksItem = "Path links: on → turn off"
+		}
+		items := []string{"Continue (stay in slot)", "Scrollback (search / save output)", broadcastItem, linksItem, "Copy output to clipboard...", "Exit to main menu"}
+		prompt := promptui.Select{
+			Label: fmt.Sprintf("? Slot %d — What would you like to do?", slot),
+			Items: items,
+			Size:  len(items),
+			Templates: &promptui.SelectTemplates{
+				Label:    "{{ . }}",
+				Active:   "▸ {{ . | cyan }}",
+				Inactive: "  {{ . }}",
+				Selected: "Selected: {{ . }}",
+			},
+			HideHelp: true,
+		}
+		i, _, err := prompt.Run()
+		if err != nil {
+			util.Default.Printf("❌ Menu cancelled: %v — continuing\n", err)
+			return "continue"
+		}
+		switch i {
+		case 0:
+			return "continue"
+		case 1:
+			// back to this menu once the viewer closes
+			w.showScrollback(slot)
+		case 2:
+			if len(w.ptyMgr.BroadcastSlots()) > 0 {
+				w.ptyMgr.StopBroadcast()
+				util.Default.MenuPrintf("📡 Broadcast stopped\n")
+				continue
+			}
+			w.showBroadcastMenu(slot)
+		case 3:
+			on := !w.ptyMgr.PathLinksOn(slot)
+			if err := w.ptyMgr.SetPathLinks(slot, on); err != nil {
+				util.Default.MenuPrintf("❌ %v\n", err)
+				continue
+			}
+			if on {
+				util.Default.MenuPrintf("🔗 Path links on\n")
+			} else {
+				util.Default.MenuPrintf("🔗 Path links off\n")
+			}
+		case 4:
+			w.showCopyMenu(slot)
+		case 5:
+			return "exit"
+		default:
+			return "continue"
+		}
+	}
+}
+
+// showMuteMenu lets the user mute a new subtree or unmute an existing one.
+func (w *Watcher) showMuteMenu() {
+	util.Default.Suspend()
+	defer util.Default.Resume()
+	promptMu.Lock()
+	defer promptMu.Unlock()
+
+	muted := w.MutedPaths()
+	items := []string{"Mute a path..."}
+	for _, p := range muted {
+		label := p
+		if rel, err := filepath.Rel(w.watchPath, p); err == nil {
+			label = rel
+		}
+		items = append(items, "Unmute "+label)
+	}
+	items = append(items, "Back")
+
+	sel := promptui.Select{
+		Label:    "🔇 Muted subtrees",
+		Items:    items,
+		Size:     10,
 		HideHelp: true,
 	}
-	i, _, err := prompt.Run()
-	if err != nil {
-		util.Default.Printf("❌ Menu cancelled: %v — continuing\n", err)
-		return "continue"
+	i, _, err := sel.Run()
+	if err != nil || i == len(items)-1 {
+		return
+	}
+
+	if i > 0 {
+		if _, err := w.UnmutePath(muted[i-1]); err != nil {
+			util.Default.Printf("❌ %v\n", err)
+		}
+		return
+	}
+
+	prompt := promptui.Prompt{Label: "Path to mute (relative to " + w.watchPath + ")"}
+	p, err := prompt.Run()
+	if err != nil || strings.TrimSpace(p) == "" {
+		return
 	}
-	switch i {
-	case 0:
-		return "continue"
-	case 1:
-		return "exit"
-	default:
-		return "continue"
+	if _, err := w.MutePath(p); err != nil {
+		util.Default.Printf("❌ %v\n", err)
 	}
 }
 
@@ -130,7 +219,7 @@ menuLoop:
 					// re-focus the same slot without closing
 					continue
 				case "exit":
-					w.ptyMgr.CloseSlot(*slot)
+					w.endSlot(*slot)
 					w.displayMainMenu()
 					break menuLoop
 				}
@@ -139,6 +228,19 @@ menuLoop:
 			continue
 		}
 
+		// devsync.sessions: a configured slot opens its session instead of the picker
+		if cfg != nil && w.ptyMgr != nil {
+			if spec, ok := cfg.SessionForSlot(*slot); ok {
+				util.Default.Printf("➕ Opening session %q in slot %d ...\n", spec.Label(), *slot)
+				if err := w.openConfiguredSession(spec, false); err != nil {
+					util.Default.Printf("⚠️  Failed to open session %q: %v - showing command menu\n", spec.Label(), err)
+				} else {
+					// the next iteration focuses the now open slot
+					continue
+				}
+			}
+		}
+
 		var items []string
 		// If in local submenu mode, show local commands
 		if w != nil && w.isLocal {
@@ -240,27 +342,7 @@ menuLoop:
 				}
 				// Support optional local log capture marker: "command >>> file"
 				baseCmd, logFile := parseCommandLogSpec(result)
-				// Choose local initial command template based on HOST OS
-				// (local shell should reflect the OS running this watcher)
-				isWindowsTarget := runtime.GOOS == "windows"
-				var initialCmd string
-				if isWindowsTarget {
-					// On Windows host: if baseCmd provided, execute it and keep shell open; else open shell only
-					if strings.TrimSpace(baseCmd) == "" {
-						initialCmd = ""
-					} else {
-						// Escape percent and caret to survive cmd parsing
-						esc := func(s string) string {
-							s = strings.ReplaceAll(s, "%", "%%")
-							s = strings.ReplaceAll(s, "^", "^^")
-							return s
-						}
-						cmdPart := esc(baseCmd)
-						initialCmd = fmt.Sprintf("cd %s & %s & cmd", localPath, cmdPart)
-					}
-				} else {
-					initialCmd = fmt.Sprintf("cd %s && bash -c %s ; exec bash", shellEscape(localPath), shellEscape(baseCmd))
-				}
+				initialCmd := localSlotCommand(localPath, baseCmd, nil)
 				isExist := false
 				if !w.ptyMgr.HasSlot(*slot) {
 					util.Default.ClearLine()
@@ -271,16 +353,20 @@ menuLoop:
 						continue
 					}
 					// If logging requested, attach an output tap to this slot
-					if logFile != "" {
+					if logFile != "" && !isCastFile(logFile) {
 						tap := makeFileLogTap(cfg, logFile)
 						if tap != nil {
 							_ = w.ptyMgr.SetOutputTapForSlot(*slot, tap)
 						}
 					}
+					w.startSlotRecording(*slot, logFile)
 				} else {
 					isExist = true
-					// Update/attach logging tap on existing local slot as well (best-effort)
-					if logFile != "" {
+					// Update/attach logging tap on existing local slot as well (best-effort);
+					// a .cast marker starts a new recording of the slot
+					if isCastFile(logFile) {
+						w.startSlotRecording(*slot, logFile)
+					} else if logFile != "" {
 						tap := makeFileLogTap(cfg, logFile)
 						if tap != nil {
 							_ = w.ptyMgr.SetOutputTapForSlot(*slot, tap)
@@ -289,7 +375,7 @@ menuLoop:
 				}
 
 				util.Default.Suspend()
-				util.Default.PrintBlock(fmt.Sprintf("🔗 Attaching to slot %d ...", *slot), true)
+				util.Default.PrintBlock(fmt.Sprintf("🔗 Attaching to %s ...", w.slotLabel(*slot)), true)
 				log.Println("DEBUG: attaching to local slot", *slot, "isExist=", isExist)
 				if err := w.ptyMgr.Focus(*slot, isExist, callback); err != nil {
 					util.Default.Printf("⚠️  Failed to focus local slot %d: %v\n", *slot, err)
@@ -317,86 +403,52 @@ menuLoop:
 			}
 			// Parse optional local log capture marker in the selected command
 			baseCmd, logFile := parseCommandLogSpec(result)
-			// choose remote command template based on target OS
 			targetOS := ""
 			if cfg != nil {
 				targetOS = strings.ToLower(cfg.Devsync.OSTarget)
 			}
-			isWindowsTarget := strings.Contains(targetOS, "windows")
-			var initialCmd string
-			if isWindowsTarget {
-				// Normalize remotePath for Windows: convert '/c/Users' -> 'C:\Users' and
-				// replace forward slashes with backslashes so cmd.exe accepts it.
-				winPath := remotePath
-				if strings.HasPrefix(winPath, "/") && len(winPath) > 2 && winPath[2] == '/' {
-					// pattern like /c/Users -> drive letter at pos 1
-					d := strings.ToUpper(string(winPath[1]))
-					rest := winPath[2:]
-					rest = strings.ReplaceAll(rest, "/", "\\\\")
-					winPath = d + ":" + rest
-				} else {
-					winPath = strings.ReplaceAll(winPath, "/", "\\\\")
-				}
-
-				// Use cmd.exe on remote Windows targets. If the selected result came
-				// from the user's config (cfg.Devsync.Script.Remote.Commands), do NOT
-				// apply any automatic mapping or escaping — respect the exact command
-				// provided by the configuration. Only apply mapping for dynamic or
-				// fallback menu entries.
-				inConfig := false
-				if cfg != nil && cfg.Devsync.Script.Remote.Commands != nil {
-					for _, c := range cfg.Devsync.Script.Remote.Commands {
-						if c == result {
-							inConfig = true
-							break
-						}
+			// Commands from cfg.Devsync.Script.Remote.Commands are passed to
+			// Windows targets verbatim (see remoteSlotCommand)
+			inConfig := false
+			if cfg != nil && cfg.Devsync.Script.Remote.Commands != nil {
+				for _, c := range cfg.Devsync.Script.Remote.Commands {
+					if c == result {
+						inConfig = true
+						break
 					}
 				}
-				// Escape percent signs and carets so the command survives cmd parsing.
-				escCmd := func(s string) string {
-					s = strings.ReplaceAll(s, "%", "%%")
-					s = strings.ReplaceAll(s, "^", "^^")
-					return s
-				}
-				// Only apply escaping for non-config items; do not rewrite or map
-				// commands like `pwd`/`ls` to Windows equivalents — respect user
-				// config and dynamic entries verbatim except for escaping percent
-				// and caret characters which would be consumed by cmd.exe parsing.
-				cmdPart := baseCmd
-				if !inConfig {
-					cmdPart = escCmd(baseCmd)
-				}
-				// Run the user's command; assume the directory already exists and
-				// just change directory into it before running the command.
-				// body := fmt.Sprintf("cd /d \"%s\" & %s", winPath, cmdPart)
-				body := fmt.Sprintf("cd /d \"%s\" & %s", winPath, cmdPart)
-				// Do not add extra outer quoting or backslash-escaped quotes here;
-				// pass the body (which already contains quoted paths) directly to cmd.exe
-				initialCmd = fmt.Sprintf("cmd.exe /K %s", body)
-			} else {
-				initialCmd = fmt.Sprintf("mkdir -p %s || true && cd %s && bash -c %s ; exec bash",
-					shellEscape(remotePath), shellEscape(remotePath), shellEscape(baseCmd))
 			}
+			initialCmd := remoteSlotCommand(targetOS, remotePath, baseCmd, nil, inConfig)
 			isExist := false
 			if !w.ptyMgr.HasSlot(*slot) {
 				util.Default.Println("➕ Creating new slot", *slot, "...")
+				// devsync.persistent_sessions: run under tmux/screen, reattaching a detached session of this slot
+				var detached *detachedSession
+				initialCmd, detached = w.persistentCommand(*slot, initialCmd)
 				// Debug: print before opening remote slot to inspect values seen at runtime
 				log.Printf("DEBUG: targetOS=%q remotePath=%q initialCmd=%q\n", targetOS, remotePath, initialCmd)
 				if err := w.ptyMgr.OpenRemoteSlot(*slot, initialCmd); err != nil {
 					util.Default.Printf("⚠️  Failed to open slot %d: %v - falling back to single-run\n", *slot, err)
 					continue
 				}
+				if detached != nil {
+					w.restorePersistentScrollback(*detached)
+				}
 				// If logging requested, attach an output tap to this slot
-				if logFile != "" {
+				if logFile != "" && !isCastFile(logFile) {
 					tap := makeFileLogTap(cfg, logFile)
 					if tap != nil {
 						_ = w.ptyMgr.SetOutputTapForSlot(*slot, tap)
 					}
 				}
+				w.startSlotRecording(*slot, logFile)
 			} else {
 				isExist = true
-				// Update/attach logging tap on existing slot as well (best-effort)
-				if logFile != "" {
+				// Update/attach logging tap on existing slot as well (best-effort);
+				// a .cast marker starts a new recording of the slot
+				if isCastFile(logFile) {
+					w.startSlotRecording(*slot, logFile)
+				} else if logFile != "" {
 					tap := makeFileLogTap(cfg, logFile)
 					if tap != nil {
 						_ = w.ptyMgr.SetOutputTapForSlot(*slot, tap)
@@ -406,7 +458,7 @@ menuLoop:
 
 			util.Default.Suspend()
 			log.Println("DEBUG: attaching to slot", *slot, "isExist=", isExist)
-			util.Default.PrintBlock(fmt.Sprintf("🔗 Attaching to slot %d ...", *slot), true)
+			util.Default.PrintBlock(fmt.Sprintf("🔗 Attaching to %s ...", w.slotLabel(*slot)), true)
 			if err := w.ptyMgr.Focus(*slot, isExist, callback); err != nil {
 				util.Default.Printf("⚠️  Failed to focus slot %d: %v\n", *slot, err)
 			}
@@ -566,6 +618,7 @@ func (w *Watcher) enterShellNonCommand() {
 			util.Default.Resume()
 			return
 		}
+		w.startSlotRecording(slot, "")
 	} else {
 		isExist = true
 	}
@@ -0,0 +1,101 @@
+package devsync
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"make-sync/internal/devsync/localclient"
+	"make-sync/internal/pathlink"
+	"make-sync/internal/sshclient"
+	"make-sync/internal/util"
+)
+
+// pathLinkFilter links remote paths under every mapping root (auth.remotePath
+// without mappings) to their local files. Deeper roots go first; the later
+// linkers leave paths that are linked already alone.
+func (w *Watcher) pathLinkFilter() (func([]byte) []byte, error) {
+	cfg := w.config
+	if cfg == nil {
+		return nil, fmt.Errorf("no config loaded")
+	}
+	mappings := cfg.PathMappings()
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return len(mappings[i].RemotePath) > len(mappings[j].RemotePath)
+	})
+	var linkers []*pathlink.Linker
+	for _, m := range mappings {
+		if m.RemotePath == "" {
+			continue
+		}
+		local, err := filepath.Abs(m.LocalPath)
+		if err != nil {
+			local = m.LocalPath
+		}
+		linkers = append(linkers, pathlink.New(m.RemotePath, local, cfg.Devsync.PathLinks.URLTemplate()))
+	}
+	if len(linkers) == 0 {
+		return nil, fmt.Errorf("auth.remotePath is not set")
+	}
+	return func(b []byte) []byte {
+		for _, l := range linkers {
+			b = l.Filter(b)
+		}
+		return b
+	}, nil
+}
+
+// SetPathLinks turns path links on or off for the output of slot.
+func (m *PTYManager) SetPathLinks(slot int, on bool) error {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil || s.Bridge == nil {
+		return fmt.Errorf("no session in slot %d", slot)
+	}
+	var filter func([]byte) []byte
+	if on {
+		f, err := m.w.pathLinkFilter()
+		if err != nil {
+			return err
+		}
+		filter = f
+	}
+	switch b := s.Bridge.(type) {
+	case *sshclient.PTYSSHBridge:
+		b.SetOutputFilter(filter)
+	case *localclient.PTYLocalBridge:
+		b.SetOutputFilter(filter)
+	default:
+		return fmt.Errorf("bridge for slot %d does not support output filters", slot)
+	}
+	s.tapMu.Lock()
+	s.pathLinks = on
+	s.tapMu.Unlock()
+	return nil
+}
+
+// PathLinksOn reports whether path links are on for slot.
+func (m *PTYManager) PathLinksOn(slot int) bool {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil {
+		return false
+	}
+	s.tapMu.Lock()
+	defer s.tapMu.Unlock()
+	return s.pathLinks
+}
+
+// startPathLinks enables path links on a new slot when devsync.path_links
+// (or the slot's devsync.sessions entry) asks for it.
+func (m *PTYManager) startPathLinks(s *PTYSession) {
+	cfg := m.w.config
+	if cfg == nil || !cfg.PathLinksEnabled(s.Slot) {
+		return
+	}
+	if err := m.SetPathLinks(s.Slot, true); err != nil {
+		util.Default.Printf("⚠️  Path links unavailable for slot %d: %v\n", s.Slot, err)
+	}
+}
@@ -0,0 +1,234 @@
+package devsync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PauseUploads holds debounced events instead of uploading them. It reports
+// false when uploads were already paused.
+func (w *Watcher) PauseUploads() bool {
+	if !w.uploadsPaused.CompareAndSwap(false, true) {
+		return false
+	}
+	w.safeStatusln("⏸️  Uploads paused — events are buffered until resume")
+	w.emitControlEvent("paused", "", "")
+	return true
+}
+
+// ResumeUploads releases the events held while paused as a catch-up sync
+// and prints a summary of what it contains. It reports false when uploads
+// were not paused.
+func (w *Watcher) ResumeUploads() bool {
+	if !w.uploadsPaused.CompareAndSwap(true, false) {
+		return false
+	}
+
+	w.debounceMu.Lock()
+	held := make([]FileEvent, 0, len(w.debounceMap))
+	for key, ent := range w.debounceMap {
+		ent.timer.Stop()
+		delete(w.debounceMap, key)
+		if w.isMuted(ent.evt.Path) {
+			continue
+		}
+		held = append(held, ent.evt)
+	}
+	w.debounceMu.Unlock()
+
+	summary := summarizeEvents(held)
+	w.safeStatusln("▶️  Uploads resumed — catch-up sync: %s", summary)
+	// A pause easily holds more events than the queue has room for; feed
+	// them in the background as the queue drains instead of dropping any.
+	go func() {
+		for _, evt := range held {
+			select {
+			case w.eventQueue <- evt:
+			case <-w.ctx.Done():
+				return
+			}
+		}
+	}()
+	w.emitControlEvent("resumed", "", summary)
+	return true
+}
+
+// ToggleUploadsPaused pauses or resumes uploads (menu key P).
+func (w *Watcher) ToggleUploadsPaused() {
+	if !w.PauseUploads() {
+		w.ResumeUploads()
+	}
+}
+
+// summarizeEvents renders a short count of held events by type.
+func summarizeEvents(evts []FileEvent) string {
+	if len(evts) == 0 {
+		return "nothing to sync"
+	}
+	var changed, removed, renamed int
+	for _, e := range evts {
+		switch e.EventType {
+		case EventRemove:
+			removed++
+		case EventRename:
+			renamed++
+		default:
+			changed++
+		}
+	}
+	return fmt.Sprintf("%d changed, %d removed, %d renamed", changed, removed, renamed)
+}
+
+// resolveLocalPath maps p (absolute, or relative to the watch path) to a
+// cleaned absolute path inside the watch path.
+func (w *Watcher) resolveLocalPath(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return "", fmt.Errorf("empty path")
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(w.watchPath, p)
+	}
+	p = filepath.Clean(p)
+	if rel, err := filepath.Rel(w.watchPath, p); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the watch path", p)
+	}
+	return p, nil
+}
+
+// MutePath ignores every event at or below p until it is unmuted. p may be
+// relative to the watch path.
+func (w *Watcher) MutePath(p string) (string, error) {
+	abs, err := w.resolveLocalPath(p)
+	if err != nil {
+		return "", err
+	}
+	w.mutedMu.Lock()
+	if w.mutedPaths == nil {
+		w.mutedPaths = make(map[string]struct{})
+	}
+	w.mutedPaths[abs] = struct{}{}
+	w.mutedMu.Unlock()
+
+	// Drop held or pending events that are now muted
+	w.debounceMu.Lock()
+	for key, ent := range w.debounceMap {
+		if pathUnder(ent.evt.Path, abs) {
+			ent.timer.Stop()
+			delete(w.debounceMap, key)
+		}
+	}
+	w.debounceMu.Unlock()
+	w.compactEventQueue(abs)
+
+	w.safeStatusln("🔇 Muted %s", abs)
+	w.emitControlEvent("muted", abs, "")
+	return abs, nil
+}
+
+// UnmutePath stops ignoring p and runs a catch-up sync for the subtree in
+// the background.
+func (w *Watcher) UnmutePath(p string) (string, error) {
+	abs, err := w.resolveLocalPath(p)
+	if err != nil {
+		return "", err
+	}
+	w.mutedMu.Lock()
+	_, ok := w.mutedPaths[abs]
+	delete(w.mutedPaths, abs)
+	w.mutedMu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("%s is not muted", abs)
+	}
+
+	w.safeStatusln("🔊 Unmuted %s", abs)
+	w.emitControlEvent("unmuted", abs, "")
+	go w.catchUpSubtree(abs)
+	return abs, nil
+}
+
+// MutedPaths returns the muted subtrees, sorted.
+func (w *Watcher) MutedPaths() []string {
+	w.mutedMu.RLock()
+	defer w.mutedMu.RUnlock()
+	out := make([]string, 0, len(w.mutedPaths))
+	for p := range w.mutedPaths {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// isMuted reports whether path lies in a muted subtree.
+func (w *Watcher) isMuted(path string) bool {
+	if path == "" {
+		return false
+	}
+	w.mutedMu.RLock()
+	defer w.mutedMu.RUnlock()
+	for m := range w.mutedPaths {
+		if pathUnder(path, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathUnder reports whether path equals dir or lies below it.
+func pathUnder(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// catchUpSubtree schedules uploads for files under dir that changed while
+// they were muted (compared with the FileCache) and deletes remote copies of
+// cached files that disappeared, as far as trigger permissions allow.
+func (w *Watcher) catchUpSubtree(dir string) {
+	if w.fileCache == nil {
+		w.safePrintf("⚠️  No file cache available, skipping catch-up for %s\n", dir)
+		return
+	}
+
+	var evts []FileEvent
+	_ = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if w.shouldIgnore(p) || w.isMuted(p) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if changed, cerr := w.fileCache.ShouldSyncFile(p); cerr == nil && changed {
+			evType := EventWrite
+			if _, cached := w.fileCache.GetHash(p); !cached {
+				evType = EventCreate
+			}
+			if w.checkEventAllowed(evType, p, false) {
+				evts = append(evts, FileEvent{Path: p, EventType: evType})
+			}
+		}
+		return nil
+	})
+
+	if cached, err := w.fileCache.ListFilesUnder(dir); err == nil {
+		for _, p := range cached {
+			if _, serr := os.Stat(p); os.IsNotExist(serr) && !w.isMuted(p) && w.checkEventAllowed(EventRemove, p, false) {
+				evts = append(evts, FileEvent{Path: p, EventType: EventRemove})
+			}
+		}
+	}
+
+	summary := summarizeEvents(evts)
+	w.safeStatusln("🔄 Catch-up for %s: %s", dir, summary)
+	w.emitControlEvent("catch_up", dir, summary)
+	for _, evt := range evts {
+		w.syncFileViaSSH(evt)
+	}
+}
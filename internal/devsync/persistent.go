@@ -0,0 +1,319 @@
+package devsync
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"make-sync/internal/config"
+	"make-sync/internal/util"
+
+	"github.com/manifoldco/promptui"
+)
+
+// detachedSession is a persistent remote slot still running under tmux or
+// screen without an open slot in this make-sync.
+type detachedSession struct {
+	Slot int
+	Name string
+	Mux  string
+}
+
+func (d detachedSession) label() string {
+	return fmt.Sprintf("slot %d — %s (%s)", d.Slot, d.Name, d.Mux)
+}
+
+var sessionNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// persistentEnabled reports whether remote slots run under a multiplexer.
+func (w *Watcher) persistentEnabled() bool {
+	cfg := w.config
+	return cfg != nil && cfg.Devsync.PersistentSessions.Enabled &&
+		!w.sideIsWindows(false) && w.sshClient != nil
+}
+
+// persistentPrefix returns the multiplexer session name prefix of this
+// project. The agent name from .sync_temp/config.json keeps it unique per
+// local checkout.
+func (w *Watcher) persistentPrefix() string {
+	id := ""
+	if lc, err := config.LoadLocalConfig(); err == nil && lc != nil {
+		id = lc.Devsync.AgentName
+	}
+	if id == "" && w.config != nil {
+		id = w.config.Devsync.Auth.RemotePath
+		if i := strings.LastIndex(strings.TrimRight(id, "/"), "/"); i >= 0 {
+			id = strings.TrimRight(id, "/")[i+1:]
+		}
+	}
+	return "make-sync-" + sessionNameUnsafe.ReplaceAllString(id, "_") + "-"
+}
+
+func (w *Watcher) persistentName(slot int) string {
+	return w.persistentPrefix() + strconv.Itoa(slot)
+}
+
+// multiplexer returns tmux or screen, whichever the config asks for and the
+// remote has; the result is cached for the session.
+func (w *Watcher) multiplexer() (string, error) {
+	w.persistentMu.Lock()
+	defer w.persistentMu.Unlock()
+	if w.persistentMux != "" {
+		return w.persistentMux, nil
+	}
+	want := w.config.Devsync.PersistentSessions.MultiplexerName()
+	candidates := []string{want}
+	if want == config.MultiplexerAuto {
+		candidates = []string{config.MultiplexerTmux, config.MultiplexerScreen}
+	}
+	for _, c := range candidates {
+		out, _ := w.sshClient.RunCommandWithOutput(fmt.Sprintf("command -v %s >/dev/null 2>&1 && echo ok || true", c))
+		if strings.TrimSpace(out) == "ok" {
+			w.persistentMux = c
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found on the remote", strings.Join(candidates, " or "))
+}
+
+// persistentCommand wraps the remote command of slot in a multiplexer
+// session. When a detached session of the slot already exists it is
+// reattached instead and returned. Without a usable multiplexer the command
+// is returned unchanged.
+func (w *Watcher) persistentCommand(slot int, remoteCmd string) (string, *detachedSession) {
+	if !w.persistentEnabled() {
+		return remoteCmd, nil
+	}
+	mux, err := w.multiplexer()
+	if err != nil {
+		util.Default.Printf("⚠️  Persistent sessions unavailable (%v) — slot %d will not survive restarts\n", err, slot)
+		return remoteCmd, nil
+	}
+	name := w.persistentName(slot)
+	var existing *detachedSession
+	if sessions, err := w.listDetachedSessions(); err == nil {
+		for i := range sessions {
+			if sessions[i].Slot == slot {
+				existing = &sessions[i]
+				util.Default.Printf("♻️  Reattaching detached session %s (the selected command is not run)\n", name)
+			}
+		}
+	}
+	return attachCommand(mux, name, remoteCmd), existing
+}
+
+// attachCommand creates session name running remoteCmd, or attaches to it
+// when it already exists.
+func attachCommand(mux, name, remoteCmd string) string {
+	// The SSH bridge splits "... bash -c CMD ; exec bash" into a shell and
+	// typed input; inside the multiplexer the command must run as a whole.
+	remoteCmd = strings.ReplaceAll(remoteCmd, " ; exec bash", "; exec bash")
+	if mux == config.MultiplexerScreen {
+		return fmt.Sprintf("screen -D -R -S %s bash -c %s", name, shellEscape(remoteCmd))
+	}
+	return fmt.Sprintf("tmux new-session -A -s %s %s", name, shellEscape(remoteCmd))
+}
+
+// startPersistentDetached starts the multiplexer session of slot in the
+// background so its command runs before anyone attaches. cmd is the command
+// built by persistentCommand.
+func (w *Watcher) startPersistentDetached(slot int, cmd string) {
+	if !w.persistentEnabled() {
+		return
+	}
+	// "tmux new-session -A" / "screen -D -R" become their detached forms
+	detached := strings.Replace(cmd, "tmux new-session -A ", "tmux new-session -d ", 1)
+	detached = strings.Replace(detached, "screen -D -R ", "screen -d -m ", 1)
+	if detached == cmd {
+		return
+	}
+	if err := w.sshClient.RunCommand(detached); err != nil {
+		util.Default.Printf("⚠️  Failed to start slot %d in the background: %v\n", slot, err)
+	}
+}
+
+// listDetachedSessions returns the persistent sessions of this project that
+// run on the remote but have no open slot.
+func (w *Watcher) listDetachedSessions() ([]detachedSession, error) {
+	if !w.persistentEnabled() {
+		return nil, nil
+	}
+	mux, err := w.multiplexer()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if mux == config.MultiplexerScreen {
+		// lines look like "\t12345.name\t(Detached)"
+		out, _ := w.sshClient.RunCommandWithOutput("screen -ls 2>/dev/null || true")
+		for _, line := range strings.Split(out, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			if i := strings.Index(fields[0], "."); i > 0 {
+				names = append(names, fields[0][i+1:])
+			}
+		}
+	} else {
+		out, _ := w.sshClient.RunCommandWithOutput("tmux list-sessions -F '#{session_name}' 2>/dev/null || true")
+		names = strings.Fields(out)
+	}
+
+	prefix := w.persistentPrefix()
+	var res []detachedSession
+	for _, n := range names {
+		if !strings.HasPrefix(n, prefix) {
+			continue
+		}
+		slot, err := strconv.Atoi(strings.TrimPrefix(n, prefix))
+		if err != nil || slot < 3 || slot > 9 {
+			continue
+		}
+		if w.ptyMgr != nil && w.ptyMgr.HasSlot(slot) {
+			continue
+		}
+		res = append(res, detachedSession{Slot: slot, Name: n, Mux: mux})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Slot < res[j].Slot })
+	return res, nil
+}
+
+// capturePersistentScrollback returns the history of a multiplexer session.
+func (w *Watcher) capturePersistentScrollback(d detachedSession) string {
+	var cmd string
+	if d.Mux == config.MultiplexerScreen {
+		tmp := "/tmp/" + d.Name + ".hardcopy"
+		cmd = fmt.Sprintf("screen -S %s -X hardcopy -h %s >/dev/null 2>&1; sleep 0.3; cat %s 2>/dev/null; rm -f %s", d.Name, tmp, tmp, tmp)
+	} else {
+		cmd = fmt.Sprintf("tmux capture-pane -p -J -S - -t %s 2>/dev/null || true", d.Name)
+	}
+	out, _ := w.sshClient.RunCommandWithOutput(cmd)
+	return strings.TrimRight(out, "\n")
+}
+
+// restorePersistentScrollback seeds the scrollback of a reattached slot with
+// the history kept by the multiplexer.
+func (w *Watcher) restorePersistentScrollback(d detachedSession) {
+	if history := w.capturePersistentScrollback(d); history != "" {
+		w.ptyMgr.SeedScrollback(d.Slot, history+"\n")
+	}
+}
+
+// reattachSession opens slot d.Slot attached to the detached session. The
+// slot is started the next time it is focused (Alt+N).
+func (w *Watcher) reattachSession(d detachedSession) error {
+	if w.ptyMgr == nil {
+		return fmt.Errorf("PTY manager not initialized")
+	}
+	if w.ptyMgr.HasSlot(d.Slot) {
+		return fmt.Errorf("slot %d is already open", d.Slot)
+	}
+	if err := w.ptyMgr.OpenRemoteSlot(d.Slot, attachCommand(d.Mux, d.Name, "exec bash")); err != nil {
+		return err
+	}
+	w.restorePersistentScrollback(d)
+	return nil
+}
+
+// killPersistentSession ends the multiplexer session of slot, used when the
+// user closes a slot on purpose.
+func (w *Watcher) killPersistentSession(slot int) {
+	if slot < 3 || slot > 9 || !w.persistentEnabled() {
+		return
+	}
+	mux, err := w.multiplexer()
+	if err != nil {
+		return
+	}
+	name := w.persistentName(slot)
+	cmd := fmt.Sprintf("tmux kill-session -t %s 2>/dev/null || true", name)
+	if mux == config.MultiplexerScreen {
+		cmd = fmt.Sprintf("screen -S %s -X quit >/dev/null 2>&1 || true", name)
+	}
+	w.sshClient.RunCommand(cmd)
+}
+
+// endSlot closes slot and, for persistent slots, ends its remote session.
+func (w *Watcher) endSlot(slot int) {
+	w.killPersistentSession(slot)
+	w.ptyMgr.CloseSlot(slot)
+}
+
+// reportDetachedSessions prints the sessions left by a previous run.
+func (w *Watcher) reportDetachedSessions() {
+	sessions, err := w.listDetachedSessions()
+	if err != nil {
+		util.Default.Printf("⚠️  Persistent sessions: %v\n", err)
+		return
+	}
+	if len(sessions) == 0 {
+		return
+	}
+	slots := make([]string, len(sessions))
+	for i, d := range sessions {
+		slots[i] = strconv.Itoa(d.Slot)
+	}
+	util.Default.Printf("♻️  %d detached remote session(s) from a previous run (slots %s) — press T to reattach\n",
+		len(sessions), strings.Join(slots, ", "))
+}
+
+// showDetachedSessionsMenu lists detached sessions and reattaches or kills
+// the chosen ones.
+func (w *Watcher) showDetachedSessionsMenu() {
+	util.Default.Suspend()
+	defer util.Default.Resume()
+	promptMu.Lock()
+	defer promptMu.Unlock()
+
+	if !w.persistentEnabled() {
+		util.Default.Println("ℹ️  Persistent sessions are disabled (devsync.persistent_sessions.enabled)")
+		return
+	}
+	sessions, err := w.listDetachedSessions()
+	if err != nil {
+		util.Default.Printf("❌ %v\n", err)
+		return
+	}
+	if len(sessions) == 0 {
+		util.Default.Println("ℹ️  No detached remote sessions")
+		return
+	}
+
+	items := []string{"Reattach all"}
+	for _, d := range sessions {
+		items = append(items, "Reattach "+d.label(), "Kill "+d.label())
+	}
+	items = append(items, "Back")
+	sel := promptui.Select{
+		Label:    "♻️  Detached remote sessions",
+		Items:    items,
+		Size:     10,
+		HideHelp: true,
+	}
+	i, _, err := sel.Run()
+	if err != nil || i == len(items)-1 {
+		return
+	}
+
+	chosen := sessions
+	kill := false
+	if i > 0 {
+		chosen = sessions[(i-1)/2 : (i-1)/2+1]
+		kill = (i-1)%2 == 1
+	}
+	for _, d := range chosen {
+		if kill {
+			w.killPersistentSession(d.Slot)
+			util.Default.Printf("🗑️  Killed %s\n", d.Name)
+			continue
+		}
+		if err := w.reattachSession(d); err != nil {
+			util.Default.Printf("❌ Slot %d: %v\n", d.Slot, err)
+			continue
+		}
+		util.Default.Printf("✅ Slot %d reattached — press Alt+%d to open it\n", d.Slot, d.Slot)
+	}
+}
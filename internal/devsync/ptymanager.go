@@ -3,8 +3,8 @@ package devsync
 import (
 	"fmt"
 	"log"
-	"make-sync/internal/devsync/localclient"
-	"make-sync/internal/sshclient"
+	"make-sync/internal/cast"
+	"make-sync/internal/scrollback"
 	"make-sync/internal/util"
 	"os"
 	"strconv"
@@ -17,8 +17,25 @@ import (
 type PTYSession struct {
 	Slot    int
 	Cmd     string
+	Name    string // devsync.sessions name, if any
 	Bridge  Bridge
 	created time.Time
+	// started is set once the bridge's shell has been started by Focus or
+	// StartInBackground (auto_start); reattached sessions start on first focus
+	started bool
+
+	// tapMu guards the output consumers combined into the bridge tap
+	tapMu       sync.Mutex
+	logTap      func([]byte, bool)
+	recorder    *cast.Recorder
+	recordInput bool
+	scroll      *scrollback.Buffer
+	pane        func([]byte)    // split view pane, see split.go
+	triggers    *outputTriggers // devsync.sessions triggers, see triggers.go
+	supervisor  *slotSupervisor // devsync.sessions restart policy, see supervise.go
+	pathLinks   bool            // remote paths linked to local files, see pathlinks.go
+	// pinned PTY size while shown in a split pane; zero follows the terminal
+	winCols, winRows int
 }
 
 // PTYManager manages multiple persistent PTY sessions (slots 3..9)
@@ -37,6 +54,10 @@ type PTYManager struct {
 	controlMenuMu        sync.Mutex
 	activeChansClosed    bool // true when Pendingchan+routerStop already closed for current active session
 	activeChansClosedMu  sync.Mutex
+
+	// broadcastMu guards broadcast, the slots keyboard input is mirrored to
+	broadcastMu sync.Mutex
+	broadcast   map[int]bool
 }
 
 // setPending sets the Pendingchan under lock
@@ -104,6 +125,34 @@ func (m *PTYManager) OpenRemoteSlot(slot int, remoteCmd string) error {
 	m.mu.Lock()
 	m.sessions[slot] = s
 	m.mu.Unlock()
+	m.startScrollback(s)
+	m.startPathLinks(s)
+	return nil
+}
+
+// StartInBackground starts the command of slot without attaching it, so its
+// output reaches the scrollback, triggers and restart policy right away. What
+// it prints is shown when the slot is focused.
+func (m *PTYManager) StartInBackground(slot int) error {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil {
+		return fmt.Errorf("no session in slot %d", slot)
+	}
+	if s.started {
+		return nil
+	}
+	s.started = true
+	s.Bridge.SetOnExitListener(func() {
+		log.Println("StartInBackground: slot", slot, "exited")
+		m.CloseSlot(slot)
+	})
+	go func() {
+		if err := s.Bridge.StartInBackground(); err != nil {
+			util.Default.Printf("❌ Failed to start slot %d: %v\n", slot, err)
+		}
+	}()
 	return nil
 }
 
@@ -121,6 +170,10 @@ func (m *PTYManager) Focus(slot int, isExist bool, callback func(slotNew int)) e
 
 	m.bridgeActive = s.Bridge
 	m.bridgeActiveSlot = slot // simpan slot aktif
+	if !s.started {
+		isExist = false
+		s.started = true
+	}
 
 	util.Default.Resume()
 
@@ -132,6 +185,9 @@ func (m *PTYManager) Focus(slot int, isExist bool, callback func(slotNew int)) e
 	// prepare pending channel
 	ch := make(chan string, 1)
 	m.setPending(ch)
+	if m.IsBroadcasting(slot) {
+		util.Default.PrintBlock(m.broadcastIndicator(slot), true)
+	}
 	if !isExist {
 		// m.safeSend("start")
 		ch <- "start"
@@ -150,6 +206,8 @@ func (m *PTYManager) Focus(slot int, isExist bool, callback func(slotNew int)) e
 	// Setup input listeners ONCE - no need to re-setup in loop
 	m.bridgeActive.SetOnInputListener(func(b []byte) {
 		// fmt.Println("DEBUG: input listener received data:", string(b))
+		s.recordInputBytes(b)
+		m.broadcastInput(slot, b)
 	})
 	m.bridgeActive.SetOnInputHitCodeListener(func(b string) {
 		// Only process alt-style messages like "alt+N" or "alt+NN".
@@ -167,7 +225,13 @@ func (m *PTYManager) Focus(slot int, isExist bool, callback func(slotNew int)) e
 					fmt.Println("DEBUG: invalid slot number:", newSlot)
 					return
 				}
-				// Alt+N on same slot → control menu, not slot switch
+				// Alt+N on same slot stops a running broadcast first, then
+				// opens the control menu, not slot switch
+				if gg == slot && m.IsBroadcasting(slot) {
+					m.StopBroadcast()
+					util.Default.PrintBlock("📡 Broadcast stopped", true)
+					return
+				}
 				if gg == slot {
 					m.controlMenuMu.Lock()
 					m.controlMenuRequested = true
@@ -250,17 +314,10 @@ func (m *PTYManager) SetOutputTapForSlot(slot int, tap func([]byte, bool)) error
 	if !ok || s == nil || s.Bridge == nil {
 		return fmt.Errorf("no session in slot %d", slot)
 	}
-	// SSH bridge
-	if sb, ok := s.Bridge.(*sshclient.PTYSSHBridge); ok {
-		sb.SetOutputTap(tap)
-		return nil
-	}
-	// Local bridge
-	if lb, ok := s.Bridge.(*localclient.PTYLocalBridge); ok {
-		lb.SetOutputTap(tap)
-		return nil
-	}
-	return fmt.Errorf("bridge for slot %d does not support output tap", slot)
+	s.tapMu.Lock()
+	s.logTap = tap
+	s.tapMu.Unlock()
+	return m.installTap(s)
 }
 
 // PauseSlot pauses the PTY session in the given slot.
@@ -289,7 +346,11 @@ func (m *PTYManager) PauseSlot(slot int) error {
 
 // ResumeSlot resumes the PTY session in the given slot.
 func (m *PTYManager) ResumeSlot(slot int) error {
-	util.Default.PrintBlock(fmt.Sprintf("✅ You are in slot %d. Press any key to resume\n", slot), true)
+	label := fmt.Sprintf("slot %d", slot)
+	if name := m.SlotName(slot); name != "" {
+		label = fmt.Sprintf("slot %d (%s)", slot, name)
+	}
+	util.Default.PrintBlock(fmt.Sprintf("✅ You are in %s. Press any key to resume\n", label), true)
 	util.Default.ClearLine()
 	m.mu.RLock()
 	s, ok := m.sessions[slot]
@@ -328,10 +389,16 @@ func (m *PTYManager) CloseSlot(slot int) error {
 		log.Println("PTYManager: Error closing bridge for slot", slot, ":", err)
 	}
 	log.Println("PTYManager: Bridge closed for slot", slot)
-
-	// cleanup — safe against double-close (PauseSlot may have already closed them)
+	m.stopRecording(s)
+	m.stopScrollback(s)
+	m.stopSupervisor(s)
+	m.dropFromBroadcast(slot)
+
+	// cleanup — safe against double-close (PauseSlot may have already closed them).
+	// The channels belong to the focused slot; a slot exiting in the
+	// background leaves them alone.
 	m.activeChansClosedMu.Lock()
-	if !m.activeChansClosed {
+	if !m.activeChansClosed && m.bridgeActiveSlot == slot {
 		close(m.routerStop)
 		close(m.Pendingchan)
 		m.activeChansClosed = true
@@ -344,6 +411,25 @@ func (m *PTYManager) CloseSlot(slot int) error {
 	return nil
 }
 
+// SetSlotName names the session in slot (devsync.sessions).
+func (m *PTYManager) SetSlotName(slot int, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[slot]; ok && s != nil {
+		s.Name = name
+	}
+}
+
+// SlotName returns the name of the session in slot, or empty string.
+func (m *PTYManager) SlotName(slot int) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if s, ok := m.sessions[slot]; ok && s != nil {
+		return s.Name
+	}
+	return ""
+}
+
 // HasSlot returns whether there's a session in slot
 func (m *PTYManager) HasSlot(slot int) bool {
 	m.mu.RLock()
@@ -403,5 +489,7 @@ func (m *PTYManager) OpenLocalSlot(slot int, initialCmd string) error {
 	m.mu.Lock()
 	m.sessions[slot] = s
 	m.mu.Unlock()
+	m.startScrollback(s)
+	m.startPathLinks(s)
 	return nil
 }
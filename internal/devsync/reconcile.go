@@ -0,0 +1,488 @@
+package devsync
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"make-sync/internal/sshclient"
+	"make-sync/internal/syncdata"
+	"make-sync/internal/util"
+)
+
+// reconcileListLimit is how many paths of each kind the reconcile summary
+// prints before it only shows a count.
+const reconcileListLimit = 20
+
+// reconcilePlan lists, by slash-separated path relative to the watch path,
+// what a reconcile does.
+type reconcilePlan struct {
+	upload       []string
+	download     []string
+	deleteRemote []string
+	deleteLocal  []string
+	conflict     []string
+	// blocked are local changes trigger_permission keeps from the remote
+	blocked []string
+	// oneWay are changes against the configured sync direction
+	oneWay []string
+	// refresh are identical on both sides but missing or stale in the cache
+	refresh []string
+	// forget are cached but gone on both sides
+	forget []string
+}
+
+func (p *reconcilePlan) summary() string {
+	return fmt.Sprintf("%d to upload, %d to download, %d remote deletes, %d local deletes, %d conflicts",
+		len(p.upload), len(p.download), len(p.deleteRemote), len(p.deleteLocal), len(p.conflict))
+}
+
+// planReconcile compares the local and remote hashes of every path with the
+// cached hash of the last sync. A side still matching the cache is unchanged,
+// so the other side's version (or its delete) wins; paths changed on both
+// sides, or new on both with different content, are conflicts. An empty
+// remote hash (the agent failed to hash the file) never matches.
+func planReconcile(local, remote, cached map[string]string) *reconcilePlan {
+	all := make(map[string]struct{}, len(local)+len(remote)+len(cached))
+	for _, m := range []map[string]string{local, remote, cached} {
+		for rel := range m {
+			all[rel] = struct{}{}
+		}
+	}
+	rels := make([]string, 0, len(all))
+	for rel := range all {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	p := &reconcilePlan{}
+	for _, rel := range rels {
+		lh, lok := local[rel]
+		rh, rok := remote[rel]
+		bh, bok := cached[rel]
+
+		switch {
+		case lok && rok && lh == rh && rh != "":
+			if !bok || bh != lh {
+				p.refresh = append(p.refresh, rel)
+			}
+		case !lok && !rok:
+			p.forget = append(p.forget, rel)
+		case lok && rok:
+			switch {
+			case bok && rh == bh:
+				p.upload = append(p.upload, rel)
+			case bok && lh == bh && rh != "":
+				p.download = append(p.download, rel)
+			default:
+				p.conflict = append(p.conflict, rel)
+			}
+		case lok:
+			switch {
+			case !bok:
+				p.upload = append(p.upload, rel)
+			case lh == bh:
+				p.deleteLocal = append(p.deleteLocal, rel)
+			default:
+				p.conflict = append(p.conflict, rel)
+			}
+		default:
+			switch {
+			case !bok:
+				p.download = append(p.download, rel)
+			case rh == bh:
+				p.deleteRemote = append(p.deleteRemote, rel)
+			default:
+				p.conflict = append(p.conflict, rel)
+			}
+		}
+	}
+	return p
+}
+
+// startupReconcile runs the optional reconcile configured under
+// devsync.startup_reconcile before the agent starts watching.
+func (w *Watcher) startupReconcile() {
+	opts := w.config.Devsync.StartupReconcile
+	if !opts.Enabled || w.sshClient == nil {
+		return
+	}
+	if !w.reconciling.CompareAndSwap(false, true) {
+		return
+	}
+	defer w.reconciling.Store(false)
+
+	if _, err := w.reconcile(opts.DryRun); err != nil {
+		util.Default.Printf("⚠️  Startup reconcile failed: %v\n", err)
+	}
+}
+
+// TriggerReconcile starts a reconcile in both directions in the background.
+// Only one reconcile runs at a time.
+func (w *Watcher) TriggerReconcile() error {
+	if w.sshClient == nil {
+		return fmt.Errorf("SSH client not available")
+	}
+	if !w.reconciling.CompareAndSwap(false, true) {
+		return fmt.Errorf("reconcile already running")
+	}
+	w.emitControlEvent("reconcile_started", "", "")
+	go func() {
+		defer w.reconciling.Store(false)
+		plan, err := w.reconcile(false)
+		if err != nil {
+			w.emitControlEvent("reconcile_failed", "", err.Error())
+			return
+		}
+		w.emitControlEvent("reconcile_finished", "", plan.summary())
+	}()
+	return nil
+}
+
+// reconcile indexes the remote tree, compares it with the local tree and
+// the file cache, prints a summary and, unless dryRun, applies every change
+// that is not a conflict.
+func (w *Watcher) reconcile(dryRun bool) (*reconcilePlan, error) {
+	if w.fileCache == nil {
+		return nil, fmt.Errorf("file cache not available")
+	}
+	w.safePrintln("🔄 Reconciling local and remote trees...")
+
+	remote, err := w.loadRemoteIndex()
+	if err != nil {
+		return nil, err
+	}
+	cached, err := w.fileCache.HashesByPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file cache: %v", err)
+	}
+	local := w.scanLocalHashes()
+
+	// Only paths devsync would sync take part; ignoring a path must not
+	// turn into a delete on the other side
+	for _, m := range []map[string]string{remote, cached} {
+		for rel := range m {
+			if w.skipReconcilePath(rel) {
+				delete(m, rel)
+			}
+		}
+	}
+	if len(remote) == 0 && len(cached) > 0 {
+		return nil, fmt.Errorf("remote index is empty while the cache is not; refusing to reconcile")
+	}
+
+	plan := planReconcile(local, remote, cached)
+	w.applyTriggerPermissions(plan, cached)
+	w.applyDirections(plan)
+	w.printReconcilePlan(plan)
+	if dryRun {
+		w.safePrintln("ℹ️  Dry run: nothing was changed")
+		return plan, nil
+	}
+	w.applyReconcilePlan(plan)
+	w.safeStatusln("✅ Reconcile finished: %s", plan.summary())
+	return plan, nil
+}
+
+// skipReconcilePath reports whether rel is outside what devsync syncs.
+func (w *Watcher) skipReconcilePath(rel string) bool {
+	if rel == ".sync_temp" || strings.HasPrefix(rel, ".sync_temp/") || strings.Contains(rel, "/.sync_temp/") {
+		return true
+	}
+	abs := filepath.Join(w.watchPath, filepath.FromSlash(rel))
+	return w.shouldIgnore(abs) || w.isMuted(abs)
+}
+
+// scanLocalHashes hashes every file of the mapped local roots devsync would
+// sync, keyed by the path relative to the watch path.
+func (w *Watcher) scanLocalHashes() map[string]string {
+	out := make(map[string]string)
+	for _, m := range w.config.PathMappings() {
+		w.scanLocalRoot(m.LocalPath, out)
+	}
+	return out
+}
+
+func (w *Watcher) scanLocalRoot(root string, out map[string]string) {
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == root {
+			return nil
+		}
+		rel, rerr := filepath.Rel(w.watchPath, p)
+		if rerr != nil {
+			return nil
+		}
+		if w.skipReconcilePath(filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if h, herr := w.fileCache.CalculateFileHash(p); herr == nil {
+			out[filepath.ToSlash(rel)] = h
+		}
+		return nil
+	})
+}
+
+// loadRemoteIndex runs the agent indexer on every mapped remote root,
+// downloads the databases and returns the remote file hashes keyed by the
+// local path relative to the watch path.
+func (w *Watcher) loadRemoteIndex() (map[string]string, error) {
+	out := make(map[string]string)
+	for i, m := range w.config.PathMappings() {
+		dbName := "indexing_files.db"
+		if w.config.HasMappings() {
+			w.safePrintf("📁 Indexing mapping %s (%s)\n", m.Label(), m.RemotePath)
+			dbName = fmt.Sprintf("indexing_files_%d.db", i)
+		}
+		if err := w.loadRemoteRootIndex(m.RemotePath, dbName, w.mappingLocalPrefix(m), out); err != nil {
+			if w.config.HasMappings() {
+				return nil, fmt.Errorf("mapping %s: %v", m.Label(), err)
+			}
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// loadRemoteRootIndex indexes one remote root and adds its hashes to out,
+// with prefix prepended to every relative path.
+func (w *Watcher) loadRemoteRootIndex(remoteBase, dbName, prefix string, out map[string]string) error {
+	if remoteBase == "" {
+		return fmt.Errorf("remote base path is empty or not configured")
+	}
+	targetOS := w.config.Devsync.OSTarget
+	remoteSyncTemp := w.joinRemotePathOS(targetOS, remoteBase, ".sync_temp")
+
+	if _, err := syncdata.RemoteRunAgentIndexing(w.sshClient, remoteSyncTemp, targetOS, false, nil, w.config.Devsync.Indexing); err != nil {
+		return fmt.Errorf("remote indexing failed: %v", err)
+	}
+
+	localSyncTemp := filepath.Join(w.watchPath, ".sync_temp")
+	if err := os.MkdirAll(localSyncTemp, 0755); err != nil {
+		return fmt.Errorf("failed to create local .sync_temp: %v", err)
+	}
+	localDB := filepath.Join(localSyncTemp, dbName)
+	if err := w.sshClient.DownloadFile(localDB, w.joinRemotePathOS(targetOS, remoteSyncTemp, "indexing_files.db")); err != nil {
+		return fmt.Errorf("failed to download index DB: %v", err)
+	}
+
+	db, err := gorm.Open(sqlite.Open(localDB), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return fmt.Errorf("failed to open remote index: %v", err)
+	}
+	if sqlDB, derr := db.DB(); derr == nil {
+		defer sqlDB.Close()
+	}
+
+	rows, err := db.Raw(`SELECT rel, hash, is_dir FROM files`).Rows()
+	if err != nil {
+		return fmt.Errorf("failed to query remote index: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rel, hash string
+		var isDir int
+		if err := rows.Scan(&rel, &hash, &isDir); err != nil || isDir != 0 {
+			continue
+		}
+		rel = strings.TrimPrefix(strings.ReplaceAll(rel, "\\", "/"), "./")
+		if rel == "" || rel == "." {
+			continue
+		}
+		out[prefix+rel] = strings.TrimSpace(hash)
+	}
+	return rows.Err()
+}
+
+// applyTriggerPermissions moves local changes that trigger_permission would
+// not let the watcher propagate out of the upload and remote delete lists.
+func (w *Watcher) applyTriggerPermissions(p *reconcilePlan, cached map[string]string) {
+	allowed := func(rel string, eventType EventType) bool {
+		ok, _ := w.isEventAllowed(eventType, filepath.Join(w.watchPath, filepath.FromSlash(rel)), false)
+		if !ok {
+			p.blocked = append(p.blocked, rel)
+		}
+		return ok
+	}
+
+	upload := p.upload[:0]
+	for _, rel := range p.upload {
+		evType := EventWrite
+		if _, ok := cached[rel]; !ok {
+			evType = EventCreate
+		}
+		if allowed(rel, evType) {
+			upload = append(upload, rel)
+		}
+	}
+	p.upload = upload
+
+	deleteRemote := p.deleteRemote[:0]
+	for _, rel := range p.deleteRemote {
+		if allowed(rel, EventRemove) {
+			deleteRemote = append(deleteRemote, rel)
+		}
+	}
+	p.deleteRemote = deleteRemote
+}
+
+// printReconcilePlan prints the summary and the paths of each kind.
+func (w *Watcher) printReconcilePlan(p *reconcilePlan) {
+	w.safePrintf("🔄 Reconcile plan: %s\n", p.summary())
+	groups := []struct {
+		label string
+		rels  []string
+	}{
+		{"⬆️  upload", p.upload},
+		{"⬇️  download", p.download},
+		{"🗑️  delete remote", p.deleteRemote},
+		{"🗑️  delete local", p.deleteLocal},
+		{"⚠️  conflict (skipped)", p.conflict},
+		{"⛔ blocked by trigger_permission", p.blocked},
+		{"⛔ skipped by direction", p.oneWay},
+	}
+	for _, g := range groups {
+		for i, rel := range g.rels {
+			if i == reconcileListLimit {
+				w.safePrintf("   … and %d more\n", len(g.rels)-reconcileListLimit)
+				break
+			}
+			w.safePrintf("   %s %s\n", g.label, rel)
+		}
+	}
+}
+
+// remotePathFor maps a slash-separated path relative to the watch path to
+// its remote path, through the mapping containing it.
+func (w *Watcher) remotePathFor(rel string) string {
+	targetOS := w.config.Devsync.OSTarget
+	remoteBase := w.config.Devsync.Auth.RemotePath
+	if _, m, mrel, ok := w.mappingFor(filepath.Join(w.watchPath, filepath.FromSlash(rel))); ok {
+		remoteBase = m.RemotePath
+		if mrel == "." {
+			return remoteBase
+		}
+		rel = mrel
+	}
+	if strings.Contains(strings.ToLower(targetOS), "win") {
+		rel = strings.ReplaceAll(rel, "/", "\\")
+	}
+	return w.joinRemotePathOS(targetOS, remoteBase, rel)
+}
+
+// applyReconcilePlan transfers and deletes everything in p except conflicts
+// and records the result in the file cache.
+func (w *Watcher) applyReconcilePlan(p *reconcilePlan) {
+	localPath := func(rel string) string {
+		return filepath.Join(w.watchPath, filepath.FromSlash(rel))
+	}
+	concurrency := w.config.Devsync.Concurrency
+	if concurrency <= 0 {
+		concurrency = 5
+	}
+
+	if len(p.upload) > 0 {
+		pairs := make([]sshclient.UploadPair, 0, len(p.upload))
+		for _, rel := range p.upload {
+			pairs = append(pairs, sshclient.UploadPair{Local: localPath(rel), Remote: w.remotePathFor(rel)})
+		}
+		w.transferPairs(pairs, concurrency, true)
+	}
+
+	if len(p.download) > 0 {
+		pairs := make([]sshclient.UploadPair, 0, len(p.download))
+		for _, rel := range p.download {
+			pairs = append(pairs, sshclient.UploadPair{Local: localPath(rel), Remote: w.remotePathFor(rel)})
+		}
+		w.transferPairs(pairs, concurrency, false)
+	}
+
+	for _, rel := range p.deleteRemote {
+		remote := w.remotePathFor(rel)
+		if err := w.sshClient.RunCommand(remoteDeleteCommand(w.config.Devsync.OSTarget, remote, false)); err != nil {
+			w.safePrintf("❌ Failed to delete remote path %s: %v\n", remote, err)
+			w.emitControlEvent("sync_failed", localPath(rel), err.Error())
+			continue
+		}
+		_ = w.fileCache.DeleteFileMetadata(localPath(rel))
+		w.emitControlEvent("delete_remote", localPath(rel), remote)
+	}
+
+	for _, rel := range p.deleteLocal {
+		lp := localPath(rel)
+		if err := os.Remove(lp); err != nil && !os.IsNotExist(err) {
+			w.safePrintf("❌ Failed to delete local path %s: %v\n", lp, err)
+			w.emitControlEvent("sync_failed", lp, err.Error())
+			continue
+		}
+		_ = w.fileCache.DeleteFileMetadata(lp)
+		w.emitControlEvent("delete_local", lp, w.remotePathFor(rel))
+	}
+
+	for _, rel := range p.refresh {
+		_ = w.fileCache.UpdateFileMetadata(localPath(rel))
+	}
+	for _, rel := range p.forget {
+		_ = w.fileCache.DeleteFileMetadata(localPath(rel))
+	}
+	for _, rel := range p.conflict {
+		w.emitControlEvent("reconcile_conflict", localPath(rel), "")
+	}
+}
+
+// transferPairs uploads (or downloads) pairs over SFTP, falls back to scp
+// per file for whatever SFTP could not handle (e.g. Windows targets) and
+// updates the cache for each file that made it.
+func (w *Watcher) transferPairs(pairs []sshclient.UploadPair, concurrency int, upload bool) {
+	var done []string
+	var err error
+	if upload {
+		done, err = w.sshClient.UploadFilesSFTP(pairs, concurrency)
+	} else {
+		done, err = w.sshClient.DownloadFilesSFTP(pairs, concurrency)
+	}
+	if err != nil {
+		w.safePrintf("⚠️  Some transfers failed (sftp): %v\n", err)
+	}
+	ok := make(map[string]struct{}, len(done))
+	for _, lp := range done {
+		ok[lp] = struct{}{}
+	}
+
+	for _, pair := range pairs {
+		if _, found := ok[pair.Local]; !found {
+			var terr error
+			if upload {
+				terr = w.sshClient.UploadFileSCP(pair.Local, pair.Remote)
+			} else {
+				if terr = os.MkdirAll(filepath.Dir(pair.Local), 0755); terr == nil {
+					terr = w.sshClient.DownloadFile(pair.Local, pair.Remote)
+				}
+			}
+			if terr != nil {
+				w.safePrintf("❌ Failed to transfer %s: %v\n", pair.Local, terr)
+				w.emitControlEvent("sync_failed", pair.Local, terr.Error())
+				continue
+			}
+		}
+		_ = w.fileCache.UpdateFileMetadata(pair.Local)
+		if upload {
+			w.emitControlEvent("upload", pair.Local, pair.Remote)
+		} else {
+			w.emitControlEvent("download", pair.Local, pair.Remote)
+		}
+	}
+}
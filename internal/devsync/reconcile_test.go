@@ -0,0 +1,100 @@
+package devsync
+
+import (
+	"reflect"
+	"testing"
+
+	"make-sync/internal/config"
+)
+
+func TestPlanReconcile(t *testing.T) {
+	local := map[string]string{
+		"same":          "h1",
+		"same-uncached": "h1",
+		"local-edit":    "new",
+		"remote-edit":   "old",
+		"both-edit":     "l",
+		"new-local":     "n",
+		"new-both":      "x",
+		"remote-gone":   "old",
+		"remote-gone-e": "new",
+		"bad-hash":      "old",
+	}
+	remote := map[string]string{
+		"same":          "h1",
+		"same-uncached": "h1",
+		"local-edit":    "old",
+		"remote-edit":   "new",
+		"both-edit":     "r",
+		"new-remote":    "n",
+		"new-both":      "y",
+		"local-gone":    "old",
+		"local-gone-e":  "new",
+		"bad-hash":      "",
+	}
+	cached := map[string]string{
+		"same":          "h1",
+		"local-edit":    "old",
+		"remote-edit":   "old",
+		"both-edit":     "old",
+		"remote-gone":   "old",
+		"remote-gone-e": "old",
+		"local-gone":    "old",
+		"local-gone-e":  "old",
+		"bad-hash":      "old",
+		"gone-both":     "old",
+	}
+	p := planReconcile(local, remote, cached)
+	want := &reconcilePlan{
+		upload:       []string{"local-edit", "new-local"},
+		download:     []string{"new-remote", "remote-edit"},
+		deleteRemote: []string{"local-gone"},
+		deleteLocal:  []string{"remote-gone"},
+		conflict:     []string{"bad-hash", "both-edit", "local-gone-e", "new-both", "remote-gone-e"},
+		refresh:      []string{"same-uncached"},
+		forget:       []string{"gone-both"},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Fatalf("got  %+v\nwant %+v", p, want)
+	}
+}
+
+func TestPlanReconcileWithoutCache(t *testing.T) {
+	// a missing cache (first run, deleted .sync_temp) never deletes anything
+	p := planReconcile(
+		map[string]string{"a": "1", "b": "2", "c": "3"},
+		map[string]string{"b": "2", "c": "4", "d": "5"},
+		nil,
+	)
+	want := &reconcilePlan{
+		upload:   []string{"a"},
+		download: []string{"d"},
+		conflict: []string{"c"},
+		refresh:  []string{"b"},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Fatalf("got  %+v\nwant %+v", p, want)
+	}
+}
+
+func TestApplyDirectionsMovesOneWayChanges(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Devsync.Direction = config.DirectionPushOnly
+	w := &Watcher{watchPath: t.TempDir(), config: cfg}
+	p := &reconcilePlan{
+		upload:       []string{"a"},
+		deleteRemote: []string{"b"},
+		download:     []string{"c"},
+		deleteLocal:  []string{"d"},
+	}
+	w.applyDirections(p)
+	if len(p.download) != 0 || len(p.deleteLocal) != 0 {
+		t.Fatalf("push_only kept pulls: %+v", p)
+	}
+	if !reflect.DeepEqual(p.upload, []string{"a"}) || !reflect.DeepEqual(p.deleteRemote, []string{"b"}) {
+		t.Fatalf("push_only dropped pushes: %+v", p)
+	}
+	if !reflect.DeepEqual(p.oneWay, []string{"c", "d"}) {
+		t.Fatalf("one-way: got %v, want [c d]", p.oneWay)
+	}
+}
@@ -0,0 +1,160 @@
+package devsync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"make-sync/internal/cast"
+	"make-sync/internal/devsync/localclient"
+	"make-sync/internal/sshclient"
+	"make-sync/internal/util"
+
+	"golang.org/x/term"
+)
+
+// resizeCheckInterval limits how often a recording tap polls the terminal
+// size for "r" events.
+const resizeCheckInterval = 500 * time.Millisecond
+
+// terminalSize returns the size of the attached terminal, or 80x24.
+func terminalSize() (int, int) {
+	if w, h, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 && h > 0 {
+		return w, h
+	}
+	return 80, 24
+}
+
+// isCastFile reports whether a ">>> file" marker asks for a recording
+// instead of a plain text log.
+func isCastFile(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), ".cast")
+}
+
+// StartRecording records the slot to a .cast file in the configured
+// recordings directory. name may be empty to use slot<N>-<time>.cast.
+// Returns the path of the recording.
+func (m *PTYManager) StartRecording(slot int, name string) (string, error) {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil {
+		return "", fmt.Errorf("no session in slot %d", slot)
+	}
+	cfg := m.w.config
+	if cfg == nil {
+		return "", fmt.Errorf("no config loaded")
+	}
+	if name == "" {
+		name = fmt.Sprintf("slot%d-%s.cast", slot, time.Now().Format("20060102-150405"))
+	}
+	width, height := terminalSize()
+	rec, err := cast.NewRecorder(filepath.Join(cfg.RecordingDir(), name), width, height, s.Cmd)
+	if err != nil {
+		return "", err
+	}
+
+	s.tapMu.Lock()
+	old := s.recorder
+	s.recorder = rec
+	s.recordInput = cfg.Devsync.Recording.Input
+	s.tapMu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	if err := m.installTap(s); err != nil {
+		m.stopRecording(s)
+		return "", err
+	}
+	return rec.Path(), nil
+}
+
+// stopRecording closes the recording of s, if any.
+func (m *PTYManager) stopRecording(s *PTYSession) {
+	s.tapMu.Lock()
+	rec := s.recorder
+	s.recorder = nil
+	s.tapMu.Unlock()
+	if rec != nil {
+		rec.Close()
+	}
+}
+
+// recordInputBytes passes keystrokes of s to its recording when input recording
+// is enabled.
+func (s *PTYSession) recordInputBytes(b []byte) {
+	s.tapMu.Lock()
+	rec, input := s.recorder, s.recordInput
+	s.tapMu.Unlock()
+	if rec != nil && input {
+		rec.Input(b)
+	}
+}
+
+// installTap sets a bridge output tap feeding the text log (">>> file"), the
+// recording, the scrollback, the split pane and the triggers of s. Bridges accept a single tap, so all of
+// them share it.
+func (m *PTYManager) installTap(s *PTYSession) error {
+	s.tapMu.Lock()
+	logTap, rec, scroll, pane, trig := s.logTap, s.recorder, s.scroll, s.pane, s.triggers
+	s.tapMu.Unlock()
+
+	var tap func([]byte, bool)
+	if logTap != nil || rec != nil || scroll != nil || pane != nil || trig != nil {
+		var sizeMu sync.Mutex
+		var lastCheck time.Time
+		tap = func(b []byte, isErr bool) {
+			if logTap != nil {
+				logTap(b, isErr)
+			}
+			if scroll != nil {
+				scroll.Write(b)
+			}
+			if pane != nil {
+				pane(b)
+			}
+			if trig != nil {
+				trig.Write(b)
+			}
+			if rec != nil {
+				sizeMu.Lock()
+				if time.Since(lastCheck) >= resizeCheckInterval {
+					lastCheck = time.Now()
+					rec.Resize(s.windowSize())
+				}
+				sizeMu.Unlock()
+				rec.Output(b)
+			}
+		}
+	}
+
+	switch b := s.Bridge.(type) {
+	case *sshclient.PTYSSHBridge:
+		b.SetOutputTap(tap)
+	case *localclient.PTYLocalBridge:
+		b.SetOutputTap(tap)
+	default:
+		return fmt.Errorf("bridge for slot %d does not support output tap", s.Slot)
+	}
+	return nil
+}
+
+// startSlotRecording starts recording a freshly opened slot when the command
+// marker names a .cast file or devsync.recording.enabled is set.
+func (w *Watcher) startSlotRecording(slot int, logFile string) {
+	name := ""
+	if isCastFile(logFile) {
+		name = logFile
+	} else if w.config == nil || !w.config.Devsync.Recording.Enabled {
+		return
+	}
+	path, err := w.ptyMgr.StartRecording(slot, name)
+	if err != nil {
+		util.Default.Printf("⚠️  Failed to start recording slot %d: %v\n", slot, err)
+		return
+	}
+	util.Default.Printf("🎥 Recording slot %d to %s\n", slot, path)
+}
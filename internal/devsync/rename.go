@@ -0,0 +1,209 @@
+package devsync
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// renamePairWindowMin is the shortest time a vanished path waits for its
+// matching appearance before it is handled as a delete.
+const renamePairWindowMin = 300 * time.Millisecond
+
+// renameContentSamples is how many cached files of a vanished directory are
+// compared with the new location when no inotify cookie is available.
+const renameContentSamples = 3
+
+// vanishedPath is a rename-away or remove event held back so that a create
+// arriving within the debounce window can be paired with it as a move.
+type vanishedPath struct {
+	ev     FileEvent
+	cookie uint32
+	timer  *time.Timer
+}
+
+// renamePairWindow returns how long vanished paths wait for a partner.
+func (w *Watcher) renamePairWindow() time.Duration {
+	if w.debounceDelay > renamePairWindowMin {
+		return w.debounceDelay
+	}
+	return renamePairWindowMin
+}
+
+// holdVanished delays the delete handling of ev (whose path no longer
+// exists). If nothing claims it within the window and trigger permissions
+// allow the delete, it is processed through ExecuteScripts.
+func (w *Watcher) holdVanished(ev FileEvent, cookie uint32) {
+	// The path is gone, so only the cache can tell whether it was a directory
+	if w.fileCache != nil && !ev.IsDir {
+		if cached, err := w.fileCache.ListFilesUnder(ev.Path); err == nil {
+			for _, p := range cached {
+				if p != ev.Path {
+					ev.IsDir = true
+					break
+				}
+			}
+		}
+	}
+
+	key := ev.Path
+	vp := &vanishedPath{ev: ev, cookie: cookie}
+	w.movesMu.Lock()
+	if w.vanished == nil {
+		w.vanished = make(map[string]*vanishedPath)
+	}
+	if prev, ok := w.vanished[key]; ok {
+		// inotify reports a moved directory twice (IN_MOVE_SELF without a
+		// cookie and IN_MOVED_FROM with one); keep the cookie
+		prev.timer.Stop()
+		if vp.cookie == 0 {
+			vp.cookie = prev.cookie
+		}
+	}
+	vp.timer = time.AfterFunc(w.renamePairWindow(), func() {
+		w.movesMu.Lock()
+		cur, ok := w.vanished[key]
+		if !ok || cur != vp {
+			w.movesMu.Unlock()
+			return
+		}
+		delete(w.vanished, key)
+		w.movesMu.Unlock()
+		if w.checkEventAllowed(vp.ev.EventType, vp.ev.Path, vp.ev.IsDir) {
+			w.ExecuteScripts(vp.ev)
+		}
+	})
+	w.vanished[key] = vp
+	w.movesMu.Unlock()
+}
+
+// matchVanished returns the held event that path (which just appeared) was
+// renamed or moved from, and removes it from the pending set. Pairs are
+// matched by inotify cookie when available; otherwise a single candidate in
+// the same directory or with the same name must also have the same content
+// according to the FileCache.
+func (w *Watcher) matchVanished(path string, isDir bool, cookie uint32) *FileEvent {
+	w.movesMu.Lock()
+	var match *vanishedPath
+	var candidates []*vanishedPath
+	for _, vp := range w.vanished {
+		if cookie != 0 && vp.cookie == cookie {
+			match = vp
+			break
+		}
+		if vp.ev.IsDir != isDir || vp.ev.Path == path {
+			continue
+		}
+		if filepath.Base(vp.ev.Path) == filepath.Base(path) || filepath.Dir(vp.ev.Path) == filepath.Dir(path) {
+			candidates = append(candidates, vp)
+		}
+	}
+	w.movesMu.Unlock()
+
+	if match == nil {
+		// Hash outside the lock; the candidate may expire meanwhile
+		var same []*vanishedPath
+		for _, vp := range candidates {
+			if w.sameContent(vp.ev.Path, path, isDir) {
+				same = append(same, vp)
+			}
+		}
+		if len(same) != 1 {
+			return nil
+		}
+		match = same[0]
+	}
+
+	w.movesMu.Lock()
+	defer w.movesMu.Unlock()
+	if cur, ok := w.vanished[match.ev.Path]; !ok || cur != match {
+		return nil
+	}
+	match.timer.Stop()
+	delete(w.vanished, match.ev.Path)
+	ev := match.ev
+	return &ev
+}
+
+// sameContent compares the cached content of oldPath with newPath on disk.
+// Directories compare a few sampled files.
+func (w *Watcher) sameContent(oldPath, newPath string, isDir bool) bool {
+	if w.fileCache == nil {
+		return false
+	}
+	if !isDir {
+		cached, ok := w.fileCache.GetHash(oldPath)
+		if !ok {
+			return false
+		}
+		current, err := w.fileCache.CalculateFileHash(newPath)
+		return err == nil && current == cached
+	}
+
+	files, err := w.fileCache.ListFilesUnder(oldPath)
+	if err != nil || len(files) == 0 {
+		return false
+	}
+	if len(files) > renameContentSamples {
+		files = files[:renameContentSamples]
+	}
+	for _, p := range files {
+		rel, rerr := filepath.Rel(oldPath, p)
+		if rerr != nil {
+			return false
+		}
+		if !w.sameContent(p, filepath.Join(newPath, rel), false) {
+			return false
+		}
+	}
+	return true
+}
+
+// dispatchMove handles a paired rename/move: the FileCache entries follow
+// the new path right away (so events for files inside a moved directory are
+// recognised as unchanged) and the queued processor issues a remote move,
+// uploading only if that fails.
+func (w *Watcher) dispatchMove(old FileEvent, newPath string, isDir bool) {
+	w.safePrintf("🔀 Detected move: %s -> %s\n", old.Path, newPath)
+	if w.fileCache != nil {
+		if err := w.fileCache.RenamePath(old.Path, newPath); err != nil {
+			w.safePrintf("⚠️  Failed to move cache entries %s -> %s: %v\n", old.Path, newPath, err)
+		}
+	}
+	w.ExecuteScripts(FileEvent{
+		Path:      newPath,
+		OldPath:   old.Path,
+		EventType: EventRename,
+		IsDir:     isDir,
+		Timestamp: time.Now(),
+	})
+}
+
+// remoteMoveCommand returns the shell command that renames oldRemote to
+// newRemote on the target OS.
+func remoteMoveCommand(targetOS, oldRemote, newRemote string) string {
+	if strings.Contains(strings.ToLower(targetOS), "win") {
+		oldRemote = strings.ReplaceAll(oldRemote, "/", "\\")
+		newRemote = strings.ReplaceAll(newRemote, "/", "\\")
+		return fmt.Sprintf("cmd.exe /C move /Y \"%s\" \"%s\"", oldRemote, newRemote)
+	}
+	return fmt.Sprintf("mv '%s' '%s'", oldRemote, newRemote)
+}
+
+// remoteDeleteCommand returns the shell command that deletes remotePath on
+// the target OS. With dir it also removes a directory tree; pass it when the
+// path may be one.
+func remoteDeleteCommand(targetOS, remotePath string, dir bool) string {
+	if strings.Contains(strings.ToLower(targetOS), "win") {
+		p := strings.ReplaceAll(remotePath, "/", "\\")
+		if dir {
+			return fmt.Sprintf("cmd.exe /C if exist \"%s\\*\" (rmdir /S /Q \"%s\") else (del /F /Q \"%s\")", p, p, p)
+		}
+		return fmt.Sprintf("cmd.exe /C del /F /Q \"%s\"", p)
+	}
+	if dir {
+		return fmt.Sprintf("rm -rf '%s'", remotePath)
+	}
+	return fmt.Sprintf("rm -f '%s'", remotePath)
+}
@@ -0,0 +1,18 @@
+//go:build linux
+// +build linux
+
+package devsync
+
+import (
+	"github.com/rjeczalik/notify"
+	"golang.org/x/sys/unix"
+)
+
+// notifyMoveCookie returns the inotify cookie shared by the IN_MOVED_FROM and
+// IN_MOVED_TO halves of a rename (0 when unavailable).
+func notifyMoveCookie(ev notify.EventInfo) uint32 {
+	if ie, ok := ev.Sys().(*unix.InotifyEvent); ok && ie != nil {
+		return ie.Cookie
+	}
+	return 0
+}
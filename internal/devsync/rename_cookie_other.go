@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package devsync
+
+import "github.com/rjeczalik/notify"
+
+// notifyMoveCookie is only available on inotify; other backends fall back to
+// matching renames by name and content (see matchVanished).
+func notifyMoveCookie(ev notify.EventInfo) uint32 {
+	return 0
+}
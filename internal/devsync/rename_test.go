@@ -0,0 +1,130 @@
+package devsync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"make-sync/internal/config"
+	"make-sync/internal/sshclient"
+)
+
+func newRenameTestWatcher(t *testing.T) *Watcher {
+	t.Helper()
+	dir := t.TempDir()
+	cache, err := NewFileCache(filepath.Join(t.TempDir(), "cache.db"), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	cfg := &config.Config{}
+	cfg.Devsync.TriggerPerm.Unlink = true
+	return &Watcher{
+		watchPath:   dir,
+		config:      cfg,
+		fileCache:   cache,
+		debounceMap: make(map[string]*debounceEntry),
+	}
+}
+
+func writeCached(t *testing.T, w *Watcher, rel, content string) string {
+	t.Helper()
+	p := filepath.Join(w.watchPath, rel)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.fileCache.UpdateFileMetadata(p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestMatchVanishedByCookie(t *testing.T) {
+	w := newRenameTestWatcher(t)
+	old := filepath.Join(w.watchPath, "a", "old.txt")
+	w.holdVanished(FileEvent{Path: old, EventType: EventRename}, 42)
+	w.holdVanished(FileEvent{Path: filepath.Join(w.watchPath, "other.txt"), EventType: EventRemove}, 7)
+
+	// cookies pair across directories without looking at content
+	if ev := w.matchVanished(filepath.Join(w.watchPath, "b", "new.txt"), false, 42); ev == nil || ev.Path != old {
+		t.Fatalf("got %v, want %s", ev, old)
+	}
+	if ev := w.matchVanished(filepath.Join(w.watchPath, "b", "new.txt"), false, 42); ev != nil {
+		t.Fatalf("matched %s twice", ev.Path)
+	}
+}
+
+func TestMatchVanishedByHash(t *testing.T) {
+	w := newRenameTestWatcher(t)
+	old := writeCached(t, w, "src/foo.go", "package foo")
+	other := writeCached(t, w, "src/bar.go", "package bar")
+	moved := filepath.Join(w.watchPath, "src", "foo2.go")
+	if err := os.Rename(old, moved); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(other)
+	w.holdVanished(FileEvent{Path: old, EventType: EventRemove}, 0)
+	w.holdVanished(FileEvent{Path: other, EventType: EventRemove}, 0)
+
+	if ev := w.matchVanished(filepath.Join(w.watchPath, "lib", "x.go"), false, 0); ev != nil {
+		t.Fatalf("paired %s with an unrelated path", ev.Path)
+	}
+	if ev := w.matchVanished(moved, false, 0); ev == nil || ev.Path != old {
+		t.Fatalf("got %v, want %s", ev, old)
+	}
+
+	// same name in another directory, but different content
+	changed := writeCached(t, w, "lib/bar.go", "package changed")
+	if ev := w.matchVanished(changed, false, 0); ev != nil {
+		t.Fatalf("paired %s despite different content", ev.Path)
+	}
+}
+
+func TestVanishedExpiresToDelete(t *testing.T) {
+	w := newRenameTestWatcher(t)
+	// paused uploads hold the delete instead of running it on the remote
+	w.sshClient = &sshclient.SSHClient{}
+	w.uploadsPaused.Store(true)
+	gone := filepath.Join(w.watchPath, "gone.txt")
+	w.holdVanished(FileEvent{Path: gone, EventType: EventRemove}, 0)
+
+	deadline := time.Now().Add(w.renamePairWindow() + 2*time.Second)
+	for time.Now().Before(deadline) {
+		w.debounceMu.Lock()
+		ent, ok := w.debounceMap[gone]
+		w.debounceMu.Unlock()
+		if ok {
+			if ent.evt.EventType != EventRemove {
+				t.Fatalf("held %v, want a remove", ent.evt.EventType)
+			}
+			if ev := w.matchVanished(gone+".new", false, 0); ev != nil {
+				t.Fatal("expired path still pairs")
+			}
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("vanished path was not handled as a delete")
+}
+
+func TestRemoteMoveAndDeleteCommands(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{remoteMoveCommand("linux", "/srv/a/x.txt", "/srv/b/x.txt"), `mv '/srv/a/x.txt' '/srv/b/x.txt'`},
+		{remoteMoveCommand("Windows", "C:/app/a.txt", "C:/app/b.txt"), `cmd.exe /C move /Y "C:\app\a.txt" "C:\app\b.txt"`},
+		{remoteDeleteCommand("linux", "/srv/a.txt", false), `rm -f '/srv/a.txt'`},
+		{remoteDeleteCommand("linux", "/srv/dir", true), `rm -rf '/srv/dir'`},
+		{remoteDeleteCommand("win64", "C:/app/a.txt", false), `cmd.exe /C del /F /Q "C:\app\a.txt"`},
+		{remoteDeleteCommand("win", "C:/app/d", true), `cmd.exe /C if exist "C:\app\d\*" (rmdir /S /Q "C:\app\d") else (del /F /Q "C:\app\d")`},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %s, want %s", tt.got, tt.want)
+		}
+	}
+}
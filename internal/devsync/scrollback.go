@@ -0,0 +1,88 @@
+package devsync
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"make-sync/internal/config"
+	"make-sync/internal/scrollback"
+	"make-sync/internal/tui"
+	"make-sync/internal/util"
+)
+
+// startScrollback attaches a scrollback buffer to a freshly opened slot.
+// Output older than devsync.scrollback.memory spills to
+// .sync_temp/scrollback/slot<N>.txt.
+func (m *PTYManager) startScrollback(s *PTYSession) {
+	var sb config.Scrollback
+	if m.w.config != nil {
+		sb = m.w.config.Devsync.Scrollback
+	}
+	spill := filepath.Join(m.w.watchPath, ".sync_temp", "scrollback", fmt.Sprintf("slot%d.txt", s.Slot))
+	s.tapMu.Lock()
+	s.scroll = scrollback.New(spill, sb.MemoryBytes(), sb.SizeBytes())
+	s.tapMu.Unlock()
+	if err := m.installTap(s); err != nil {
+		util.Default.Printf("⚠️  Scrollback unavailable for slot %d: %v\n", s.Slot, err)
+	}
+}
+
+// stopScrollback drops the history of s and its spill file.
+func (m *PTYManager) stopScrollback(s *PTYSession) {
+	s.tapMu.Lock()
+	buf := s.scroll
+	s.scroll = nil
+	s.tapMu.Unlock()
+	if buf != nil {
+		buf.Close()
+	}
+}
+
+// ScrollbackLines returns the output history of slot as plain text lines.
+func (m *PTYManager) ScrollbackLines(slot int) ([]string, error) {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil {
+		return nil, fmt.Errorf("no session in slot %d", slot)
+	}
+	s.tapMu.Lock()
+	buf := s.scroll
+	s.tapMu.Unlock()
+	if buf == nil {
+		return nil, fmt.Errorf("no scrollback for slot %d", slot)
+	}
+	return buf.Lines(), nil
+}
+
+// showScrollback opens the scrollback viewer of slot. Saved ranges go to
+// .sync_temp in the project.
+func (w *Watcher) showScrollback(slot int) {
+	lines, err := w.ptyMgr.ScrollbackLines(slot)
+	if err != nil {
+		util.Default.Printf("❌ %v\n", err)
+		return
+	}
+	saveDir := filepath.Join(w.watchPath, ".sync_temp")
+	if err := tui.ShowScrollback(fmt.Sprintf("Slot %d", slot), lines, saveDir); err != nil {
+		util.Default.Printf("❌ Scrollback viewer failed: %v\n", err)
+	}
+}
+
+// SeedScrollback appends history (e.g. restored from a multiplexer) to the
+// scrollback of slot. Call it before the slot is started so the history lands
+// ahead of the slot's own output.
+func (m *PTYManager) SeedScrollback(slot int, history string) {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil {
+		return
+	}
+	s.tapMu.Lock()
+	buf := s.scroll
+	s.tapMu.Unlock()
+	if buf != nil {
+		buf.Write([]byte(history))
+	}
+}
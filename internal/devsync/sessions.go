@@ -0,0 +1,242 @@
+package devsync
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"make-sync/internal/config"
+	"make-sync/internal/util"
+)
+
+// escapeCmdExe escapes percent signs and carets so a command survives
+// cmd.exe parsing.
+func escapeCmdExe(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	s = strings.ReplaceAll(s, "^", "^^")
+	return s
+}
+
+// sortedEnv returns the keys of env in a stable order.
+func sortedEnv(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// remoteSlotCommand builds the command of a remote slot running baseCmd in
+// remotePath. On Windows targets baseCmd is escaped unless verbatim (commands
+// from the user's config are passed exactly as written). env is exported
+// into the shell, so it outlives baseCmd.
+func remoteSlotCommand(targetOS, remotePath, baseCmd string, env map[string]string, verbatim bool) string {
+	if strings.Contains(targetOS, "windows") {
+		// Normalize remotePath for Windows: convert '/c/Users' -> 'C:\Users' and
+		// replace forward slashes with backslashes so cmd.exe accepts it.
+		winPath := remotePath
+		if strings.HasPrefix(winPath, "/") && len(winPath) > 2 && winPath[2] == '/' {
+			// pattern like /c/Users -> drive letter at pos 1
+			d := strings.ToUpper(string(winPath[1]))
+			rest := winPath[2:]
+			rest = strings.ReplaceAll(rest, "/", "\\\\")
+			winPath = d + ":" + rest
+		} else {
+			winPath = strings.ReplaceAll(winPath, "/", "\\\\")
+		}
+		cmdPart := baseCmd
+		if !verbatim {
+			cmdPart = escapeCmdExe(baseCmd)
+		}
+		var sets strings.Builder
+		for _, k := range sortedEnv(env) {
+			fmt.Fprintf(&sets, "set \"%s=%s\" & ", k, escapeCmdExe(env[k]))
+		}
+		// Run the user's command; assume the directory already exists and
+		// just change directory into it before running the command.
+		body := fmt.Sprintf("%scd /d \"%s\" & %s", sets.String(), winPath, cmdPart)
+		// Do not add extra outer quoting or backslash-escaped quotes here;
+		// pass the body (which already contains quoted paths) directly to cmd.exe
+		return fmt.Sprintf("cmd.exe /K %s", body)
+	}
+	var exports strings.Builder
+	for _, k := range sortedEnv(env) {
+		fmt.Fprintf(&exports, "export %s=%s && ", k, shellEscape(env[k]))
+	}
+	return fmt.Sprintf("%smkdir -p %s || true && cd %s && bash -c %s ; exec bash",
+		exports.String(), shellEscape(remotePath), shellEscape(remotePath), shellEscape(baseCmd))
+}
+
+// localSlotCommand builds the command of a local slot running baseCmd in
+// localPath, using the shell of the host OS.
+func localSlotCommand(localPath, baseCmd string, env map[string]string) string {
+	if runtime.GOOS == "windows" {
+		var sets strings.Builder
+		for _, k := range sortedEnv(env) {
+			fmt.Fprintf(&sets, "set \"%s=%s\" & ", k, escapeCmdExe(env[k]))
+		}
+		// if baseCmd provided, execute it and keep shell open; else open shell only
+		if strings.TrimSpace(baseCmd) == "" {
+			if sets.Len() == 0 {
+				return ""
+			}
+			return fmt.Sprintf("%scd %s & cmd", sets.String(), localPath)
+		}
+		return fmt.Sprintf("%scd %s & %s & cmd", sets.String(), localPath, escapeCmdExe(baseCmd))
+	}
+	var exports strings.Builder
+	for _, k := range sortedEnv(env) {
+		fmt.Fprintf(&exports, "export %s=%s && ", k, shellEscape(env[k]))
+	}
+	return fmt.Sprintf("%scd %s && bash -c %s ; exec bash", exports.String(), shellEscape(localPath), shellEscape(baseCmd))
+}
+
+// sessionDir resolves the working directory of a devsync.sessions entry.
+func (w *Watcher) sessionDir(spec config.SessionSpec) string {
+	cfg := w.config
+	if spec.IsLocal() {
+		root := cfg.Devsync.Auth.LocalPath
+		if root == "" {
+			root = "."
+		}
+		if spec.Dir == "" {
+			return root
+		}
+		if filepath.IsAbs(spec.Dir) {
+			return spec.Dir
+		}
+		return filepath.Join(root, filepath.FromSlash(spec.Dir))
+	}
+	root := cfg.Devsync.Auth.RemotePath
+	if root == "" {
+		root = "/tmp"
+	}
+	dir := strings.ReplaceAll(spec.Dir, "\\", "/")
+	if dir == "" {
+		return root
+	}
+	if strings.HasPrefix(dir, "/") || (len(dir) > 1 && dir[1] == ':') {
+		return dir
+	}
+	return path.Join(root, dir)
+}
+
+// openConfiguredSession opens the slot of a devsync.sessions entry without
+// attaching it. With autoStart its command starts in the background right
+// away (persistent remote sessions inside the multiplexer); other slots start
+// their command on first focus.
+func (w *Watcher) openConfiguredSession(spec config.SessionSpec, autoStart bool) error {
+	if w.ptyMgr == nil {
+		return fmt.Errorf("PTY manager not initialized")
+	}
+	if w.ptyMgr.HasSlot(spec.Slot) {
+		return fmt.Errorf("slot %d is already open", spec.Slot)
+	}
+	dir := w.sessionDir(spec)
+	supervised := w.supervised(spec)
+	command := spec.Command
+	if supervised {
+		command = supervisedCommand(command)
+	}
+	if spec.IsLocal() {
+		if err := w.ptyMgr.OpenLocalSlot(spec.Slot, localSlotCommand(dir, command, spec.Env)); err != nil {
+			return err
+		}
+	} else {
+		targetOS := strings.ToLower(w.config.Devsync.OSTarget)
+		cmd := remoteSlotCommand(targetOS, dir, command, spec.Env, true)
+		cmd, detached := w.persistentCommand(spec.Slot, cmd)
+		if detached == nil && autoStart {
+			w.startPersistentDetached(spec.Slot, cmd)
+		}
+		if err := w.ptyMgr.OpenRemoteSlot(spec.Slot, cmd); err != nil {
+			return err
+		}
+		if detached != nil {
+			w.restorePersistentScrollback(*detached)
+		}
+	}
+	w.ptyMgr.SetSlotName(spec.Slot, spec.Label())
+	if err := w.ptyMgr.watchSessionOutput(spec.Slot, spec, supervised); err != nil {
+		util.Default.Printf("⚠️  Triggers and restart policy unavailable for slot %d: %v\n", spec.Slot, err)
+	}
+	w.startSlotRecording(spec.Slot, "")
+	if autoStart {
+		return w.ptyMgr.StartInBackground(spec.Slot)
+	}
+	return nil
+}
+
+// autoStartSessions opens the devsync.sessions entries marked auto_start.
+func (w *Watcher) autoStartSessions() {
+	if w.config == nil {
+		return
+	}
+	for _, spec := range w.config.Devsync.Sessions {
+		if !spec.AutoStart {
+			continue
+		}
+		if !spec.IsLocal() && w.sshClient == nil {
+			util.Default.Printf("⚠️  Session %q (slot %d) needs an SSH connection — skipped\n", spec.Label(), spec.Slot)
+			continue
+		}
+		if err := w.openConfiguredSession(spec, true); err != nil {
+			util.Default.Printf("⚠️  Failed to open session %q in slot %d: %v\n", spec.Label(), spec.Slot, err)
+			continue
+		}
+		util.Default.Printf("🪟 Session %q running in slot %d — press Alt+%d to attach\n", spec.Label(), spec.Slot, spec.Slot)
+	}
+}
+
+// slotLabel returns "slot N" with the slot's session name, if any.
+func (w *Watcher) slotLabel(slot int) string {
+	if w.ptyMgr != nil {
+		if name := w.ptyMgr.SlotName(slot); name != "" {
+			return fmt.Sprintf("slot %d (%s)", slot, name)
+		}
+	}
+	return fmt.Sprintf("slot %d", slot)
+}
+
+// sessionMenuLines lists the devsync.sessions entries for the main menu.
+func (w *Watcher) sessionMenuLines() []string {
+	if w.config == nil || len(w.config.Devsync.Sessions) == 0 {
+		return nil
+	}
+	lines := []string{"Sessions:"}
+	for _, spec := range w.config.Devsync.Sessions {
+		side := config.SessionSideRemote
+		if spec.IsLocal() {
+			side = config.SessionSideLocal
+		}
+		state := "not open"
+		if w.ptyMgr != nil && w.ptyMgr.HasSlot(spec.Slot) {
+			state = "open"
+			if sup := w.ptyMgr.supervisorState(spec.Slot); sup != "" {
+				state += ", " + sup
+			}
+		}
+		lines = append(lines, fmt.Sprintf("  Alt+%d - %s (%s, %s)", spec.Slot, spec.Label(), side, state))
+	}
+	return lines
+}
+
+// slotStatuses lists the open PTY slots for the control API.
+func (w *Watcher) slotStatuses() []SlotStatus {
+	if w.ptyMgr == nil {
+		return nil
+	}
+	slots := w.ptyMgr.ListSlots()
+	sort.Ints(slots)
+	out := make([]SlotStatus, 0, len(slots))
+	for _, slot := range slots {
+		st := SlotStatus{Slot: slot, Name: w.ptyMgr.SlotName(slot), Command: w.ptyMgr.GetSlotCmd(slot), Broadcast: w.ptyMgr.IsBroadcasting(slot)}
+		w.ptyMgr.fillSupervisorStatus(slot, &st)
+		out = append(out, st)
+	}
+	return out
+}
@@ -0,0 +1,549 @@
+package devsync
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"make-sync/internal/util"
+	"make-sync/internal/vterm"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+	"github.com/manifoldco/promptui"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/cancelreader"
+	"golang.org/x/term"
+)
+
+// Split layouts.
+const (
+	SplitSideBySide = "side-by-side" // panes next to each other
+	SplitStacked    = "stacked"      // panes on top of each other
+)
+
+const (
+	splitMinPanes   = 2
+	splitMaxPanes   = 4
+	splitFrameDelay = 25 * time.Millisecond
+	// housekeeping (terminal size, closed slots) every this many frames
+	splitCheckFrames = 20
+)
+
+// windowSize returns the size the slot's PTY currently has.
+func (s *PTYSession) windowSize() (int, int) {
+	s.tapMu.Lock()
+	cols, rows := s.winCols, s.winRows
+	s.tapMu.Unlock()
+	if cols > 0 && rows > 0 {
+		return cols, rows
+	}
+	return terminalSize()
+}
+
+// SetSlotWindowSize pins the PTY of slot to cols x rows; zero follows the
+// terminal again.
+func (m *PTYManager) SetSlotWindowSize(slot, cols, rows int) error {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil || s.Bridge == nil {
+		return fmt.Errorf("no session in slot %d", slot)
+	}
+	s.tapMu.Lock()
+	s.winCols, s.winRows = max(cols, 0), max(rows, 0)
+	s.tapMu.Unlock()
+	return s.Bridge.SetWindowSize(cols, rows)
+}
+
+// setPaneTap routes the output of slot to a split pane (nil detaches it).
+func (m *PTYManager) setPaneTap(slot int, fn func([]byte)) error {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil {
+		return fmt.Errorf("no session in slot %d", slot)
+	}
+	s.tapMu.Lock()
+	s.pane = fn
+	s.tapMu.Unlock()
+	return m.installTap(s)
+}
+
+// writeSlotInput sends keyboard input to slot without focusing it.
+func (m *PTYManager) writeSlotInput(slot int, b []byte) error {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil || s.Bridge == nil {
+		return fmt.Errorf("no session in slot %d", slot)
+	}
+	w := s.Bridge.GetStdinWriter()
+	if w == nil {
+		return fmt.Errorf("slot %d is not started", slot)
+	}
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	s.recordInputBytes(b)
+	m.broadcastInput(slot, b)
+	return nil
+}
+
+// splitSlots returns the slots that can be shown in a split pane: open and
+// started at least once (their shell is running).
+func (m *PTYManager) splitSlots() []int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []int
+	for slot, s := range m.sessions {
+		if s != nil && s.started {
+			out = append(out, slot)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+type splitPane struct {
+	slot   int
+	label  string
+	vt     *vterm.Terminal
+	title  cellbuf.Rectangle
+	area   cellbuf.Rectangle
+	closed bool
+}
+
+// splitView renders several slots at once, each through its own virtual
+// terminal, and sends keyboard input to the focused pane.
+type splitView struct {
+	w      *Watcher
+	layout string
+	panes  []*splitPane
+	focus  int
+	out    io.Writer
+
+	width, height int
+	frame, prev   *cellbuf.Buffer
+	fullRedraw    bool
+	dirty         atomic.Bool
+
+	// modes of the focused pane mirrored to the real terminal
+	appCursor, paste, cursorShown bool
+}
+
+// showSplitMenu asks for the slots and the layout, then runs the split view
+// until the user leaves it.
+func (w *Watcher) showSplitMenu() {
+	if w.ptyMgr == nil {
+		return
+	}
+	slots := w.ptyMgr.splitSlots()
+	if len(slots) < splitMinPanes {
+		util.Default.Printf("🪟 Split view needs at least %d started slots (open them with Alt+3..9 first)\n", splitMinPanes)
+		return
+	}
+
+	var picked []int
+	layout := ""
+	func() {
+		util.Default.Suspend()
+		defer util.Default.Resume()
+		promptMu.Lock()
+		defer promptMu.Unlock()
+
+		selected := make(map[int]bool)
+		for _, s := range slots[:min(len(slots), splitMaxPanes)] {
+			selected[s] = true
+		}
+		ok := w.pickSlots("🪟 Slots to show in split view (Enter toggles)", "Continue", slots, selected, func(p []int) error {
+			if len(p) < splitMinPanes || len(p) > splitMaxPanes {
+				return fmt.Errorf("select %d to %d slots", splitMinPanes, splitMaxPanes)
+			}
+			picked = p
+			return nil
+		})
+		if !ok {
+			return
+		}
+		sel := promptui.Select{
+			Label:    "🪟 Layout",
+			Items:    []string{"Side by side (vertical split)", "Stacked (horizontal split)", "Cancel"},
+			HideHelp: true,
+		}
+		i, _, err := sel.Run()
+		if err != nil || i == 2 {
+			return
+		}
+		layout = SplitSideBySide
+		if i == 1 {
+			layout = SplitStacked
+		}
+	}()
+	if layout == "" {
+		return
+	}
+	if err := w.runSplit(picked, layout); err != nil {
+		util.Default.Printf("❌ Split view: %v\n", err)
+		return
+	}
+	w.displayMainMenu()
+}
+
+// runSplit shows slots in layout until Alt+1 (or Alt+0) is pressed or every
+// pane's slot has closed. Alt+<slot> moves the keyboard focus to that pane.
+func (w *Watcher) runSplit(slots []int, layout string) error {
+	fd := int(os.Stdin.Fd())
+	st, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to enable raw mode: %w", err)
+	}
+	defer term.Restore(fd, st)
+
+	in, err := cancelreader.NewReader(os.Stdin)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	util.Default.Suspend()
+	defer util.Default.Resume()
+
+	v := &splitView{w: w, layout: layout, out: os.Stdout}
+	for _, slot := range slots {
+		v.panes = append(v.panes, &splitPane{slot: slot, label: w.slotLabel(slot)})
+	}
+	width, height := terminalSize()
+	v.resize(width, height)
+	for _, p := range v.panes {
+		p := p
+		if lines, err := w.ptyMgr.ScrollbackLines(p.slot); err == nil {
+			_, rows := p.vt.Size()
+			if len(lines) > rows {
+				lines = lines[len(lines)-rows:]
+			}
+			p.vt.Write([]byte(strings.Join(lines, "\r\n")))
+		}
+		slot := p.slot
+		p.vt.SetReplyFunc(func(b []byte) { _ = w.ptyMgr.writeSlotInput(slot, b) })
+		if err := w.ptyMgr.setPaneTap(p.slot, func(b []byte) {
+			p.vt.Write(b)
+			v.dirty.Store(true)
+		}); err != nil {
+			p.closed = true
+		}
+	}
+	log.Println("split view: showing slots", slots, "layout", layout)
+	io.WriteString(v.out, ansi.SetAltScreenSaveCursorMode+ansi.EraseEntireScreen)
+	defer v.close()
+
+	input := make(chan []byte)
+	go func() {
+		defer close(input)
+		buf := make([]byte, 256)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 {
+				input <- append([]byte(nil), buf[:n]...)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	defer func() {
+		in.Cancel()
+		for range input {
+		}
+	}()
+
+	ticker := time.NewTicker(splitFrameDelay)
+	defer ticker.Stop()
+	v.render()
+	for frames := 0; ; {
+		select {
+		case b, ok := <-input:
+			if !ok {
+				return nil
+			}
+			if v.handleInput(b) {
+				return nil
+			}
+		case <-ticker.C:
+			frames++
+			if frames%splitCheckFrames == 0 {
+				if cols, rows := terminalSize(); cols != v.width || rows != v.height {
+					v.resize(cols, rows)
+				}
+				if v.checkClosed() {
+					return nil
+				}
+			}
+			if v.dirty.Swap(false) || v.fullRedraw {
+				v.render()
+			}
+		}
+	}
+}
+
+// handleInput sends b to the focused pane. Chunks with Alt+digit are
+// shortcuts: Alt+1/Alt+0 leave, Alt+<slot> focuses that pane. It returns
+// true when the split view should close.
+func (v *splitView) handleInput(b []byte) bool {
+	for i := 0; i < len(b)-1; i++ {
+		if b[i] != 0x1b || b[i+1] < '0' || b[i+1] > '9' {
+			continue
+		}
+		digit := int(b[i+1] - '0')
+		if digit == 0 || digit == 1 {
+			return true
+		}
+		for idx, p := range v.panes {
+			if p.slot == digit && !p.closed {
+				v.focus = idx
+				v.fullRedraw = true
+			}
+		}
+		return false
+	}
+	p := v.panes[v.focus]
+	if p.closed {
+		return false
+	}
+	if err := v.w.ptyMgr.writeSlotInput(p.slot, b); err != nil {
+		log.Printf("split view: input to slot %d failed: %v", p.slot, err)
+	}
+	return false
+}
+
+// checkClosed marks panes whose slot has been closed and moves the focus to
+// an open one. It returns true when no pane is left.
+func (v *splitView) checkClosed() bool {
+	open := 0
+	for _, p := range v.panes {
+		if !p.closed && !v.w.ptyMgr.HasSlot(p.slot) {
+			p.closed = true
+			v.fullRedraw = true
+		}
+		if !p.closed {
+			open++
+		}
+	}
+	if open == 0 {
+		return true
+	}
+	if v.panes[v.focus].closed {
+		for i, p := range v.panes {
+			if !p.closed {
+				v.focus = i
+				break
+			}
+		}
+	}
+	return false
+}
+
+// resize lays the panes out for a width x height terminal and gives every
+// PTY its pane size.
+func (v *splitView) resize(width, height int) {
+	v.width, v.height = width, height
+	v.frame = cellbuf.NewBuffer(width, height)
+	v.prev = cellbuf.NewBuffer(width, height)
+	v.fullRedraw = true
+
+	n := len(v.panes)
+	for i, p := range v.panes {
+		if v.layout == SplitStacked {
+			// every pane: a title row, then its content
+			y0, y1 := height*i/n, height*(i+1)/n
+			p.title = cellbuf.Rect(0, y0, width, 1)
+			p.area = cellbuf.Rect(0, y0+1, width, max(y1-y0-1, 1))
+		} else {
+			// one separator column between panes
+			avail := width - (n - 1)
+			x0 := avail*i/n + i
+			x1 := avail*(i+1)/n + i
+			p.title = cellbuf.Rect(x0, 0, x1-x0, 1)
+			p.area = cellbuf.Rect(x0, 1, max(x1-x0, 1), max(height-1, 1))
+		}
+		cols, rows := p.area.Dx(), p.area.Dy()
+		if p.vt == nil {
+			p.vt = vterm.New(cols, rows)
+		} else {
+			p.vt.Resize(cols, rows)
+		}
+		if !p.closed {
+			if err := v.w.ptyMgr.SetSlotWindowSize(p.slot, cols, rows); err != nil {
+				log.Printf("split view: resize slot %d: %v", p.slot, err)
+			}
+		}
+	}
+}
+
+// render draws all panes into the frame and writes the changed lines.
+func (v *splitView) render() {
+	v.frame.Clear()
+	titleStyle := cellbuf.Style{}
+	titleStyle.Faint(true).Reverse(true)
+	focusStyle := cellbuf.Style{}
+	focusStyle.Bold(true).Reverse(true)
+	sepStyle := cellbuf.Style{}
+	sepStyle.Faint(true)
+
+	for i, p := range v.panes {
+		style, title := titleStyle, " "+p.label
+		if i == v.focus {
+			style = focusStyle
+			title = " ▶ " + p.label + "  (Alt+N focus, Alt+1 leave)"
+		}
+		if v.w.ptyMgr.IsBroadcasting(p.slot) {
+			title += " 📡"
+		}
+		if p.closed {
+			title += " [closed]"
+		}
+		v.drawText(p.title, title, style)
+		p.vt.Draw(v.frame, p.area)
+		if v.layout == SplitSideBySide && i > 0 {
+			x := p.title.Min.X - 1
+			for y := 0; y < v.height; y++ {
+				v.frame.SetCell(x, y, &cellbuf.Cell{Rune: '│', Width: 1, Style: sepStyle})
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(ansi.HideCursor)
+	if v.fullRedraw {
+		sb.WriteString(ansi.ResetStyle + ansi.EraseEntireScreen)
+	}
+	for y := 0; y < v.height; y++ {
+		line := v.frame.Line(y)
+		if !v.fullRedraw && linesEqual(line, v.prev.Line(y)) {
+			continue
+		}
+		sb.WriteString(ansi.CursorPosition(1, y+1))
+		writeCells(&sb, line)
+		v.prev.Lines[y] = append(cellbuf.Line(nil), line...)
+	}
+	v.fullRedraw = false
+
+	p := v.panes[v.focus]
+	if app := p.vt.AppCursorKeys(); app != v.appCursor {
+		v.appCursor = app
+		sb.WriteString(modeSeq(1, app))
+	}
+	if paste := p.vt.BracketedPaste(); paste != v.paste {
+		v.paste = paste
+		sb.WriteString(modeSeq(2004, paste))
+	}
+	x, y, visible := p.vt.Cursor()
+	sb.WriteString(ansi.CursorPosition(p.area.Min.X+x+1, p.area.Min.Y+y+1))
+	v.cursorShown = visible && !p.closed
+	if v.cursorShown {
+		sb.WriteString(ansi.ShowCursor)
+	}
+	io.WriteString(v.out, sb.String())
+}
+
+// drawText writes s into the one-row rect r with style, padding the rest.
+func (v *splitView) drawText(r cellbuf.Rectangle, s string, style cellbuf.Style) {
+	x := r.Min.X
+	for _, ch := range s {
+		w := runewidth.RuneWidth(ch)
+		if w == 0 || x+w > r.Max.X {
+			continue
+		}
+		v.frame.SetCell(x, r.Min.Y, &cellbuf.Cell{Rune: ch, Width: w, Style: style})
+		x += w
+	}
+	for ; x < r.Max.X; x++ {
+		v.frame.SetCell(x, r.Min.Y, &cellbuf.Cell{Rune: ' ', Width: 1, Style: style})
+	}
+}
+
+// close gives the PTYs their full size back and restores the terminal.
+func (v *splitView) close() {
+	for _, p := range v.panes {
+		if p.closed {
+			continue
+		}
+		_ = v.w.ptyMgr.setPaneTap(p.slot, nil)
+		if err := v.w.ptyMgr.SetSlotWindowSize(p.slot, 0, 0); err != nil {
+			log.Printf("split view: restore size of slot %d: %v", p.slot, err)
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(ansi.ResetStyle)
+	if v.appCursor {
+		sb.WriteString(modeSeq(1, false))
+	}
+	if v.paste {
+		sb.WriteString(modeSeq(2004, false))
+	}
+	sb.WriteString(ansi.ResetAltScreenSaveCursorMode + ansi.ShowCursor)
+	io.WriteString(v.out, sb.String())
+	log.Println("split view: closed")
+}
+
+func modeSeq(mode int, on bool) string {
+	if on {
+		return fmt.Sprintf("\x1b[?%dh", mode)
+	}
+	return fmt.Sprintf("\x1b[?%dl", mode)
+}
+
+func cellOrBlank(c *cellbuf.Cell) *cellbuf.Cell {
+	if c == nil {
+		return &cellbuf.BlankCell
+	}
+	return c
+}
+
+func linesEqual(a, b cellbuf.Line) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !cellOrBlank(a[i]).Equal(cellOrBlank(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeCells writes a frame line with its styles and hyperlinks.
+func writeCells(sb *strings.Builder, line cellbuf.Line) {
+	var pen cellbuf.Style
+	var link cellbuf.Link
+	for x := 0; x < len(line); x++ {
+		c := cellOrBlank(line[x])
+		if c.Width == 0 && c.Rune == 0 {
+			continue // wide cell placeholder
+		}
+		if !c.Style.Equal(&pen) {
+			sb.WriteString(ansi.ResetStyle + c.Style.Sequence())
+			pen = c.Style
+		}
+		if !c.Link.Equal(&link) {
+			if c.Link.Empty() {
+				sb.WriteString(ansi.ResetHyperlink())
+			} else {
+				sb.WriteString(ansi.SetHyperlink(c.Link.URL, c.Link.Params))
+			}
+			link = c.Link
+		}
+		sb.WriteString(c.String())
+	}
+	if !link.Empty() {
+		sb.WriteString(ansi.ResetHyperlink())
+	}
+	if !pen.Empty() {
+		sb.WriteString(ansi.ResetStyle)
+	}
+}
@@ -0,0 +1,250 @@
+package devsync
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"make-sync/internal/config"
+	"make-sync/internal/util"
+)
+
+// exitMarker is printed by a supervised session command when it exits,
+// followed by the exit code. Slot output is watched for it because the slot
+// itself keeps running a shell after the command.
+const exitMarker = "[make-sync] exit code "
+
+var exitMarkerPattern = regexp.MustCompile(`^` + regexp.QuoteMeta(exitMarker) + `(\d+)$`)
+
+// restartStableAfter resets the restart count of a command that ran at
+// least this long before exiting.
+const restartStableAfter = time.Minute
+
+// sigintExitCode is the exit code of a command stopped with Ctrl+C; it is
+// never restarted.
+const sigintExitCode = 130
+
+// slotSupervisor applies the restart policy of a devsync.sessions entry.
+type slotSupervisor struct {
+	mu       sync.Mutex
+	spec     config.SessionSpec
+	started  time.Time // start of the current run
+	restarts int       // restarts in a row
+	exitCode int
+	exited   bool
+	gaveUp   bool
+	stopped  bool // slot closed
+	timer    *time.Timer
+}
+
+// supervisedCommand makes command print the exit marker when it exits
+// (POSIX shells). The subshell keeps an "exit" in command from skipping the
+// marker; the newline keeps a trailing comment from swallowing it.
+func supervisedCommand(command string) string {
+	return "(" + command + "\n); printf '\\n" + exitMarker + "%d\\n' \"$?\""
+}
+
+// parseExitMarker returns the exit code of an exit marker line.
+func parseExitMarker(line string) (int, bool) {
+	m := exitMarkerPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return 0, false
+	}
+	code, err := strconv.Atoi(m[1])
+	return code, err == nil
+}
+
+// supervised reports whether the command of spec runs under its restart
+// policy. The exit marker needs a POSIX shell, so Windows slots are not
+// supervised.
+func (w *Watcher) supervised(spec config.SessionSpec) bool {
+	if spec.RestartPolicy() == config.RestartNever || strings.TrimSpace(spec.Command) == "" {
+		return false
+	}
+	if w.sideIsWindows(spec.IsLocal()) {
+		util.Default.Printf("⚠️  Session %q: restart policy needs a POSIX shell, not supported on Windows — running unsupervised\n", spec.Label())
+		return false
+	}
+	return true
+}
+
+// sessionRerunLine is what gets typed into the shell of spec's slot to run
+// its command again.
+func (w *Watcher) sessionRerunLine(spec config.SessionSpec) string {
+	if w.ptyMgr.slotSupervisorOf(spec.Slot) == nil {
+		return spec.Command
+	}
+	return fmt.Sprintf("cd %s && bash -c %s", shellEscape(w.sessionDir(spec)), shellEscape(supervisedCommand(spec.Command)))
+}
+
+// What happens after a supervised command exited, see slotSupervisor.onExit.
+const (
+	exitIgnored = iota // slot closed, or gave up already
+	exitStays          // the policy does not restart it
+	exitRestarts
+	exitGivesUp // crash loop
+)
+
+// onExit records that the command exited with code at now and decides what
+// happens next. For exitRestarts it counts the restart and returns its
+// backoff delay. The caller holds sup.mu.
+func (sup *slotSupervisor) onExit(code int, now time.Time) (int, time.Duration) {
+	if sup.gaveUp || sup.stopped {
+		return exitIgnored, 0
+	}
+	spec := sup.spec
+	policy := spec.RestartPolicy()
+	sup.exited, sup.exitCode = true, code
+	if now.Sub(sup.started) >= restartStableAfter {
+		sup.restarts = 0
+	}
+	restart := code != sigintExitCode && (policy == config.RestartAlways || (policy == config.RestartOnFailure && code != 0))
+	if !restart {
+		return exitStays, 0
+	}
+	if sup.restarts >= spec.MaxRestartCount() {
+		sup.gaveUp = true
+		return exitGivesUp, 0
+	}
+	delay := spec.RestartDelay(sup.restarts)
+	sup.restarts++
+	return exitRestarts, delay
+}
+
+// sessionExited applies the restart policy after the supervised command of
+// slot exited with code.
+func (w *Watcher) sessionExited(slot int, sup *slotSupervisor, code int) {
+	label := w.slotLabel(slot)
+	spec := sup.spec
+
+	sup.mu.Lock()
+	action, delay := sup.onExit(code, time.Now())
+	switch action {
+	case exitIgnored:
+		sup.mu.Unlock()
+		return
+	case exitStays:
+		sup.mu.Unlock()
+		log.Printf("supervisor: %s exited with code %d, not restarted (%s)", label, code, spec.RestartPolicy())
+		w.emitControlEvent("session_exited", "", fmt.Sprintf("%s: exit code %d", label, code))
+		flashStatus(fmt.Sprintf("⏹️  %s: %s exited with code %d", label, spec.Command, code))
+		return
+	case exitGivesUp:
+		restarts := sup.restarts
+		sup.mu.Unlock()
+		log.Printf("supervisor: %s crash loop, gave up after %d restarts (exit code %d)", label, restarts, code)
+		w.emitControlEvent("session_crash_loop", "", fmt.Sprintf("%s: gave up after %d restarts, exit code %d", label, restarts, code))
+		util.Default.PrintBlock(strings.Join([]string{
+			"🛑 ================================================================",
+			fmt.Sprintf("🛑 %s: %q keeps exiting (last exit code %d)", label, spec.Command, code),
+			fmt.Sprintf("🛑 Gave up after %d restarts in a row. Fix it, then close the slot", restarts),
+			fmt.Sprintf("🛑 (Alt+%d → Exit) and reopen it with Alt+%d to supervise it again.", slot, slot),
+			"🛑 ================================================================",
+		}, "\n"), true)
+		return
+	}
+	n := sup.restarts
+	sup.timer = time.AfterFunc(delay, func() { w.restartSupervised(slot, sup) })
+	sup.mu.Unlock()
+
+	log.Printf("supervisor: %s exited with code %d, restart %d/%d in %s", label, code, n, spec.MaxRestartCount(), delay)
+	w.emitControlEvent("session_restart", "", fmt.Sprintf("%s: exit code %d, restart %d/%d", label, code, n, spec.MaxRestartCount()))
+	flashStatus(fmt.Sprintf("🔁 %s: exited with code %d — restarting in %s (%d/%d)", label, code, delay, n, spec.MaxRestartCount()))
+}
+
+// restartSupervised runs the command of a supervised slot again, unless the
+// slot was closed meanwhile.
+func (w *Watcher) restartSupervised(slot int, sup *slotSupervisor) {
+	w.ptyMgr.mu.RLock()
+	s, ok := w.ptyMgr.sessions[slot]
+	w.ptyMgr.mu.RUnlock()
+	if !ok || s == nil {
+		return
+	}
+	s.tapMu.Lock()
+	current := s.supervisor
+	s.tapMu.Unlock()
+	if current != sup {
+		return
+	}
+	sup.mu.Lock()
+	if sup.stopped {
+		sup.mu.Unlock()
+		return
+	}
+	sup.started = time.Now()
+	sup.timer = nil
+	sup.mu.Unlock()
+	if err := w.ptyMgr.rerunCommand(slot, w.sessionRerunLine(sup.spec)); err != nil {
+		util.Default.Printf("❌ Failed to restart %s: %v\n", w.slotLabel(slot), err)
+	}
+}
+
+// stopSupervisor cancels a pending restart of s.
+func (m *PTYManager) stopSupervisor(s *PTYSession) {
+	s.tapMu.Lock()
+	sup := s.supervisor
+	s.supervisor = nil
+	s.tapMu.Unlock()
+	if sup == nil {
+		return
+	}
+	sup.mu.Lock()
+	if sup.timer != nil {
+		sup.timer.Stop()
+		sup.timer = nil
+	}
+	sup.stopped = true
+	sup.mu.Unlock()
+}
+
+// slotSupervisorOf returns the supervisor of slot, or nil.
+func (m *PTYManager) slotSupervisorOf(slot int) *slotSupervisor {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil {
+		return nil
+	}
+	s.tapMu.Lock()
+	defer s.tapMu.Unlock()
+	return s.supervisor
+}
+
+// supervisorState describes the last exit and the restarts of slot for the
+// main menu; empty when the slot is not supervised or has not exited yet.
+func (m *PTYManager) supervisorState(slot int) string {
+	sup := m.slotSupervisorOf(slot)
+	if sup == nil {
+		return ""
+	}
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	switch {
+	case sup.gaveUp:
+		return fmt.Sprintf("🛑 crash loop, exit %d after %d restarts", sup.exitCode, sup.restarts)
+	case sup.exited:
+		return fmt.Sprintf("exit %d, %d restarts", sup.exitCode, sup.restarts)
+	}
+	return ""
+}
+
+// fillSupervisorStatus adds the restart policy state of slot to st.
+func (m *PTYManager) fillSupervisorStatus(slot int, st *SlotStatus) {
+	sup := m.slotSupervisorOf(slot)
+	if sup == nil {
+		return
+	}
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	if sup.exited {
+		code := sup.exitCode
+		st.ExitCode = &code
+	}
+	st.Restarts = sup.restarts
+	st.GaveUp = sup.gaveUp
+}
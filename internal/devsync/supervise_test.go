@@ -0,0 +1,102 @@
+package devsync
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"make-sync/internal/config"
+)
+
+func TestParseExitMarker(t *testing.T) {
+	tests := []struct {
+		line string
+		code int
+		ok   bool
+	}{
+		{"[make-sync] exit code 0", 0, true},
+		{"  [make-sync] exit code 137 ", 137, true},
+		{"[make-sync] exit code", 0, false},
+		{"[make-sync] exit code 1x", 0, false},
+		{"echo [make-sync] exit code 1", 0, false},
+	}
+	for _, tt := range tests {
+		code, ok := parseExitMarker(tt.line)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("%q: got %d %v, want %d %v", tt.line, code, ok, tt.code, tt.ok)
+		}
+	}
+}
+
+func TestSupervisedCommandPrintsExitCode(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	for cmd, want := range map[string]int{
+		"true":                0,
+		"exit 3":              3,
+		"echo hi # a comment": 0,
+		"sh -c 'exit 7'":      7,
+	} {
+		out, _ := exec.Command("bash", "-c", supervisedCommand(cmd)).Output()
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		code, ok := parseExitMarker(lines[len(lines)-1])
+		if !ok || code != want {
+			t.Errorf("%q: last line %q, want exit code %d", cmd, lines[len(lines)-1], want)
+		}
+	}
+}
+
+func TestSupervisorOnExit(t *testing.T) {
+	now := time.Now()
+	spec := func(policy string) config.SessionSpec {
+		return config.SessionSpec{Command: "make serve", Restart: policy, MaxRestarts: 2, RestartBackoff: 100}
+	}
+	tests := []struct {
+		name   string
+		policy string
+		code   int
+		want   int
+	}{
+		{"on-failure, failed", config.RestartOnFailure, 1, exitRestarts},
+		{"on-failure, succeeded", config.RestartOnFailure, 0, exitStays},
+		{"always, succeeded", config.RestartAlways, 0, exitRestarts},
+		{"always, Ctrl+C", config.RestartAlways, sigintExitCode, exitStays},
+	}
+	for _, tt := range tests {
+		sup := &slotSupervisor{spec: spec(tt.policy), started: now}
+		if got, _ := sup.onExit(tt.code, now); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+		if !sup.exited || sup.exitCode != tt.code {
+			t.Errorf("%s: exit not recorded", tt.name)
+		}
+	}
+
+	// crash loop: backoff doubles, then it gives up after MaxRestarts
+	sup := &slotSupervisor{spec: spec(config.RestartAlways), started: now}
+	for i, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond} {
+		action, delay := sup.onExit(1, now)
+		if action != exitRestarts || delay != want {
+			t.Fatalf("restart %d: got %d after %s, want restart after %s", i+1, action, delay, want)
+		}
+	}
+	if action, _ := sup.onExit(1, now); action != exitGivesUp || !sup.gaveUp {
+		t.Fatalf("third exit: got %d, want crash loop", action)
+	}
+	if action, _ := sup.onExit(1, now); action != exitIgnored {
+		t.Fatalf("exit after giving up: got %d, want ignored", action)
+	}
+
+	// a run longer than restartStableAfter resets the count
+	sup = &slotSupervisor{spec: spec(config.RestartAlways), started: now, restarts: 2}
+	if action, delay := sup.onExit(1, now.Add(restartStableAfter)); action != exitRestarts || delay != 100*time.Millisecond {
+		t.Fatalf("stable run: got %d after %s, want first restart", action, delay)
+	}
+
+	sup = &slotSupervisor{spec: spec(config.RestartAlways), started: now, stopped: true}
+	if action, _ := sup.onExit(1, now); action != exitIgnored {
+		t.Fatalf("closed slot: got %d, want ignored", action)
+	}
+}
@@ -0,0 +1,453 @@
+package devsync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"make-sync/internal/config"
+	"make-sync/internal/deployagent"
+	"make-sync/internal/sshclient"
+	"make-sync/internal/util"
+)
+
+// Target states reported in status output.
+const (
+	targetConnecting = "connecting"
+	targetOK         = "ok"
+	targetRetrying   = "retrying"
+	targetOffline    = "offline"
+)
+
+// targetRetryMin and targetRetryMax bound the backoff between retry rounds.
+const (
+	targetRetryMin = 5 * time.Second
+	targetRetryMax = 2 * time.Minute
+)
+
+// TargetStatus describes one extra devsync target in status output.
+type TargetStatus struct {
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	Synced    int    `json:"synced"`
+	Failed    int    `json:"failed"`
+	Retrying  int    `json:"retrying"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// targetSync replicates the events of the upload queue to one entry of
+// devsync.targets over its own SSH connection, with its own agent and retry
+// queue. Remote changes are only picked up from devsync.auth.
+type targetSync struct {
+	w      *Watcher
+	target config.Target
+	cfg    *config.Config
+	queue  chan FileEvent
+
+	mu      sync.Mutex
+	client  *sshclient.SSHClient
+	state   string
+	lastErr string
+	synced  int
+	failed  int
+	// retry holds failed events keyed by local path; a newer event for the
+	// same path replaces it
+	retry map[string]FileEvent
+}
+
+// startTargets connects every entry of devsync.targets in the background.
+func (w *Watcher) startTargets() {
+	targets := w.config.SyncTargets()[1:]
+	if len(targets) == 0 {
+		return
+	}
+	w.targetsMu.Lock()
+	defer w.targetsMu.Unlock()
+	for _, t := range targets {
+		ts := &targetSync{
+			w:      w,
+			target: t,
+			cfg:    w.config.ForTarget(t),
+			queue:  make(chan FileEvent, 1024),
+			state:  targetConnecting,
+			retry:  make(map[string]FileEvent),
+		}
+		w.targets = append(w.targets, ts)
+		go ts.run()
+	}
+}
+
+// fanOutEvent hands a queued event or a live delete to every target.
+func (w *Watcher) fanOutEvent(ev FileEvent) {
+	if w.isMuted(ev.Path) {
+		return
+	}
+	w.targetsMu.Lock()
+	targets := w.targets
+	w.targetsMu.Unlock()
+	for _, ts := range targets {
+		select {
+		case ts.queue <- ev:
+		default:
+			// queue full: keep the event for the next retry round
+			ts.mu.Lock()
+			ts.retry[ev.Path] = ev
+			ts.mu.Unlock()
+		}
+	}
+}
+
+// targetStatuses snapshots the state of every target.
+func (w *Watcher) targetStatuses() []TargetStatus {
+	w.targetsMu.Lock()
+	targets := w.targets
+	w.targetsMu.Unlock()
+	out := make([]TargetStatus, 0, len(targets))
+	for _, ts := range targets {
+		ts.mu.Lock()
+		out = append(out, TargetStatus{
+			Name:      ts.target.Label(),
+			State:     ts.state,
+			Synced:    ts.synced,
+			Failed:    ts.failed,
+			Retrying:  len(ts.retry),
+			LastError: ts.lastErr,
+		})
+		ts.mu.Unlock()
+	}
+	return out
+}
+
+// closeTargets closes the connections of every target.
+func (w *Watcher) closeTargets() {
+	w.targetsMu.Lock()
+	targets := w.targets
+	w.targetsMu.Unlock()
+	for _, ts := range targets {
+		ts.mu.Lock()
+		if ts.client != nil {
+			_ = ts.client.Close()
+			ts.client = nil
+		}
+		ts.mu.Unlock()
+	}
+}
+
+func (ts *targetSync) setState(state string, err error) {
+	ts.mu.Lock()
+	ts.state = state
+	if err != nil {
+		ts.lastErr = err.Error()
+	}
+	ts.mu.Unlock()
+}
+
+// run connects the target, deploys its agent and then applies queued events,
+// retrying failed ones with backoff until the watcher shuts down.
+func (ts *targetSync) run() {
+	label := ts.target.Label()
+	backoff := targetRetryMin
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	connected := false
+
+	for {
+		select {
+		case <-ts.w.ctx.Done():
+			return
+		case ev := <-ts.queue:
+			if !connected {
+				ts.keepForRetry(ev, nil)
+				continue
+			}
+			ts.mu.Lock()
+			delete(ts.retry, ev.Path)
+			if ev.OldPath != "" {
+				delete(ts.retry, ev.OldPath)
+			}
+			ts.mu.Unlock()
+			if err := ts.apply(ev); err != nil {
+				ts.w.safePrintf("❌ Target %s: %v\n", label, err)
+				ts.keepForRetry(ev, err)
+				ts.w.emitControlEvent("target_failed", ev.Path, label+": "+err.Error())
+			}
+		case <-timer.C:
+			if !connected {
+				if err := ts.connect(); err != nil {
+					ts.setState(targetOffline, err)
+					ts.w.safePrintf("⚠️  Target %s offline, retrying in %s: %v\n", label, backoff, err)
+					timer.Reset(backoff)
+					backoff = nextTargetBackoff(backoff)
+					continue
+				}
+				connected = true
+				ts.setState(targetOK, nil)
+				ts.w.safePrintf("🎯 Target %s connected\n", label)
+			}
+			if ts.retryPending() {
+				backoff = nextTargetBackoff(backoff)
+			} else {
+				backoff = targetRetryMin
+			}
+			timer.Reset(backoff)
+		}
+	}
+}
+
+func nextTargetBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > targetRetryMax {
+		return targetRetryMax
+	}
+	return d
+}
+
+// connect opens the SSH connection of the target and deploys its agent.
+func (ts *targetSync) connect() error {
+	client, err := createSSHClient(ts.cfg)
+	if err != nil {
+		return err
+	}
+	ts.mu.Lock()
+	ts.client = client
+	ts.mu.Unlock()
+
+	projectRoot, err := util.GetProjectRoot()
+	if err != nil {
+		return fmt.Errorf("failed to detect project root: %v", err)
+	}
+	ts.w.deployMappingAgents(ts.cfg, client, projectRoot)
+	deployOpts := deployagent.UnifiedDeployOptions{
+		ProjectRoot:    projectRoot,
+		TargetOS:       ts.cfg.Devsync.OSTarget,
+		Config:         ts.cfg,
+		SSHClient:      client,
+		BuildIfMissing: true,
+		UploadAgent:    true,
+		UploadConfig:   true,
+	}
+	if _, err := deployagent.DeployAgentAndConfig(deployOpts); err != nil {
+		ts.w.safePrintf("⚠️  Failed to deploy agent to target %s: %v\n", ts.target.Label(), err)
+	}
+	return nil
+}
+
+func (ts *targetSync) keepForRetry(ev FileEvent, err error) {
+	ts.mu.Lock()
+	ts.retry[ev.Path] = ev
+	if err != nil {
+		ts.failed++
+		ts.lastErr = err.Error()
+		ts.state = targetRetrying
+	}
+	ts.mu.Unlock()
+}
+
+// retryPending replays the retry queue, reconnecting first when the
+// connection dropped. It reports whether events are still waiting.
+func (ts *targetSync) retryPending() bool {
+	ts.mu.Lock()
+	pending := make([]FileEvent, 0, len(ts.retry))
+	for _, ev := range ts.retry {
+		pending = append(pending, ev)
+	}
+	ts.retry = make(map[string]FileEvent)
+	client := ts.client
+	ts.mu.Unlock()
+	if len(pending) == 0 {
+		return false
+	}
+	if client == nil {
+		for _, ev := range pending {
+			ts.keepForRetry(ev, nil)
+		}
+		return true
+	}
+
+	label := ts.target.Label()
+	if err := client.RunCommand("echo ok"); err != nil {
+		if rerr := client.Reconnect(); rerr != nil {
+			for _, ev := range pending {
+				ts.keepForRetry(ev, nil)
+			}
+			ts.setState(targetOffline, rerr)
+			ts.w.safePrintf("⚠️  Target %s still offline: %v\n", label, rerr)
+			return true
+		}
+	}
+
+	ts.w.safePrintf("🔁 Target %s: retrying %d change(s)\n", label, len(pending))
+	left := 0
+	for _, ev := range pending {
+		if err := ts.apply(ev); err != nil {
+			ts.keepForRetry(ev, err)
+			left++
+		}
+	}
+	if left == 0 {
+		ts.setState(targetOK, nil)
+		return false
+	}
+	return true
+}
+
+// apply performs ev on the target.
+func (ts *targetSync) apply(ev FileEvent) error {
+	ts.mu.Lock()
+	client := ts.client
+	ts.mu.Unlock()
+	if client == nil {
+		return fmt.Errorf("not connected")
+	}
+	pm := ts.cfg.PathMap()
+	targetOS := ts.cfg.Devsync.OSTarget
+
+	switch ev.EventType {
+	case EventRemove:
+		remote, err := pm.LocalToRemote(ev.Path)
+		if err != nil || strings.Contains(remote, ".sync_temp") {
+			return nil
+		}
+		// the path is gone locally, so it may have been a tree
+		if err := client.RunCommand(remoteDeleteCommand(targetOS, remote, true)); err != nil {
+			return fmt.Errorf("failed to delete %s: %v", remote, err)
+		}
+		ts.done()
+		ts.triggerHooks("delete_remote", ev.Path)
+		return nil
+
+	case EventRename:
+		if ev.OldPath != "" {
+			oldRemote, oerr := pm.LocalToRemote(ev.OldPath)
+			newRemote, nerr := pm.LocalToRemote(ev.Path)
+			if oerr == nil && nerr == nil {
+				if err := client.RunCommand(remoteMoveCommand(targetOS, oldRemote, newRemote)); err == nil {
+					ts.done()
+					ts.triggerHooks("move_remote", ev.Path)
+					return nil
+				}
+				defer func() { _ = client.RunCommand(remoteDeleteCommand(targetOS, oldRemote, true)) }()
+			}
+		}
+		fallthrough
+
+	default:
+		info, err := os.Stat(ev.Path)
+		if err != nil {
+			// gone again before it reached this target
+			return nil
+		}
+		if !info.IsDir() {
+			return ts.upload(client, pm, ev.Path)
+		}
+		var firstErr error
+		_ = filepath.Walk(ev.Path, func(p string, fi os.FileInfo, werr error) error {
+			if werr != nil {
+				return nil
+			}
+			if ts.w.shouldIgnore(p) {
+				if fi.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if fi.IsDir() {
+				return nil
+			}
+			if err := ts.upload(client, pm, p); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			return nil
+		})
+		return firstErr
+	}
+}
+
+// upload copies one local file to the target, creating its remote directory.
+func (ts *targetSync) upload(client *sshclient.SSHClient, pm util.PathMap, localPath string) error {
+	remote, err := pm.LocalToRemote(localPath)
+	if err != nil {
+		return nil
+	}
+	targetOS := ts.cfg.Devsync.OSTarget
+	if strings.Contains(strings.ToLower(targetOS), "win") {
+		remote = strings.ReplaceAll(remote, "/", "\\")
+		if idx := strings.LastIndex(remote, "\\"); idx > 0 {
+			dir := remote[:idx]
+			_ = client.RunCommand(fmt.Sprintf("cmd.exe /C if not exist \"%s\" mkdir \"%s\"", dir, dir))
+		}
+	} else if err := client.RunCommand(fmt.Sprintf("mkdir -p '%s'", path.Dir(remote))); err != nil {
+		return fmt.Errorf("failed to create remote directory for %s: %v", remote, err)
+	}
+	if err := client.UploadFileSCP(localPath, remote); err != nil {
+		return fmt.Errorf("failed to upload %s: %v", remote, err)
+	}
+	util.Default.Printf("🎯 %s: %s → %s\n", ts.target.Label(), localPath, remote)
+	util.Default.ClearLine()
+	ts.done()
+	ts.triggerHooks("upload", localPath)
+	return nil
+}
+
+func (ts *targetSync) done() {
+	ts.mu.Lock()
+	ts.synced++
+	if len(ts.retry) == 0 {
+		ts.state = targetOK
+	}
+	ts.mu.Unlock()
+}
+
+// triggerHooks fires the remote devsync.hooks matching an event synced to the
+// target. Local hooks only run for the event on devsync.auth.
+func (ts *targetSync) triggerHooks(event, localPath string) {
+	w := ts.w
+	w.configMu.RLock()
+	cfg := w.config
+	w.configMu.RUnlock()
+	if cfg == nil || len(cfg.Devsync.Hooks) == 0 {
+		return
+	}
+	rel, err := filepath.Rel(w.watchPath, localPath)
+	if err != nil {
+		return
+	}
+	rel = filepath.ToSlash(rel)
+	remote, err := ts.cfg.PathMap().LocalToRemote(localPath)
+	if err != nil {
+		return
+	}
+	if strings.Contains(strings.ToLower(ts.cfg.Devsync.OSTarget), "win") {
+		remote = strings.ReplaceAll(remote, "/", "\\")
+	}
+	inv := hookInvocation{event: event, local: localPath, remote: remote, rel: rel, target: ts}
+
+	for _, h := range cfg.Devsync.Hooks {
+		if strings.EqualFold(strings.TrimSpace(h.Side), "local") {
+			continue
+		}
+		if !hookWantsEvent(h, event) || !config.MatchTriggerPattern(h.Glob, rel) {
+			continue
+		}
+		w.fireHook(h, inv)
+	}
+}
+
+// runCommand runs a hook command on the target from the remote root of the
+// mapping owning localPath and returns its combined output.
+func (ts *targetSync) runCommand(cmd, localPath string) (string, error) {
+	ts.mu.Lock()
+	client := ts.client
+	ts.mu.Unlock()
+	if client == nil {
+		return "", fmt.Errorf("target %s not connected", ts.target.Label())
+	}
+	var stdout, stderr bytes.Buffer
+	err := client.RunCommandWithIO(remoteHookCommand(ts.cfg, cmd, localPath), nil, &stdout, &stderr)
+	return stdout.String() + stderr.String(), err
+}
@@ -0,0 +1,235 @@
+package devsync
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"make-sync/internal/config"
+	"make-sync/internal/util"
+)
+
+// maxTriggerLine caps a line that never ends (progress output); it is
+// matched and dropped at this length.
+const maxTriggerLine = 4096
+
+// restartInterruptDelay gives the slot time to handle Ctrl+C before the
+// session command is typed again.
+const restartInterruptDelay = 300 * time.Millisecond
+
+// flashDuration is how long a flash message stays on the bottom line.
+const flashDuration = 3 * time.Second
+
+type outputTrigger struct {
+	spec config.SessionTrigger
+	re   *regexp.Regexp
+	last time.Time
+}
+
+// outputTriggers matches the output of one slot line by line against its
+// devsync.sessions triggers and the exit marker of supervised commands.
+// Escape sequences are stripped statefully, so both they and lines may span
+// chunks.
+type outputTriggers struct {
+	mu    sync.Mutex
+	strip *util.ANSIStripper
+	line  []byte
+	rules []*outputTrigger
+	fire  func(t config.SessionTrigger, line string)
+	// onExit receives the exit code of a supervised command, see supervise.go
+	onExit func(code int)
+}
+
+func newOutputTriggers(specs []config.SessionTrigger, fire func(config.SessionTrigger, string)) *outputTriggers {
+	ot := &outputTriggers{strip: util.NewANSIStripper(), fire: fire}
+	for _, spec := range specs {
+		re, err := regexp.Compile(spec.Pattern)
+		if err != nil {
+			// rejected by config validation already
+			continue
+		}
+		ot.rules = append(ot.rules, &outputTrigger{spec: spec, re: re})
+	}
+	return ot
+}
+
+// Write feeds a chunk of PTY output.
+func (ot *outputTriggers) Write(b []byte) {
+	type hit struct {
+		spec config.SessionTrigger
+		line string
+	}
+	var hits []hit
+	var exits []int
+	ot.mu.Lock()
+	matchLine := func() {
+		if len(ot.line) == 0 {
+			return
+		}
+		line := string(ot.line)
+		ot.line = ot.line[:0]
+		if ot.onExit != nil {
+			if code, ok := parseExitMarker(line); ok {
+				exits = append(exits, code)
+				return
+			}
+		}
+		now := time.Now()
+		for _, r := range ot.rules {
+			if !r.re.MatchString(line) {
+				continue
+			}
+			if cd := r.spec.CooldownDuration(); cd > 0 && now.Sub(r.last) < cd {
+				continue
+			}
+			r.last = now
+			hits = append(hits, hit{r.spec, strings.TrimSpace(line)})
+		}
+	}
+	for _, c := range ot.strip.Strip(b) {
+		if c == '\n' || c == '\r' {
+			matchLine()
+			continue
+		}
+		ot.line = append(ot.line, c)
+		if len(ot.line) >= maxTriggerLine {
+			matchLine()
+		}
+	}
+	ot.mu.Unlock()
+	for _, h := range hits {
+		ot.fire(h.spec, h.line)
+	}
+	for _, code := range exits {
+		ot.onExit(code)
+	}
+}
+
+// watchSessionOutput watches the output of slot for the triggers of spec
+// and, when supervised, for the exit of its command.
+func (m *PTYManager) watchSessionOutput(slot int, spec config.SessionSpec, supervised bool) error {
+	if len(spec.Triggers) == 0 && !supervised {
+		return nil
+	}
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil {
+		return fmt.Errorf("no session in slot %d", slot)
+	}
+	w := m.w
+	ot := newOutputTriggers(spec.Triggers, func(t config.SessionTrigger, line string) {
+		go w.runTrigger(slot, spec, t, line)
+	})
+	var sup *slotSupervisor
+	if supervised {
+		sup = &slotSupervisor{spec: spec, started: time.Now()}
+		ot.onExit = func(code int) {
+			go w.sessionExited(slot, sup, code)
+		}
+	}
+	s.tapMu.Lock()
+	s.triggers = ot
+	s.supervisor = sup
+	s.tapMu.Unlock()
+	return m.installTap(s)
+}
+
+// runTrigger performs the action of t for a line of slot's output.
+func (w *Watcher) runTrigger(slot int, spec config.SessionSpec, t config.SessionTrigger, line string) {
+	label := w.slotLabel(slot)
+	msg := t.Message
+	if msg == "" {
+		msg = line
+	}
+	action := strings.ToLower(strings.TrimSpace(t.Action))
+	log.Printf("trigger: %s matched %q (%s): %s", label, t.Pattern, action, line)
+	w.emitControlEvent("trigger", "", fmt.Sprintf("%s %s: %s", label, action, line))
+
+	switch action {
+	case config.TriggerNotify:
+		title := "make-sync " + label
+		// BEL, then OSC 9 (iTerm2, Windows Terminal, kitty) and OSC 777
+		// (urxvt, foot, VTE); terminals ignore the ones they do not know
+		util.Default.Print(fmt.Sprintf("\a\x1b]9;%s: %s\x07\x1b]777;notify;%s;%s\x07",
+			title, oscSafe(msg), oscSafe(title), oscSafe(msg)))
+	case config.TriggerFlash:
+		flashStatus(fmt.Sprintf("⚡ %s: %s", label, msg))
+	case config.TriggerLocal, config.TriggerRemote:
+		local := action == config.TriggerLocal
+		cmd := w.renderTriggerCommand(t.Command, slot, line, local)
+		out, err := w.runSideCommand(local, cmd, "")
+		log.Printf("trigger: %s command %q output: %s", label, cmd, out)
+		if err != nil {
+			w.safePrintf("❌ Trigger on %s failed: %v\n", label, err)
+			w.emitControlEvent("trigger_failed", "", label+": "+err.Error())
+		}
+	case config.TriggerRestart:
+		if err := w.ptyMgr.rerunCommand(slot, w.sessionRerunLine(spec)); err != nil {
+			w.safePrintf("❌ Trigger on %s could not restart the command: %v\n", label, err)
+			return
+		}
+		flashStatus(fmt.Sprintf("🔁 %s: restarted after %q", label, line))
+	}
+}
+
+// renderTriggerCommand fills the {{match}} and {{slot}} placeholders of cmd.
+// The matched line is quoted for the shell the command runs in.
+func (w *Watcher) renderTriggerCommand(cmd string, slot int, line string, local bool) string {
+	return strings.NewReplacer(
+		"{{match}}", quoteForShell(line, w.sideIsWindows(local)),
+		"{{slot}}", strconv.Itoa(slot),
+	).Replace(cmd)
+}
+
+// rerunCommand interrupts whatever runs in slot and types command again in
+// the slot's shell (slot commands fall back to a shell when they exit).
+func (m *PTYManager) rerunCommand(slot int, command string) error {
+	m.mu.RLock()
+	s, ok := m.sessions[slot]
+	m.mu.RUnlock()
+	if !ok || s == nil || s.Bridge == nil {
+		return fmt.Errorf("no session in slot %d", slot)
+	}
+	if !s.started {
+		return fmt.Errorf("slot %d is not started", slot)
+	}
+	stdin := s.Bridge.GetStdinWriter()
+	if stdin == nil {
+		return fmt.Errorf("slot %d has no input", slot)
+	}
+	if _, err := stdin.Write([]byte{0x03}); err != nil {
+		return err
+	}
+	time.Sleep(restartInterruptDelay)
+	_, err := stdin.Write([]byte(command + "\r"))
+	return err
+}
+
+// flashStatus shows msg in reverse video on the bottom line of the terminal
+// for flashDuration, keeping the cursor where it is.
+func flashStatus(msg string) {
+	width, height := terminalSize()
+	msg = strings.TrimSpace(msg)
+	if r := []rune(msg); len(r) > width-1 {
+		msg = string(r[:width-1])
+	}
+	util.Default.Print(fmt.Sprintf("\x1b7\x1b[%d;1H\x1b[7m %s\x1b[K\x1b[0m\x1b8", height, msg))
+	time.AfterFunc(flashDuration, func() {
+		util.Default.Print(fmt.Sprintf("\x1b7\x1b[%d;1H\x1b[2K\x1b8", height))
+	})
+}
+
+// oscSafe drops the characters that would end or break an OSC string.
+func oscSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == ';' {
+			return ' '
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,62 @@
+package devsync
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"make-sync/internal/config"
+)
+
+func collectTriggers(specs []config.SessionTrigger) (*outputTriggers, *[]string) {
+	var got []string
+	ot := newOutputTriggers(specs, func(t config.SessionTrigger, line string) {
+		got = append(got, t.Action+": "+line)
+	})
+	return ot, &got
+}
+
+func TestOutputTriggersSplitChunks(t *testing.T) {
+	ot, got := collectTriggers([]config.SessionTrigger{
+		{Pattern: `ERROR \d+`, Action: config.TriggerFlash, Cooldown: -1},
+	})
+	for _, chunk := range []string{
+		"ok\r\nERR", "OR 4", "2 here\r", "\n\x1b[3", "1mERROR\x1b[0m 7\n", // escape split between chunks
+		"\x1b]0;ERROR 9 title\x07fine\n", // OSC is not output
+	} {
+		ot.Write([]byte(chunk))
+	}
+	want := []string{"flash: ERROR 42 here", "flash: ERROR 7"}
+	if !reflect.DeepEqual(*got, want) {
+		t.Fatalf("got %q, want %q", *got, want)
+	}
+}
+
+func TestOutputTriggersLongLineAndCooldown(t *testing.T) {
+	ot, got := collectTriggers([]config.SessionTrigger{
+		{Pattern: `^x+$`, Action: config.TriggerNotify, Cooldown: -1},
+		{Pattern: `panic`, Action: config.TriggerFlash}, // default cooldown
+	})
+	// matched once at maxTriggerLine, the rest is a line of its own
+	ot.Write([]byte(strings.Repeat("x", maxTriggerLine+10) + "\n"))
+	if len(*got) != 2 || len((*got)[0]) != len("notify: ")+maxTriggerLine || (*got)[1] != "notify: xxxxxxxxxx" {
+		t.Fatalf("long line: got %d hits", len(*got))
+	}
+
+	*got = nil
+	ot.Write([]byte("panic: a\npanic: b\n"))
+	if want := []string{"flash: panic: a"}; !reflect.DeepEqual(*got, want) {
+		t.Fatalf("cooldown: got %q, want %q", *got, want)
+	}
+}
+
+func TestOutputTriggersExitMarker(t *testing.T) {
+	ot, got := collectTriggers(nil)
+	var codes []int
+	ot.onExit = func(code int) { codes = append(codes, code) }
+	ot.Write([]byte("done\r\n[make-sync] exit "))
+	ot.Write([]byte("code 2\r\n"))
+	if !reflect.DeepEqual(codes, []int{2}) || len(*got) != 0 {
+		t.Fatalf("got exits %v, hits %q", codes, *got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os/exec"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"make-sync/internal/config"
@@ -150,8 +151,15 @@ type Watcher struct {
 	// protects access to pendingDirDeletes/pendingDirMoves
 	pendingMu sync.Mutex
 
+	// vanished holds rename-away/remove events waiting to be paired with a
+	// create as a move (see rename.go)
+	movesMu  sync.Mutex
+	vanished map[string]*vanishedPath
+
 	// Agent process tracking
 	agentPID string // PID of remote agent process
+	// agentCursor is the last acknowledged remote journal sequence (-1 = none)
+	agentCursor int64
 
 	// If true, agent stop was requested by the user (e.g. via Ctrl+R) and the
 	// monitoring goroutine should not attempt to auto-restart the agent.
@@ -163,6 +171,41 @@ type Watcher struct {
 	agentMonitoringRunning   bool
 	agentMonitoringRunningMu sync.Mutex
 
+	// Agent heartbeat tracking used to detect a hung agent (see agent_health.go)
+	agentHealthMu      sync.Mutex
+	agentLastHeartbeat time.Time
+	agentQueueDepth    int
+	agentLastEvent     string
+	agentMissedBeats   int
+	agentUnhealthy     bool
+
+	// Control API state (see control.go)
+	control       atomic.Pointer[controlServer]
+	startedAt     time.Time
+	uploadsPaused atomic.Bool // debounced events are held instead of uploaded
+	reconciling   atomic.Bool
+	lastSyncMu    sync.Mutex
+	lastSyncAt    time.Time
+	lastSyncPath  string
+
+	// mutedPaths are absolute local subtrees whose events are ignored (see pause.go)
+	mutedMu    sync.RWMutex
+	mutedPaths map[string]struct{}
+
+	// targets replicate queued events to devsync.targets (see targets.go)
+	targetsMu sync.Mutex
+	targets   []*targetSync
+
+	// persistentMux caches the remote multiplexer of persistent slots (see persistent.go)
+	persistentMu  sync.Mutex
+	persistentMux string
+	// sessionsOnce opens devsync.sessions (see sessions.go) on the first interactive run
+	sessionsOnce sync.Once
+
+	// hookRunners hold the debounce/concurrency state of devsync.hooks (see hooks.go)
+	hooksMu     sync.Mutex
+	hookRunners map[string]*hookRunner
+
 	configMu sync.RWMutex // protect reading/writing w.config or other config-derived state
 
 	// protects access to extendedIgnores and ignoreFileModTime
@@ -192,5 +235,7 @@ type RemoteAgentConfig struct {
 		AgentWatchs    []string `json:"agent_watchs"`
 		ManualTransfer []string `json:"manual_transfer"`
 		WorkingDir     string   `json:"working_dir"`
+		// HeartbeatInterval in seconds; 0 disables agent heartbeats
+		HeartbeatInterval int `json:"heartbeat_interval"`
 	} `json:"devsync"`
 }
@@ -144,6 +144,7 @@ func NewWatcherBasic(cfg *config.Config) (*Watcher, error) {
 		TUIActive:      false,
 		ctx:            ctx,
 		cancelFunc:     cancel,
+		startedAt:      time.Now(),
 		// initialize pending maps
 		pendingDirDeletes: make(map[string]struct{}),
 		pendingDirMoves:   make(map[string]struct{}),
@@ -165,6 +166,8 @@ func NewWatcherBasic(cfg *config.Config) (*Watcher, error) {
 	// start serialized processor
 	watcher.startEventQueueProcessor()
 
+	watcher.applyMappings()
+
 	return watcher, nil
 }
 
@@ -184,6 +187,9 @@ func NewWatcher(cfg *config.Config) (*Watcher, error) {
 	// kill it before starting a new agent to prevent double agents on remote.
 	watcher.handlePidSingleton()
 
+	// Local control API (`make-sync ctl ...`)
+	watcher.startControlServer()
+
 	// build & deploy
 	if err := watcher.buildAndDeployAgent(); err != nil {
 		util.Default.Printf("⚠️  Failed to build/deploy agent: %v\n", err)
@@ -194,6 +200,12 @@ func NewWatcher(cfg *config.Config) (*Watcher, error) {
 		util.Default.Printf("⚠️  Failed to sync config to remote: %v\n", err)
 	}
 
+	// extra devsync.targets connect and deploy their agents in the background
+	watcher.startTargets()
+
+	// optional startup reconcile, before the agent reports remote changes
+	watcher.startupReconcile()
+
 	// start monitoring
 	if err := watcher.startAgentMonitoring(); err != nil {
 		util.Default.Printf("⚠️  Failed to start agent monitoring: %v\n", err)
@@ -223,6 +235,18 @@ func (w *Watcher) Start() error {
 		}
 	}()
 
+	return w.run(true)
+}
+
+// StartHeadless begins watching files without raw mode, keyboard handling or
+// PTY sessions (see RunHeadless).
+func (w *Watcher) StartHeadless() error {
+	return w.run(false)
+}
+
+// run sets up file watching and blocks until the watcher stops. interactive
+// enables the keyboard handler and session completion events.
+func (w *Watcher) run(interactive bool) error {
 	// make Start idempotent for repeated UI navigation
 	w.runningMu.Lock()
 	if w.running {
@@ -263,11 +287,24 @@ func (w *Watcher) Start() error {
 	w.safePrintf("🔍 Starting file watcher on: %s\n", absWatchPath)
 	log.Printf("🔍 DEBUG: Watch directory resolved - cfg.LocalPath: '%s', final absWatchPath: '%s'", w.config.LocalPath, absWatchPath)
 	util.Default.ClearLine()
-	w.safePrintf("📋 Watch permissions - Add: %v, Change: %v, Unlink: %v, UnlinkFolder: %v\n",
+	w.safePrintf("📋 Watch permissions - Add: %v, Change: %v, Unlink: %v, UnlinkFolder: %v, Path rules: %d\n",
 		w.config.Devsync.TriggerPerm.Add,
 		w.config.Devsync.TriggerPerm.Change,
 		w.config.Devsync.TriggerPerm.Unlink,
-		w.config.Devsync.TriggerPerm.UnlinkFolder)
+		w.config.Devsync.TriggerPerm.UnlinkFolder,
+		len(w.config.Devsync.TriggerPerm.Rules))
+	if w.config.HasMappings() {
+		for _, m := range w.config.PathMappings() {
+			w.safePrintf("📁 Mapping %s: %s ⇄ %s\n", m.Label(), m.LocalPath, m.RemotePath)
+		}
+	}
+	if d := w.config.Devsync; d.SessionDirection != "" || config.NormalizeDirection(d.Direction) != config.DirectionBidirectional || len(d.DirectionRules) > 0 {
+		session := ""
+		if d.SessionDirection != "" {
+			session = ", session: " + d.SessionDirection
+		}
+		w.safePrintf("🧭 Sync direction: %s%s, path rules: %d\n", config.NormalizeDirection(d.Direction), session, len(d.DirectionRules))
+	}
 
 	// Ensure we safely initialize watchChan if needed. Accesses to watchChan
 	// race when other goroutines (StopNotify) set/close it, so take a small
@@ -313,18 +350,30 @@ func (w *Watcher) Start() error {
 		close(w.ready)
 	})
 
-	// Start keyboard input handler goroutine
-	// Start legacy keyboard handler only when TUI is not active
-	go w.handleKeyboardInput()
+	if interactive {
+		// devsync.sessions marked auto_start, then persistent slots left
+		// running by a previous run (devsync.persistent_sessions); once per
+		// process, not on every restart of the watcher
+		w.sessionsOnce.Do(func() {
+			w.autoStartSessions()
+			w.reportDetachedSessions()
+		})
+
+		// Start keyboard input handler goroutine
+		// Start legacy keyboard handler only when TUI is not active
+		go w.handleKeyboardInput()
 
-	// Start session completion event handler
-	go w.handleSessionCompletionEvents()
+		// Start session completion event handler
+		go w.handleSessionCompletionEvents()
+	}
 
 	// Start event processing goroutine
 	go w.processEvents()
 
 	w.safeStatus("✅ File watcher started successfully\n")
-	w.safeStatus("💡 Press Ctrl+C to stop watching, R+Enter to reload .sync_ignore, S+Enter to show cache stats, A+Enter to deploy agent\n")
+	if interactive {
+		w.safeStatus("💡 Press Ctrl+C to stop watching, R+Enter to reload .sync_ignore, S+Enter to show cache stats, A+Enter to deploy agent, P to pause/resume uploads, M to mute a subtree\n")
+	}
 
 	select {
 	case <-w.done:
@@ -387,6 +436,8 @@ func (w *Watcher) Stop() {
 		}
 	}
 
+	w.closeTargets()
+
 	// Close SSH connection if exists
 	if w.sshClient != nil {
 		if err := w.sshClient.Close(); err != nil {
@@ -414,6 +465,7 @@ func (w *Watcher) generateRemoteConfig() *RemoteAgentConfig {
 	w.configMu.Lock()
 	w.config = newCfg
 	w.configMu.Unlock()
+	w.applyMappings()
 
 	// Build remote config snapshot from the new config
 	cfg := &RemoteAgentConfig{}
@@ -425,10 +477,11 @@ func (w *Watcher) generateRemoteConfig() *RemoteAgentConfig {
 		agg = append(agg, newCfg.Devsync.Ignores...)
 	}
 	cfg.Devsync.Ignores = agg
-	cfg.Devsync.AgentWatchs = newCfg.Devsync.AgentWatchs
+	cfg.Devsync.AgentWatchs = newCfg.AgentWatchPaths()
 	cfg.Devsync.ManualTransfer = newCfg.Devsync.ManualTransfer
 	cfg.Devsync.WorkingDir = newCfg.Devsync.Auth.RemotePath
 	cfg.Devsync.SizeLimit = newCfg.Devsync.SizeLimit
+	cfg.Devsync.HeartbeatInterval = newCfg.Devsync.Heartbeat.IntervalSeconds()
 
 	// Also mirror essential fields into local .sync_temp/config.json for easier local inspection
 	// without having to SSH into remote. We avoid overwriting existing non-empty values except
@@ -632,17 +685,9 @@ func (w *Watcher) startAgentMonitoring() error {
 	remoteExecName := localConfig.GetAgentBinaryName(targetOS)
 	remoteAgentPath := w.joinRemotePathOS(w.config.Devsync.OSTarget, remoteSyncTemp, remoteExecName)
 
-	// Start agent watch command in background - run once and keep it running
-	var watchCmd string
-	if strings.Contains(strings.ToLower(w.config.Devsync.OSTarget), "win") {
-		// Windows: just run agent watch (PID will be printed by agent itself)
-		watchCmd = fmt.Sprintf(`cmd.exe /C "cd /d "%s" && "%s" watch"`, remoteBase, remoteAgentPath)
-	} else {
-		// POSIX: just run agent watch (PID will be printed by agent itself)
-		watchCmd = fmt.Sprintf(`%s watch`, remoteAgentPath)
-	}
-
-	// fmt.Printf("🚀 Starting agent with command: %s\n", watchCmd)
+	// The agent watcher runs detached on the remote and journals its events;
+	// resume from the last acknowledged journal cursor.
+	w.agentCursor = w.loadAgentCursor()
 
 	// Start the agent in a goroutine and keep it running
 	go func() {
@@ -697,7 +742,9 @@ func (w *Watcher) startAgentMonitoring() error {
 				util.Default.ClearLine()
 			}
 
-			// Execute the watch command - this should run continuously
+			// Execute the watch command - this should run continuously.
+			// Rebuilt each time so a restart resumes from the latest cursor.
+			watchCmd := w.agentStreamCommand(remoteBase, remoteAgentPath)
 			if err := w.runAgentWatchCommand(watchCmd); err != nil {
 				// show as status so user sees immediate reconnect info
 				w.safeStatusln("⚠️  Agent watch command failed: %v", err)
@@ -717,6 +764,18 @@ func (w *Watcher) startAgentMonitoring() error {
 					return
 				}
 
+				// Hung agent was killed after missed heartbeats; restart right away
+				if strings.Contains(err.Error(), "agent unhealthy") {
+					w.safeStatusln("🔁 Restarting unhealthy agent...")
+					select {
+					case <-w.ctx.Done():
+						w.safePrintln("🔄 Agent monitoring stopped during agent restart wait")
+						return
+					case <-time.After(1 * time.Second):
+						continue
+					}
+				}
+
 				// If session failed, try to restart
 				if strings.Contains(err.Error(), "session") || strings.Contains(err.Error(), "broken pipe") {
 					w.safeStatusln("🔌 SSH session broken, stopping current session...")
@@ -812,7 +871,18 @@ func (w *Watcher) runAgentWatchCommand(watchCmd string) error {
 	// w.hideCursor()
 	w.safeStatusln("📡 Agent watch command started, monitoring output stream...")
 
+	// Heartbeat supervision: a nil channel blocks forever when disabled
+	w.resetAgentHealth()
+	heartbeatInterval, maxMissed := w.heartbeatSettings()
+	var healthTick <-chan time.Time
+	if heartbeatInterval > 0 {
+		healthTicker := time.NewTicker(heartbeatInterval)
+		defer healthTicker.Stop()
+		healthTick = healthTicker.C
+	}
+
 	// Process output in real-time
+	pending := ""
 	for {
 		select {
 		case <-w.ctx.Done():
@@ -832,7 +902,16 @@ func (w *Watcher) runAgentWatchCommand(watchCmd string) error {
 			if output == "" {
 				continue
 			}
-			w.processAgentOutput(output)
+			// Stream chunks may split a line; only process complete lines and
+			// carry the remainder over to the next chunk.
+			pending += output
+			idx := strings.LastIndex(pending, "\n")
+			if idx < 0 {
+				continue
+			}
+			complete := pending[:idx+1]
+			pending = pending[idx+1:]
+			w.processAgentOutput(complete)
 
 		case err, ok := <-errorChan:
 			if !ok {
@@ -869,6 +948,14 @@ func (w *Watcher) runAgentWatchCommand(watchCmd string) error {
 				return err
 			}
 
+		case <-healthTick:
+			if w.checkAgentHeartbeat(heartbeatInterval, maxMissed) {
+				w.showCursor()
+				w.safeStatusln("💀 Agent unhealthy: no heartbeat for %d intervals, killing agent...", maxMissed)
+				w.killUnhealthyAgent()
+				return fmt.Errorf("agent unhealthy: missed %d heartbeats", maxMissed)
+			}
+
 		case <-time.After(3000 * time.Second):
 			// Restore cursor before returning
 			w.showCursor()
@@ -902,12 +989,35 @@ func (w *Watcher) processAgentOutput(output string) {
 	}
 
 	// Parse structured output from agent
+	lastSeq := int64(-1)
+	defer func() {
+		if lastSeq >= 0 {
+			w.ackAgentCursor(lastSeq)
+		}
+	}()
 	lines := strings.Split(strings.TrimSpace(output), "\n")
 	for _, line := range lines {
-		if strings.TrimSpace(line) == "" {
+		// The agent clears the terminal line before each print; drop those
+		// escape sequences so the structured prefix can be matched.
+		line = strings.TrimSpace(string(util.StripANSIEscapes([]byte(line))))
+		if line == "" {
 			continue
 		}
 
+		// Position the journal tail started from; everything before it is handled
+		if strings.HasPrefix(line, "JOURNAL_CURSOR:") {
+			if n, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "JOURNAL_CURSOR:")), 10, 64); err == nil {
+				lastSeq = n
+			}
+			continue
+		}
+
+		// Journal replay lines carry a "<seq> " prefix; acknowledged once handled
+		if seq, rest, ok := splitJournalLine(line); ok {
+			line = rest
+			lastSeq = seq
+		}
+
 		// Parse line format: [timestamp] TYPE|data|path
 		if strings.HasPrefix(line, "[") && strings.Contains(line, "]") {
 			parts := strings.SplitN(line, "]", 2)
@@ -925,6 +1035,9 @@ func (w *Watcher) processAgentOutput(output string) {
 					// Handle file events
 					w.handleFileDownloadEvent(eventType, filePath)
 				}
+			} else if strings.HasPrefix(content, "HEARTBEAT|") {
+				// Parse heartbeat: HEARTBEAT|queue_depth|last_event
+				w.recordAgentHeartbeat(content)
 			} else if strings.HasPrefix(content, "HASH|") {
 				// Parse hash: HASH|path|hash_value
 				hashParts := strings.Split(content, "|")
@@ -933,11 +1046,14 @@ func (w *Watcher) processAgentOutput(output string) {
 					hashValue := hashParts[2]
 
 					// Determine remote base and try to compute relative path
-					localPath, lerr := util.RemoteToLocal(w.config.Devsync.Auth.RemotePath, w.config.Devsync.Auth.LocalPath, filePath)
+					localPath, lerr := w.remoteToLocal(filePath)
 					if lerr != nil {
 						w.safePrintf("⚠️  Could not map remote path to local: %v\n", lerr)
 						continue
 					}
+					if w.isMuted(localPath) || !w.allowsPull(localPath) {
+						continue
+					}
 
 					// Map to local path under watchPath
 					var err error
@@ -954,7 +1070,7 @@ func (w *Watcher) processAgentOutput(output string) {
 
 					if localHash == hashValue {
 						// If hashes match, no action needed
-						return
+						continue
 					} else {
 						// Try to download the file from remote to local
 						remoteBase := w.config.Devsync.Auth.RemotePath
@@ -974,6 +1090,9 @@ func (w *Watcher) processAgentOutput(output string) {
 						}
 						if err := w.sshClient.DownloadFile(localPath, filePath); err != nil {
 							w.safePrintf("❌ Failed to download file %s from remote: %v\n", localPath, err)
+							w.emitControlEvent("sync_failed", localPath, err.Error())
+						} else {
+							w.emitControlEvent("download", localPath, filePath)
 						}
 					}
 				}
@@ -991,17 +1110,22 @@ func (w *Watcher) handleFileDownloadEvent(eventType, filePath string) {
 		w.safePrintf("🗑️  Received delete event for %s\n", filePath)
 
 		// Map remote path to local path
-		relPath, rerr := util.RemoteToLocal(w.config.Devsync.Auth.RemotePath, w.config.Devsync.Auth.LocalPath, filePath)
+		relPath, rerr := w.remoteToLocal(filePath)
 		if rerr != nil {
 			w.safePrintf("⚠️  Could not map remote delete path to local: %v\n", rerr)
 			return
 		}
+		if w.isMuted(relPath) || !w.allowsPull(relPath) {
+			return
+		}
 
 		// Attempt to remove local file or directory
 		if err := os.RemoveAll(relPath); err != nil {
 			w.safePrintf("❌ Failed to delete local path %s: %v\n", relPath, err)
+			w.emitControlEvent("sync_failed", relPath, err.Error())
 		} else {
 			w.safePrintf("✅ Deleted local path: %s\n", relPath)
+			w.emitControlEvent("delete_local", relPath, filePath)
 
 			// Remove metadata from cache if available
 			if w.fileCache != nil {
@@ -1100,6 +1224,9 @@ func (w *Watcher) syncConfigToRemote() error {
 	if err := w.uploadConfigToRemote(configJSON, remoteConfigPath); err != nil {
 		return fmt.Errorf("failed to upload config: %v", err)
 	}
+	if err := w.syncMappingConfigs(); err != nil {
+		return err
+	}
 
 	w.safePrintln("✅ Config synced successfully to remote")
 	return nil
@@ -1158,6 +1285,9 @@ func (w *Watcher) buildAndDeployAgent() error {
 
 	// temporary debug removed
 
+	// Agent copies for indexing the roots of devsync.mappings
+	w.deployMappingAgents(w.config, w.sshClient, projectRoot)
+
 	// Prepare unified deployment options
 	deployOpts := deployagent.UnifiedDeployOptions{
 		ProjectRoot:    projectRoot,
@@ -1283,56 +1413,41 @@ func (w *Watcher) handleEvent(event notify.EventInfo) {
 		w.ignoresMu.Unlock()
 	}
 
-	// Snapshot config for safe downstream use
-	w.configMu.RLock()
-	cfgSnapshot := w.config
-	w.configMu.RUnlock()
-
 	// Check if path should be ignored (uses loadExtendedIgnores which is locked internally)
-	if w.shouldIgnore(path) {
+	if w.shouldIgnore(path) || w.isMuted(path) {
 		return
 	}
 
 	// Map notify event to our EventType
 	eventType := w.mapNotifyEvent(event.Event())
 
-	// Check permissions using snapshot
-	if cfgSnapshot != nil {
-		// Debug: log file events for docker-compose.yml specifically
-		eventPath := event.Path()
-		if strings.Contains(eventPath, "docker-compose.yml") {
-			log.Printf("🔍 DEBUG: File event detected for docker-compose.yml: type=%v, path=%s", eventType, eventPath)
-		}
+	// Get file info
+	info, err := os.Stat(path)
+	isDir := err == nil && info.IsDir()
 
-		switch eventType {
-		case EventCreate:
-			if !cfgSnapshot.Devsync.TriggerPerm.Add {
-				return
-			}
-		case EventWrite:
-			if !cfgSnapshot.Devsync.TriggerPerm.Change {
-				return
-			}
-		case EventRemove:
-			if !cfgSnapshot.Devsync.TriggerPerm.Unlink {
-				return
-			}
-		case EventRename:
-			if !cfgSnapshot.Devsync.TriggerPerm.Unlink {
+	// Pair renames/moves, including remove+create sequences, so they become
+	// a remote move instead of a delete followed by a full re-upload.
+	// Vanished paths are permission-checked when their hold expires, once
+	// the cache tells whether a folder was removed.
+	cookie := notifyMoveCookie(event)
+	if err != nil && os.IsNotExist(err) && (eventType == EventRemove || (eventType == EventRename && getOldPathFromNotify(event) == "")) {
+		w.holdVanished(FileEvent{Path: path, EventType: eventType, Timestamp: time.Now()}, cookie)
+		return
+	}
+	if !w.checkEventAllowed(eventType, path, isDir) {
+		return
+	}
+	if err == nil && (eventType == EventCreate || eventType == EventRename) {
+		if old := w.matchVanished(path, isDir, cookie); old != nil {
+			// A move deletes the old remote path, so it needs the unlink
+			// permission there; otherwise the new path is a plain upload
+			if w.checkEventAllowed(EventRemove, old.Path, old.IsDir) {
+				w.dispatchMove(*old, path, isDir)
 				return
 			}
 		}
-	} else {
-		// fallback: use existing isEventAllowed (reads w.config) but try to avoid races
-		if !w.isEventAllowed(eventType) {
-			return
-		}
 	}
 
-	// Get file info
-	info, err := os.Stat(path)
-	isDir := err == nil && info.IsDir()
-
 	// If the notify backend reports a directory-level Create/Write event,
 	// many backends do that instead of a per-file event. In that case,
 	// proactively scan the directory and trigger per-file events so the
@@ -1372,16 +1487,7 @@ func (w *Watcher) handleEvent(event notify.EventInfo) {
 			// the tree and upload nested files.
 			if dispatched == 0 {
 				// compute remote mapping for directory if possible
-				remoteBase := w.config.Devsync.Auth.RemotePath
-				if remoteBase == "" {
-					remoteBase = "."
-				}
-				var remoteDir string
-				if rel, rerr := filepath.Rel(w.config.Devsync.Auth.LocalPath, path); rerr == nil {
-					remoteDir = w.joinRemotePath(remoteBase, filepath.ToSlash(rel))
-				} else {
-					remoteDir = ""
-				}
+				remoteDir, _ := w.localToRemote(path)
 				dirEvent := FileEvent{
 					Path:      path,
 					EventType: EventCreate,
@@ -1430,67 +1536,25 @@ func (w *Watcher) ExecuteScripts(event FileEvent) {
 
 	// Handle SSH sync / delete if SSH client is available
 	if w.sshClient != nil {
-		if event.EventType == EventRename {
-			// If we have an old path, attempt to delete it on remote
-			if event.OldPath != "" {
-				remoteBase := w.config.Devsync.Auth.RemotePath
-				if remoteBase == "" {
-					remoteBase = "."
-				}
-				var remoteOld string
-				if rel, rerr := filepath.Rel(w.config.Devsync.Auth.LocalPath, event.OldPath); rerr == nil {
-					remoteOld = w.joinRemotePath(remoteBase, filepath.ToSlash(rel))
-				} else {
-					rp, merr := util.LocalToRemote(w.config.Devsync.Auth.LocalPath, remoteBase, event.OldPath)
-					if merr != nil {
-						w.safePrintf("⚠️  Could not map local old path to remote: %v\n", merr)
-					} else {
-						remoteOld = rp
-					}
-				}
-
-				if remoteOld != "" {
-					if strings.Contains(remoteOld, ".sync_temp") {
-						w.safePrintf("🚫 BLOCKED: Remote delete blocked for path: %s\n", remoteOld)
-					} else {
-						cmd := fmt.Sprintf("rm -rf '%s'", remoteOld)
-						w.safePrintf("📤 Deleting remote old path: %s\n", remoteOld)
-						if err := w.sshClient.RunCommand(cmd); err != nil {
-							w.safePrintf("❌ Failed to delete remote old path %s: %v\n", remoteOld, err)
-						} else {
-							w.safePrintf("✅ Remote old path deleted: %s\n", remoteOld)
-						}
-					}
-
-					// Remove metadata from file cache for old path
-					if w.fileCache != nil {
-						if err := w.fileCache.DeleteFileMetadata(event.OldPath); err != nil {
-							w.safePrintf("⚠️  Failed to delete metadata for old path %s: %v\n", event.OldPath, err)
-						}
-					}
-				}
-			}
-
-			// Proceed to treat the new path as a create/write for syncing
-			// fall through to normal sync below
+		if event.EventType == EventRename && event.OldPath != "" {
+			// Paired rename/move: the queued processor tries a remote move
+			// and only uploads when that fails.
+			w.syncFileViaSSH(event)
+			return
 		}
 		if event.EventType == EventRemove {
-			// Map local path to remote path using POSIX join (preserve forward slashes)
-			remoteBase := w.config.Devsync.Auth.RemotePath
-			if remoteBase == "" {
-				remoteBase = "."
+			if w.uploadsPaused.Load() {
+				// held with the other events until ResumeUploads
+				w.scheduleDebouncedEvent(event)
+				return
 			}
-			var remotePath string
-			if rel, rerr := filepath.Rel(w.config.Devsync.Auth.LocalPath, event.Path); rerr == nil {
-				remotePath = w.joinRemotePath(remoteBase, filepath.ToSlash(rel))
-			} else {
-				// Try robust helper conversion instead of naive replace
-				rp, merr := util.LocalToRemote(w.config.Devsync.Auth.LocalPath, remoteBase, event.Path)
-				if merr != nil {
-					w.safePrintf("⚠️  Could not map local path to remote: %v\n", merr)
-					return
-				}
-				remotePath = rp
+			// live deletes skip the queue processor, which fans out the rest
+			w.fanOutEvent(event)
+			// Map local path to remote path using POSIX join (preserve forward slashes)
+			remotePath, merr := w.localToRemote(event.Path)
+			if merr != nil {
+				w.safePrintf("⚠️  Could not map local path to remote: %v\n", merr)
+				return
 			}
 
 			// Final failsafe: don't try to delete .sync_temp on remote
@@ -1502,8 +1566,10 @@ func (w *Watcher) ExecuteScripts(event FileEvent) {
 				w.safePrintf("📤 Deleting remote path: %s\n", remotePath)
 				if err := w.sshClient.RunCommand(cmd); err != nil {
 					w.safePrintf("❌ Failed to delete remote path %s: %v\n", remotePath, err)
+					w.emitControlEvent("sync_failed", event.Path, err.Error())
 				} else {
 					w.safePrintf("✅ Remote delete succeeded: %s\n", remotePath)
+					w.emitControlEvent("delete_remote", event.Path, remotePath)
 				}
 			}
 
@@ -1533,20 +1599,9 @@ func (w *Watcher) ExecuteScripts(event FileEvent) {
 				// If the file no longer exists locally (common after rename/move),
 				// try to remove the corresponding remote path and clear cache metadata.
 				if os.IsNotExist(err) || strings.Contains(strings.ToLower(err.Error()), "no such file") {
-					remoteBase := w.config.Devsync.Auth.RemotePath
-					if remoteBase == "" {
-						remoteBase = "."
-					}
-					var remotePath string
-					if rel, rerr := filepath.Rel(w.config.Devsync.Auth.LocalPath, event.Path); rerr == nil {
-						remotePath = w.joinRemotePath(remoteBase, filepath.ToSlash(rel))
-					} else {
-						rp, merr := util.LocalToRemote(w.config.Devsync.Auth.LocalPath, remoteBase, event.Path)
-						if merr != nil {
-							w.safePrintf("⚠️  Could not map missing local path to remote: %v\n", merr)
-						} else {
-							remotePath = rp
-						}
+					remotePath, merr := w.localToRemote(event.Path)
+					if merr != nil {
+						w.safePrintf("⚠️  Could not map missing local path to remote: %v\n", merr)
 					}
 
 					if remotePath != "" {
@@ -1678,25 +1733,18 @@ func (w *Watcher) syncFileViaSSH(event FileEvent) {
 	}
 
 	targetOS := strings.ToLower(w.config.Devsync.OSTarget)
-	remoteBase := w.config.Devsync.Auth.RemotePath
+
+	// Resolve the mapping (remote root) and the path relative to it
+	_, mapping, relPart, ok := w.mappingFor(localPath)
+	if !ok {
+		w.safePrintf("⚠️  Could not map local path to remote for sync: %s is outside every devsync mapping\n", localPath)
+		return
+	}
+	remoteBase := mapping.RemotePath
 	if remoteBase == "" {
 		remoteBase = "."
 	}
 
-	// Derive relative path
-	var relPart string
-	if rel, rerr := filepath.Rel(w.config.Devsync.Auth.LocalPath, localPath); rerr == nil {
-		relPart = filepath.ToSlash(rel)
-	} else {
-		rp, merr := util.LocalToRemote(w.config.Devsync.Auth.LocalPath, remoteBase, localPath)
-		if merr != nil {
-			w.safePrintf("⚠️  Could not map local path to remote for sync: %v\n", merr)
-			return
-		}
-		// rp includes remoteBase already; treat full path
-		relPart = strings.TrimPrefix(rp, remoteBase)
-	}
-
 	// Build remote path OS-aware
 	var remotePath string
 	if strings.Contains(targetOS, "win") {
@@ -1762,12 +1810,8 @@ func (w *Watcher) syncFileViaSSH(event FileEvent) {
 	}
 	if event.OldPath != "" {
 		// compute old remote mapping if available
-		if rel, rerr := filepath.Rel(w.config.Devsync.Auth.LocalPath, event.OldPath); rerr == nil {
-			event.OldRemote = w.joinRemotePath(remoteBase, filepath.ToSlash(rel))
-		} else {
-			if rp, merr := util.LocalToRemote(w.config.Devsync.Auth.LocalPath, remoteBase, event.OldPath); merr == nil {
-				event.OldRemote = rp
-			}
+		if rp, merr := w.localToRemote(event.OldPath); merr == nil {
+			event.OldRemote = rp
 		}
 	}
 
@@ -1782,6 +1826,7 @@ func (w *Watcher) startEventQueueProcessor() {
 	}
 	go func() {
 		for ev := range w.eventQueue {
+			w.fanOutEvent(ev)
 			w.processQueuedEvent(ev)
 		}
 	}()
@@ -1792,8 +1837,9 @@ func (w *Watcher) startEventQueueProcessor() {
 // the last event.
 func (w *Watcher) scheduleDebouncedEvent(evt FileEvent) {
 	key := evt.Path
-	// If debounceDelay is zero, bypass debounce and enqueue immediately.
-	if w.debounceDelay == 0 {
+	// If debounceDelay is zero, bypass debounce and enqueue immediately
+	// (unless paused: held events must stay in debounceMap).
+	if w.debounceDelay == 0 && !w.uploadsPaused.Load() {
 		select {
 		case w.eventQueue <- evt:
 			// enqueued
@@ -1817,7 +1863,8 @@ func (w *Watcher) scheduleDebouncedEvent(evt FileEvent) {
 		// when timer fires, enqueue latest event
 		w.debounceMu.Lock()
 		entry, ok := w.debounceMap[key]
-		if !ok {
+		if !ok || w.uploadsPaused.Load() {
+			// while paused the entry stays here until ResumeUploads
 			w.debounceMu.Unlock()
 			return
 		}
@@ -1869,6 +1916,9 @@ func (w *Watcher) processQueuedEvent(ev FileEvent) {
 		w.safePrintf("❌ SSH client not available for queued file sync\n")
 		return
 	}
+	if w.isMuted(ev.Path) {
+		return
+	}
 
 	switch ev.EventType {
 	case EventRemove:
@@ -1887,9 +1937,7 @@ func (w *Watcher) processQueuedEvent(ev FileEvent) {
 		remote := ev.Remote
 		if remote == "" && ev.Path != "" {
 			// compute if not provided
-			if rel, rerr := filepath.Rel(w.config.Devsync.Auth.LocalPath, ev.Path); rerr == nil {
-				remote = w.joinRemotePath(w.config.Devsync.Auth.RemotePath, filepath.ToSlash(rel))
-			}
+			remote, _ = w.localToRemote(ev.Path)
 		}
 		if remote == "" {
 			return
@@ -1902,8 +1950,10 @@ func (w *Watcher) processQueuedEvent(ev FileEvent) {
 		w.safePrintf("📤 Deleting remote path: %s\n", remote)
 		if err := w.sshClient.RunCommand(cmd); err != nil {
 			w.safePrintf("❌ Failed to delete remote path %s: %v\n", remote, err)
+			w.emitControlEvent("sync_failed", ev.Path, err.Error())
 		} else {
 			w.safePrintf("✅ Remote delete succeeded: %s\n", remote)
+			w.emitControlEvent("delete_remote", ev.Path, remote)
 		}
 
 		// Remove metadata from file cache if available
@@ -1937,13 +1987,12 @@ func (w *Watcher) processQueuedEvent(ev FileEvent) {
 					return nil
 				}
 				// Compute remote path for this file
-				rel, rerr := filepath.Rel(w.config.Devsync.Auth.LocalPath, p)
+				remote, rerr := w.localToRemote(p)
 				if rerr != nil {
-					// fallback: skip file if we cannot compute relative path
-					w.safePrintf("⚠️  Skipping upload for %s: cannot compute relative path: %v\n", p, rerr)
+					// fallback: skip file if we cannot compute its remote path
+					w.safePrintf("⚠️  Skipping upload for %s: %v\n", p, rerr)
 					return nil
 				}
-				remote := w.joinRemotePath(w.config.Devsync.Auth.RemotePath, filepath.ToSlash(rel))
 
 				// Acquire slot and upload
 				w.uploadSlots <- struct{}{}
@@ -1952,12 +2001,14 @@ func (w *Watcher) processQueuedEvent(ev FileEvent) {
 				util.Default.ClearLine()
 				if err := w.sshClient.UploadFileSCP(p, remote); err != nil {
 					w.safePrintf("❌ %d Failed to sync file %s to %s: %v\n", wid, p, remote, err)
+					w.emitControlEvent("upload_failed", p, err.Error())
 				} else {
 					util.Default.Printf("✅ %d File synced: %s → %s\n", wid, p, remote)
 					util.Default.ClearLine()
 					if w.fileCache != nil {
 						_ = w.fileCache.UpdateFileMetadata(p)
 					}
+					w.emitControlEvent("upload", p, remote)
 				}
 				<-w.uploadSlots
 				return nil
@@ -1992,30 +2043,32 @@ func (w *Watcher) processQueuedEvent(ev FileEvent) {
 					if info.IsDir() {
 						return nil
 					}
-					rel, rerr := filepath.Rel(w.config.Devsync.Auth.LocalPath, p)
+					remote, rerr := w.localToRemote(p)
 					if rerr != nil {
-						w.safePrintf("⚠️  Skipping upload for %s: cannot compute relative path: %v\n", p, rerr)
+						w.safePrintf("⚠️  Skipping upload for %s: %v\n", p, rerr)
 						return nil
 					}
-					remote := w.joinRemotePath(w.config.Devsync.Auth.RemotePath, filepath.ToSlash(rel))
 					w.uploadSlots <- struct{}{}
 					wid := atomic.AddInt64(&w.uploadCounter, 1)
 					util.Default.Printf("⬆️  %d -> Uploading %s -> %s\n", wid, p, remote)
 					util.Default.ClearLine()
 					if uerr := w.sshClient.UploadFileSCP(p, remote); uerr != nil {
 						w.safePrintf("❌ %d Failed to sync file %s to %s: %v\n", wid, p, remote, uerr)
+						w.emitControlEvent("upload_failed", p, uerr.Error())
 					} else {
 						util.Default.Printf("✅ %d File synced: %s → %s\n", wid, p, remote)
 						util.Default.ClearLine()
 						if w.fileCache != nil {
 							_ = w.fileCache.UpdateFileMetadata(p)
 						}
+						w.emitControlEvent("upload", p, remote)
 					}
 					<-w.uploadSlots
 					return nil
 				})
 			} else {
 				w.safePrintf("❌ %d Failed to sync file %s to %s: %v\n", wid, ev.Path, ev.Remote, err)
+				w.emitControlEvent("upload_failed", ev.Path, err.Error())
 			}
 		} else {
 			util.Default.Printf("✅ %d File synced: %s → %s\n", wid, ev.Path, ev.Remote)
@@ -2023,6 +2076,7 @@ func (w *Watcher) processQueuedEvent(ev FileEvent) {
 			if w.fileCache != nil {
 				_ = w.fileCache.UpdateFileMetadata(ev.Path)
 			}
+			w.emitControlEvent("upload", ev.Path, ev.Remote)
 		}
 		<-w.uploadSlots
 
@@ -2043,26 +2097,23 @@ func (w *Watcher) processQueuedEvent(ev FileEvent) {
 		// Compute remote paths if missing
 		oldRemote := ev.OldRemote
 		if oldRemote == "" && ev.OldPath != "" {
-			if rel, rerr := filepath.Rel(w.config.Devsync.Auth.LocalPath, ev.OldPath); rerr == nil {
-				oldRemote = w.joinRemotePath(w.config.Devsync.Auth.RemotePath, filepath.ToSlash(rel))
-			}
+			oldRemote, _ = w.localToRemote(ev.OldPath)
 		}
 		newRemote := ev.Remote
 		if newRemote == "" && ev.Path != "" {
-			if rel, rerr := filepath.Rel(w.config.Devsync.Auth.LocalPath, ev.Path); rerr == nil {
-				newRemote = w.joinRemotePath(w.config.Devsync.Auth.RemotePath, filepath.ToSlash(rel))
-			}
+			newRemote, _ = w.localToRemote(ev.Path)
 		}
 
 		if oldRemote != "" && newRemote != "" {
 			// Try server-side mv first
-			mvCmd := fmt.Sprintf("mv '%s' '%s'", oldRemote, newRemote)
+			mvCmd := remoteMoveCommand(w.config.Devsync.OSTarget, oldRemote, newRemote)
 			w.safePrintf("🔁 Attempting remote mv: %s -> %s\n", oldRemote, newRemote)
 			if err := w.sshClient.RunCommand(mvCmd); err == nil {
 				w.safePrintf("✅ Remote mv succeeded: %s -> %s\n", oldRemote, newRemote)
-				// update cache: delete old metadata
-				if w.fileCache != nil {
-					_ = w.fileCache.DeleteFileMetadata(ev.OldPath)
+				w.emitControlEvent("move_remote", ev.Path, oldRemote+" -> "+newRemote)
+				// cache entries follow the new path (no-op when dispatchMove already did it)
+				if w.fileCache != nil && ev.OldPath != "" {
+					_ = w.fileCache.RenamePath(ev.OldPath, ev.Path)
 				}
 				break
 			} else {
@@ -2070,32 +2121,46 @@ func (w *Watcher) processQueuedEvent(ev FileEvent) {
 			}
 		}
 
-		// Fallback: upload new path then delete old
+		// Fallback: upload new path (walking directories) then delete old
 		if ev.Path != "" && newRemote != "" {
+			if ev.IsDir {
+				w.safePrintf("⬆️  Uploading moved directory (rename fallback) %s -> %s\n", ev.Path, newRemote)
+				w.processQueuedEvent(FileEvent{Path: ev.Path, EventType: EventCreate, IsDir: true, Remote: newRemote, Timestamp: time.Now()})
+				w.deleteRemoteAfterMoveFallback(oldRemote)
+				return
+			}
 			w.uploadSlots <- struct{}{}
 			wid := atomic.AddInt64(&w.uploadCounter, 1)
 			util.Default.Printf("⬆️  %d -> Uploading (rename fallback) %s -> %s\n", wid, ev.Path, newRemote)
 			util.Default.ClearLine()
 			if err := w.sshClient.UploadFileSCP(ev.Path, newRemote); err != nil {
 				w.safePrintf("❌ %d Failed to upload renamed file %s to %s: %v\n", wid, ev.Path, newRemote, err)
+				w.emitControlEvent("upload_failed", ev.Path, err.Error())
 			} else {
+				w.emitControlEvent("upload", ev.Path, newRemote)
 				util.Default.Printf("✅ %d File uploaded (rename fallback): %s → %s\n", wid, ev.Path, newRemote)
 				util.Default.ClearLine()
 				if w.fileCache != nil {
 					_ = w.fileCache.UpdateFileMetadata(ev.Path)
 				}
-				// delete old remote if present
-				if oldRemote != "" && !strings.Contains(oldRemote, ".sync_temp") {
-					if derr := w.sshClient.RunCommand(fmt.Sprintf("rm -rf '%s'", oldRemote)); derr != nil {
-						w.safePrintf("⚠️ Failed to delete old remote path %s after upload: %v\n", oldRemote, derr)
-					}
-				}
+				w.deleteRemoteAfterMoveFallback(oldRemote)
 			}
 			<-w.uploadSlots
 		}
 	}
 }
 
+// deleteRemoteAfterMoveFallback removes the old remote path once a failed
+// remote mv has been replaced by an upload of the new path.
+func (w *Watcher) deleteRemoteAfterMoveFallback(oldRemote string) {
+	if oldRemote == "" || strings.Contains(oldRemote, ".sync_temp") {
+		return
+	}
+	if derr := w.sshClient.RunCommand(fmt.Sprintf("rm -rf '%s'", oldRemote)); derr != nil {
+		w.safePrintf("⚠️ Failed to delete old remote path %s after upload: %v\n", oldRemote, derr)
+	}
+}
+
 // mapNotifyEvent maps notify.Event to our EventType
 func (w *Watcher) mapNotifyEvent(event notify.Event) EventType {
 	switch {
@@ -2149,6 +2214,11 @@ func getOldPathFromNotify(ev notify.EventInfo) string {
 }
 
 func (w *Watcher) shouldIgnore(path string) bool {
+	// With devsync.mappings only paths inside a mapped root are synced
+	if w.outsideMappings(path) {
+		return true
+	}
+
 	// Ensure IgnoreCache instance is available early so we can consult
 	// priority-includes (negation patterns) before applying the working-dir bypass.
 	w.ignoresMu.RLock()
@@ -2435,20 +2505,61 @@ func fileSHA256(path string) (string, error) {
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
-// isEventAllowed checks if an event type is allowed by trigger permissions
-func (w *Watcher) isEventAllowed(eventType EventType) bool {
+// isEventAllowed checks if an event on path is allowed by trigger
+// permissions and reports which rule (or global flag) decided.
+func (w *Watcher) isEventAllowed(eventType EventType, path string, isDir bool) (bool, string) {
+	w.configMu.RLock()
+	cfg := w.config
+	w.configMu.RUnlock()
+	if cfg == nil {
+		return false, "no configuration"
+	}
+
+	var kind string
 	switch eventType {
 	case EventCreate:
-		return w.config.Devsync.TriggerPerm.Add
+		kind = config.TriggerAdd
 	case EventWrite:
-		return w.config.Devsync.TriggerPerm.Change
-	case EventRemove:
-		return w.config.Devsync.TriggerPerm.Unlink
-	case EventRename:
-		return w.config.Devsync.TriggerPerm.Unlink // For rename, we use unlink permission
+		kind = config.TriggerChange
+	case EventRemove, EventRename:
+		// For rename, we use unlink permission
+		kind = config.TriggerUnlink
+		if isDir {
+			kind = config.TriggerUnlinkFolder
+		}
 	default:
+		return false, "unknown event"
+	}
+
+	// A mapping with its own trigger_permission decides for its root
+	if cfg.HasMappings() {
+		if i, mrel, ok := cfg.PathMap().MatchLocal(path); ok {
+			if tp := cfg.Devsync.Mappings[i].TriggerPerm; tp != nil {
+				allowed, by := tp.Allows(mrel, kind)
+				return allowed, fmt.Sprintf("mappings[%d].%s", i, by)
+			}
+		}
+	}
+
+	rel, err := filepath.Rel(w.watchPath, path)
+	if err != nil {
+		rel = path
+	}
+	return cfg.Devsync.TriggerPerm.Allows(filepath.ToSlash(rel), kind)
+}
+
+// checkEventAllowed is isEventAllowed for the event pipeline, after the sync
+// direction: blocked events are reported to control clients along with the
+// deciding rule.
+func (w *Watcher) checkEventAllowed(eventType EventType, path string, isDir bool) bool {
+	if !w.allowsPush(path) {
 		return false
 	}
+	allowed, decidedBy := w.isEventAllowed(eventType, path, isDir)
+	if !allowed {
+		w.emitControlEvent("event_blocked", path, decidedBy)
+	}
+	return allowed
 }
 
 // isDuplicateEvent checks if this event is a duplicate of a recent event
@@ -2498,6 +2609,14 @@ func (w *Watcher) hasActiveSession() bool {
 
 // HandleReloadCommand handles the reload command from user input
 func (w *Watcher) HandleReloadCommand() {
+	if err := w.reload(); err != nil {
+		w.safePrintf("❌ Failed to reload configuration: %v\n", err)
+	}
+}
+
+// reload aborts in-flight transfers and reloads the configuration; shared by
+// the keyboard shortcut, SIGHUP and the control API.
+func (w *Watcher) reload() error {
 	// Abort any in-flight SCP transfers before reloading configuration so
 	// the UI can return promptly and uploads do not continue in background.
 	if w.sshClient != nil {
@@ -2506,8 +2625,10 @@ func (w *Watcher) HandleReloadCommand() {
 	}
 
 	if err := w.ReloadConfiguration(); err != nil {
-		w.safePrintf("❌ Failed to reload configuration: %v\n", err)
+		return err
 	}
+	w.emitControlEvent("reloaded", "", "")
+	return nil
 }
 
 // ReloadConfiguration reloads the configuration
@@ -2521,8 +2642,9 @@ func (w *Watcher) ReloadConfiguration() error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Update watcher's config
+	// Update watcher's config; the --direction of this session stays
 	oldConfig := w.config
+	newCfg.Devsync.SessionDirection = oldConfig.Devsync.SessionDirection
 	w.config = newCfg
 
 	// Sync new config to remote if SSH is configured
@@ -2571,9 +2693,17 @@ func (w *Watcher) HandleShowStatsCommand() {
 
 	statsBlock += fmt.Sprintf(`📁 Total cached files: %d
 💾 Total cached size: %.2f MB
-🗄️  Cache location: .sync_temp/file_cache.db`,
+🗄️  Cache location: .sync_temp/file_cache.db
+💓 Agent health: %s`,
 		totalFiles,
-		float64(totalSize)/(1024*1024))
+		float64(totalSize)/(1024*1024),
+		w.agentHealthSummary())
+	for _, ts := range w.targetStatuses() {
+		statsBlock += fmt.Sprintf("\n🎯 Target %s: %s, %d synced, %d failed, %d waiting for retry", ts.Name, ts.State, ts.Synced, ts.Failed, ts.Retrying)
+		if ts.LastError != "" {
+			statsBlock += fmt.Sprintf(" (last error: %s)", ts.LastError)
+		}
+	}
 
 	util.Default.PrintBlock(statsBlock, true)
 }
@@ -2603,10 +2733,14 @@ func (w *Watcher) gracefulShutdown() {
 	// Stop file watching
 	w.StopNotify()
 
+	// Close the control endpoint and any event streams
+	w.stopControlServer()
+
 	// Give goroutines a moment to clean up
 	time.Sleep(500 * time.Millisecond)
 
 	// Close SSH connections
+	w.closeTargets()
 	if w.sshClient != nil {
 		if err := w.sshClient.Close(); err != nil {
 			w.safePrintf("⚠️  Error closing SSH client: %v\n", err)
@@ -0,0 +1,215 @@
+// Package pathlink turns remote file paths in terminal output into OSC 8
+// hyperlinks pointing at the synced local file. The visible text is left
+// untouched so full-screen programs keep their layout.
+package pathlink
+
+import (
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"make-sync/internal/util"
+)
+
+// maxOSC bounds how much of an OSC string is kept to detect existing links.
+const maxOSC = 512
+
+const (
+	stateText = iota
+	stateEsc
+	stateCSI
+	stateString // OSC, DCS, APC, PM
+)
+
+// Linker filters a stream of terminal output. Escape sequences may span
+// Filter calls; paths must arrive within one call to be linked. A path
+// running up to the end of a call may continue in the next one, so it is
+// left unlinked rather than linked to a truncated path. It is safe for
+// concurrent use.
+type Linker struct {
+	remoteBase string
+	localBase  string
+	urlTmpl    string
+	re         *regexp.Regexp
+
+	mu     sync.Mutex
+	state  int
+	kind   byte   // introducer of the current escape string
+	str    []byte // start of the current OSC string
+	strEsc bool   // ESC seen inside a string, may start ST
+	inLink bool   // output is inside a hyperlink of the program itself
+}
+
+// New returns a linker for paths under remoteBase, linked to the same path
+// under localBase. urlTmpl is the link target: {{path}} is the local path
+// with forward slashes and a leading slash (URL escaped), {{line}} and
+// {{col}} the position printed after the path. Placeholders without a value
+// are dropped together with the ':' in front of them.
+func New(remoteBase, localBase, urlTmpl string) *Linker {
+	remoteBase = strings.TrimRight(strings.ReplaceAll(remoteBase, "\\", "/"), "/")
+	l := &Linker{remoteBase: remoteBase, localBase: localBase, urlTmpl: urlTmpl}
+	if remoteBase == "" {
+		return l
+	}
+	parts := strings.Split(remoteBase, "/")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	// base, at least one more path element, then :line[:col] or (line)
+	l.re = regexp.MustCompile(strings.Join(parts, `[/\\]`) +
+		`([/\\][^\s"'<>()\[\]{}|;,:` + "`" + `]+)(?::(\d+)(?::(\d+))?|\((\d+)\))?`)
+	return l
+}
+
+// Filter returns b with the paths it contains linked.
+func (l *Linker) Filter(b []byte) []byte {
+	if l.re == nil {
+		return b
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make([]byte, 0, len(b)+64)
+	text := -1
+	flush := func(end int) {
+		if text >= 0 {
+			out = l.link(out, b[text:end], end == len(b))
+			text = -1
+		}
+	}
+	for i, c := range b {
+		switch l.state {
+		case stateText:
+			if c == 0x1b {
+				flush(i)
+				l.state = stateEsc
+				out = append(out, c)
+				continue
+			}
+			if text < 0 {
+				text = i
+			}
+		case stateEsc:
+			out = append(out, c)
+			switch c {
+			case '[':
+				l.state = stateCSI
+			case ']', 'P', '_', '^':
+				l.state = stateString
+				l.kind = c
+				l.str = l.str[:0]
+				l.strEsc = false
+			default:
+				l.state = stateText
+			}
+		case stateCSI:
+			out = append(out, c)
+			if c >= 0x40 && c <= 0x7e {
+				l.state = stateText
+			}
+		case stateString:
+			out = append(out, c)
+			switch {
+			case c == 0x07:
+				l.endString()
+			case l.strEsc:
+				l.strEsc = false
+				if c == '\\' {
+					l.endString()
+				}
+			case c == 0x1b:
+				l.strEsc = true
+			case len(l.str) < maxOSC:
+				l.str = append(l.str, c)
+			}
+		}
+	}
+	flush(len(b))
+	return out
+}
+
+// endString finishes an escape string, tracking hyperlinks opened and
+// closed by the program (OSC 8 ; params ; uri).
+func (l *Linker) endString() {
+	l.state = stateText
+	if l.kind != ']' || !strings.HasPrefix(string(l.str), "8;") {
+		return
+	}
+	_, uri, _ := strings.Cut(string(l.str[2:]), ";")
+	l.inLink = uri != ""
+}
+
+// link appends text to out with the paths in it wrapped in hyperlinks.
+// open is set when text ends the chunk and may go on in the next one.
+func (l *Linker) link(out, text []byte, open bool) []byte {
+	if l.inLink {
+		return append(out, text...)
+	}
+	last := 0
+	for _, m := range l.re.FindAllSubmatchIndex(text, -1) {
+		start, end := m[0], m[1]
+		// a longer path that merely contains the base, or one that may be
+		// cut off by the end of the chunk
+		if (start > 0 && isPathByte(text[start-1])) || (open && end == len(text)) {
+			continue
+		}
+		rel := string(text[m[2]:m[3]])
+		line, col := group(text, m, 4), group(text, m, 6)
+		if line == "" {
+			line = group(text, m, 8)
+		}
+		// a sentence ending right after the path
+		if trimmed := strings.TrimRight(rel, "."); trimmed != rel && line == "" {
+			end -= len(rel) - len(trimmed)
+			rel = trimmed
+		}
+		target, ok := l.url(rel, line, col)
+		if !ok {
+			continue
+		}
+		out = append(out, text[last:start]...)
+		out = append(out, "\x1b]8;;"+target+"\x1b\\"...)
+		out = append(out, text[start:end]...)
+		out = append(out, "\x1b]8;;\x1b\\"...)
+		last = end
+	}
+	return append(out, text[last:]...)
+}
+
+// url builds the link target of the remote path remoteBase+rel.
+func (l *Linker) url(rel, line, col string) (string, bool) {
+	local, err := util.RemoteToLocal(l.remoteBase, l.localBase, l.remoteBase+strings.ReplaceAll(rel, "\\", "/"))
+	if err != nil {
+		return "", false
+	}
+	p := filepath.ToSlash(local)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // C:/x
+	}
+	s := l.urlTmpl
+	if line == "" {
+		s = strings.ReplaceAll(s, ":{{line}}", "")
+	}
+	if col == "" {
+		s = strings.ReplaceAll(s, ":{{col}}", "")
+	}
+	return strings.NewReplacer(
+		"{{path}}", (&url.URL{Path: p}).EscapedPath(),
+		"{{line}}", line,
+		"{{col}}", col,
+	).Replace(s), true
+}
+
+func group(text []byte, m []int, i int) string {
+	if m[i] < 0 {
+		return ""
+	}
+	return string(text[m[i]:m[i+1]])
+}
+
+func isPathByte(c byte) bool {
+	return c == '/' || c == '\\' || c == '.' || c == '_' || c == '-' ||
+		(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,60 @@
+package pathlink
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLinkerLinksRemotePaths(t *testing.T) {
+	l := New("/home/ubuntu/workspaces/", "/src/app", "file://{{path}}")
+	got := string(l.Filter([]byte("Error in /home/ubuntu/workspaces/app/src/Foo.php:42\r\n")))
+	want := "Error in \x1b]8;;file:///src/app/app/src/Foo.php\x1b\\/home/ubuntu/workspaces/app/src/Foo.php:42\x1b]8;;\x1b\\\r\n"
+	if got != want {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+
+	l = New("/home/ubuntu/workspaces", "/src/my app", "vscode://file{{path}}:{{line}}:{{col}}")
+	got = string(l.Filter([]byte("at /home/ubuntu/workspaces/a.js(7) and /home/ubuntu/workspaces/b.js.\n")))
+	for _, want := range []string{
+		"\x1b]8;;vscode://file/src/my%20app/a.js:7\x1b\\/home/ubuntu/workspaces/a.js(7)\x1b]8;;\x1b\\",
+		"\x1b]8;;vscode://file/src/my%20app/b.js\x1b\\/home/ubuntu/workspaces/b.js\x1b]8;;\x1b\\.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in %q", want, got)
+		}
+	}
+}
+
+func TestLinkerSkipsEscapeSequencesAndOtherPaths(t *testing.T) {
+	l := New("/srv/app", "/local", "file://{{path}}")
+	in := []string{
+		"\x1b]0;/srv/app/title.txt",                          // window title, split across calls
+		"\x07/x/srv/app/a.go /srv/application/b.go /srv/app", // not under the base
+		"\x1b]8;;http://x\x1b\\/srv/app/c.go\x1b]8;;\x1b\\",  // linked by the program already
+	}
+	var got strings.Builder
+	for _, s := range in {
+		got.Write(l.Filter([]byte(s)))
+	}
+	if got.String() != strings.Join(in, "") {
+		t.Fatalf("output changed: %q", got.String())
+	}
+
+	out := string(l.Filter([]byte("\x1b[31m/srv/app/d.go\x1b[0m")))
+	if !strings.Contains(out, "\x1b]8;;file:///local/d.go\x1b\\/srv/app/d.go\x1b]8;;\x1b\\") {
+		t.Fatalf("colored path not linked: %q", out)
+	}
+}
+
+func TestLinkerLeavesPathsAtChunkEndUnlinked(t *testing.T) {
+	l := New("/srv/app", "/local", "file://{{path}}")
+	for _, chunk := range []string{"at /srv/app/src/Fo", "o.go:12\r\n", "at /srv/app/a.go:4", "2\r\n"} {
+		if out := string(l.Filter([]byte(chunk))); out != chunk {
+			t.Fatalf("%q: linked a possibly truncated path: %q", chunk, out)
+		}
+	}
+	out := string(l.Filter([]byte("at /srv/app/b.go:7\r\n")))
+	if !strings.Contains(out, "\x1b]8;;file:///local/b.go\x1b\\/srv/app/b.go:7\x1b]8;;\x1b\\\r\n") {
+		t.Fatalf("complete path not linked: %q", out)
+	}
+}
@@ -0,0 +1,136 @@
+// Package scrollback keeps the searchable output history of a PTY slot.
+// Output is stored as plain text (ANSI sequences stripped, carriage returns
+// applied); the newest part stays in memory and older lines spill to a file.
+package scrollback
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"make-sync/internal/util"
+)
+
+// Buffer is an io.Writer collecting terminal output. It is safe for
+// concurrent use.
+type Buffer struct {
+	mu        sync.Mutex
+	strip     *util.ANSIStripper
+	mem       []byte
+	memLimit  int64
+	total     int64
+	spillPath string
+	spill     *os.File
+	spillSize int64
+	pendingCR bool
+}
+
+// New creates a buffer keeping memLimit bytes in memory and up to total
+// bytes overall, the rest in spillPath. An empty spillPath keeps memory only.
+func New(spillPath string, memLimit, total int64) *Buffer {
+	if memLimit <= 0 {
+		memLimit = 1024 * 1024
+	}
+	if total < memLimit {
+		total = memLimit
+	}
+	return &Buffer{strip: util.NewANSIStripper(), memLimit: memLimit, total: total, spillPath: spillPath}
+}
+
+// Write appends terminal output to the buffer.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, c := range b.strip.Strip(p) {
+		if b.pendingCR {
+			b.pendingCR = false
+			if c != '\n' {
+				// a lone CR rewrites the current line (progress bars, prompts)
+				b.mem = b.mem[:bytes.LastIndexByte(b.mem, '\n')+1]
+			}
+		}
+		if c == '\r' {
+			b.pendingCR = true
+			continue
+		}
+		b.mem = append(b.mem, c)
+	}
+	if int64(len(b.mem)) > b.memLimit {
+		cut := len(b.mem) - int(b.memLimit)
+		// spill whole lines only
+		if nl := bytes.IndexByte(b.mem[cut:], '\n'); nl >= 0 {
+			cut += nl + 1
+		}
+		b.spillWrite(b.mem[:cut])
+		b.mem = append([]byte(nil), b.mem[cut:]...)
+	}
+	return len(p), nil
+}
+
+func (b *Buffer) spillWrite(p []byte) {
+	limit := b.total - b.memLimit
+	if b.spillPath == "" || limit <= 0 {
+		return
+	}
+	if b.spill == nil {
+		if err := os.MkdirAll(filepath.Dir(b.spillPath), 0755); err != nil {
+			return
+		}
+		f, err := os.OpenFile(b.spillPath, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0600)
+		if err != nil {
+			return
+		}
+		b.spill = f
+	}
+	n, _ := b.spill.Write(p)
+	b.spillSize += int64(n)
+	if b.spillSize <= limit {
+		return
+	}
+	// over the limit: keep the newest half so trimming stays infrequent
+	data, err := os.ReadFile(b.spillPath)
+	if err != nil {
+		return
+	}
+	keep := data[len(data)-int(limit/2):]
+	if nl := bytes.IndexByte(keep, '\n'); nl >= 0 {
+		keep = keep[nl+1:]
+	}
+	if err := b.spill.Truncate(0); err != nil {
+		return
+	}
+	b.spill.Seek(0, 0)
+	n, _ = b.spill.Write(keep)
+	b.spillSize = int64(n)
+}
+
+// Lines returns the whole history, oldest line first.
+func (b *Buffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var data []byte
+	if b.spill != nil {
+		data, _ = os.ReadFile(b.spillPath)
+	}
+	data = append(data, b.mem...)
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// Close drops the history and removes the spill file.
+func (b *Buffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mem = nil
+	if b.spill == nil {
+		return nil
+	}
+	b.spill.Close()
+	b.spill = nil
+	return os.Remove(b.spillPath)
+}
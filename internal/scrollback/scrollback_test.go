@@ -0,0 +1,50 @@
+package scrollback
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBufferStripsAndAppliesCarriageReturns(t *testing.T) {
+	b := New("", 1024, 1024)
+	b.Write([]byte("\x1b[32mok\x1b[0m\r\n"))
+	b.Write([]byte("progress 10%\rprogress 1"))
+	b.Write([]byte("00%\r"))
+	b.Write([]byte("\ndone\n"))
+	got := strings.Join(b.Lines(), "|")
+	if got != "ok|progress 100%|done" {
+		t.Fatalf("unexpected lines %q", got)
+	}
+}
+
+func TestBufferSpillsAndTrims(t *testing.T) {
+	spill := filepath.Join(t.TempDir(), "scrollback", "slot3.txt")
+	b := New(spill, 64, 512)
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(b, "line %03d\n", i)
+	}
+	if _, err := os.Stat(spill); err != nil {
+		t.Fatalf("expected spill file: %v", err)
+	}
+	lines := b.Lines()
+	if len(lines) == 0 || lines[len(lines)-1] != "line 099" {
+		t.Fatalf("newest line missing: %v", lines)
+	}
+	if lines[0] == "line 000" {
+		t.Fatalf("expected oldest lines to be trimmed past the size limit")
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] <= lines[i-1] {
+			t.Fatalf("lines out of order at %d: %q after %q", i, lines[i], lines[i-1])
+		}
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(spill); !os.IsNotExist(err) {
+		t.Fatalf("spill file not removed")
+	}
+}
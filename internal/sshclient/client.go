@@ -1028,6 +1028,21 @@ func (c *SSHClient) RunCommandWithOutput(cmd string) (string, error) {
 	return string(output), nil
 }
 
+// RunCommandWithIO executes a command with the given stdin and writes its
+// stdout/stderr to the provided writers, returning once the command exits.
+func (c *SSHClient) RunCommandWithIO(cmd string, stdin io.Reader, stdout, stderr io.Writer) error {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create session: %v", err)
+	}
+	defer session.Close()
+
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+	return session.Run(cmd)
+}
+
 // RunCommandWithStreaming executes a command and streams output in real-time
 func (c *SSHClient) RunCommandWithStreaming(cmd string, outputCallback func(string)) (string, error) {
 	session, err := c.client.NewSession()
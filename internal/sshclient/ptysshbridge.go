@@ -27,6 +27,8 @@ type PTYSSHBridge struct {
 
 	initialCommand string
 	postCommand    string
+	// background is set by StartInBackground
+	background bool
 
 	StdinMatcher   func([]byte) bool
 	StdinCallback  func([]byte)
@@ -64,6 +66,8 @@ type PTYSSHBridge struct {
 	stderrPipe io.Reader
 	// output tap receives stdout/stderr bytes regardless of outputDisabled
 	outputTap func([]byte, bool)
+	// outputFilter rewrites stdout before it reaches the terminal (path links)
+	outputFilter func([]byte) []byte
 	// reconnect state
 	reconnectMu    sync.Mutex
 	reconnecting   bool
@@ -73,6 +77,10 @@ type PTYSSHBridge struct {
 	// exiting indicates the bridge is in the process of normal exit/close
 	exiting   bool
 	exitingMu sync.Mutex
+
+	// pinned window size (split panes); zero follows the local terminal
+	sizeMu           sync.Mutex
+	pinCols, pinRows int
 }
 
 // cacheOutput adds output data to the cache with FIFO strategy (removes oldest data when full)
@@ -179,16 +187,28 @@ func NewPTYSSHBridgeWithCommandAndPost(sshClient *SSHClient, initialCommand stri
 	return bridge, nil
 }
 
+// StartInBackground starts the session paused; Resume attaches it to the
+// terminal and shows the output cached meanwhile.
+func (bridge *PTYSSHBridge) StartInBackground() error {
+	bridge.outputMu.Lock()
+	bridge.outputDisabled = true
+	bridge.outputMu.Unlock()
+	bridge.background = true
+	return bridge.StartInteractiveShell()
+}
+
 // StartInteractiveShell starts an interactive shell session
 func (bridge *PTYSSHBridge) StartInteractiveShell() error {
 
 	// Best-effort: set stdin into raw mode for interactive sessions and keep
 	// the restore function so Pause/Resume/Close can restore it.
-	oldstate, err := term.MakeRaw(int(os.Stdin.Fd()))
-	if err != nil {
-		return fmt.Errorf("failed to snapshot terminal state: %v", err)
+	if !bridge.background {
+		oldstate, err := term.MakeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			return fmt.Errorf("failed to snapshot terminal state: %v", err)
+		}
+		bridge.oldState = oldstate
 	}
-	bridge.oldState = oldstate
 
 	cols, rows := 80, 24
 
@@ -223,7 +243,9 @@ func (bridge *PTYSSHBridge) StartInteractiveShell() error {
 	log.Println("StartInteractiveShell : Started interactive shell session")
 	// start readers: output readers long-lived, input reader can be canceled on Pause
 	bridge.ProcessPTYReadOutput(outCtx)
-	bridge.ProcessPTYReadInput(inCtx)
+	if !bridge.background {
+		bridge.ProcessPTYReadInput(inCtx)
+	}
 
 	// Start a small resize watcher that polls the terminal size and applies
 	// WindowChange on the remote session when it changes. This is a simple
@@ -239,6 +261,10 @@ func (bridge *PTYSSHBridge) StartInteractiveShell() error {
 				if bridge.sshSession == nil {
 					continue
 				}
+				if bridge.sizePinned() {
+					prevW, prevH = 0, 0
+					continue
+				}
 				if w, h, err := getTerminalSizeFallback(); err == nil {
 					if w != prevW || h != prevH {
 						_ = bridge.sshSession.WindowChange(h, w)
@@ -477,12 +503,17 @@ func (bridge *PTYSSHBridge) ProcessPTYReadOutput(ctx context.Context) error {
 				if n > 0 {
 					bridge.outputMu.Lock()
 					disabled := bridge.outputDisabled
+					filter := bridge.outputFilter
 					bridge.outputMu.Unlock()
+					shown := buf[:n]
+					if filter != nil {
+						shown = filter(shown)
+					}
 					if !disabled {
-						_, _ = os.Stdout.Write(buf[:n])
+						_, _ = os.Stdout.Write(shown)
 					}
 					// Always cache output as history buffer
-					bridge.cacheOutput(buf[:n])
+					bridge.cacheOutput(shown)
 					// always pass to tap regardless of disabled
 					if bridge.outputTap != nil {
 						data := make([]byte, n)
@@ -574,6 +605,37 @@ func (bridge *PTYSSHBridge) Pause() error {
 	return nil
 }
 
+// SetWindowSize pins the remote PTY to cols x rows (e.g. a split pane) so it
+// no longer follows the local terminal size. cols or rows <= 0 unpins it and
+// applies the terminal size again.
+func (bridge *PTYSSHBridge) SetWindowSize(cols, rows int) error {
+	pin := cols > 0 && rows > 0
+	bridge.sizeMu.Lock()
+	if pin {
+		bridge.pinCols, bridge.pinRows = cols, rows
+	} else {
+		bridge.pinCols, bridge.pinRows = 0, 0
+	}
+	bridge.sizeMu.Unlock()
+	if bridge.sshSession == nil {
+		return fmt.Errorf("no SSH session")
+	}
+	if !pin {
+		w, h, err := getTerminalSizeFallback()
+		if err != nil {
+			return err
+		}
+		cols, rows = w, h
+	}
+	return bridge.sshSession.WindowChange(rows, cols)
+}
+
+func (bridge *PTYSSHBridge) sizePinned() bool {
+	bridge.sizeMu.Lock()
+	defer bridge.sizeMu.Unlock()
+	return bridge.pinCols > 0
+}
+
 // Resume restarts stdin/output
 func (bridge *PTYSSHBridge) Resume() error {
 
@@ -741,6 +803,14 @@ func (bridge *PTYSSHBridge) SetOutputTap(fn func([]byte, bool)) {
 	bridge.outputMu.Unlock()
 }
 
+// SetOutputFilter registers a filter applied to stdout shown on the terminal
+// (and cached for Resume). The tap still receives the raw output.
+func (bridge *PTYSSHBridge) SetOutputFilter(fn func([]byte) []byte) {
+	bridge.outputMu.Lock()
+	bridge.outputFilter = fn
+	bridge.outputMu.Unlock()
+}
+
 func (b *PTYSSHBridge) GetStdinObserver() func([]byte) {
 	b.mu.RLock()
 	o := b.StdinObserver
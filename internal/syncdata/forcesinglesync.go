@@ -73,6 +73,10 @@ func ForceSingleSyncMenu(cfg *config.Config, localRoot string) {
 		absRoot = abs
 	}
 
+	// remote sizes per normalized prefix, filled by "Show remote sizes" or
+	// fetched on demand before a download; the menu says when some are missing
+	duByPrefix := map[string]RemoteDuPrefix{}
+
 	for {
 		// top: choose between Download / Upload / Back
 		choice, err := tui.ShowMenuWithPrints([]string{"Download", "Upload", "Back"}, "Single Sync")
@@ -90,9 +94,19 @@ func ForceSingleSyncMenu(cfg *config.Config, localRoot string) {
 		// folder selection menu
 		for {
 			// construct folder choices
-			items := make([]string, 0, len(registered)+3)
-			items = append(items, registered...)
+			items := make([]string, 0, len(registered)+4)
+			labelToPath := make(map[string]string, len(registered))
+			sizesItem := "Show remote sizes"
+			for _, r := range registered {
+				label := registeredPathLabel(cfg, absRoot, r, duByPrefix)
+				if label == r {
+					sizesItem = "Show remote sizes (not loaded yet)"
+				}
+				labelToPath[label] = r
+				items = append(items, label)
+			}
 			items = append(items, "----------")
+			items = append(items, sizesItem)
 			// Add a manual refresh action so users can reload `make-sync.yaml` without
 			// restarting the app.
 			items = append(items, "Refresh sync folders")
@@ -111,6 +125,28 @@ func ForceSingleSyncMenu(cfg *config.Config, localRoot string) {
 				break // go back to Download/Upload selection
 			}
 
+			if path, ok := labelToPath[folderChoice]; ok {
+				folderChoice = path
+			}
+
+			if folderChoice == sizesItem {
+				all := normalizeToRelativePrefixes(absRoot, registered)
+				if len(all) == 0 {
+					util.Default.Println("ℹ️  No registered paths found.")
+					continue
+				}
+				report, derr := runRemoteDuForMenu(cfg, all)
+				if derr != nil {
+					util.Default.Printf("❌ Remote du failed: %v\n", derr)
+					continue
+				}
+				for _, p := range report.Prefixes {
+					duByPrefix[normalizeManualPrefix(p.Prefix)] = p
+				}
+				printDuReport(report, cfg.Devsync.ManualTransferWarnBytes())
+				continue
+			}
+
 			// Handle Refresh action
 			if folderChoice == "Refresh sync folders" {
 				newCfg, cerr := config.LoadAndRenderConfig()
@@ -180,6 +216,10 @@ func ForceSingleSyncMenu(cfg *config.Config, localRoot string) {
 				switch choice {
 				case "Download":
 					util.Default.Printf("🔁 Running Download (%s) for prefixes: %v\n", modeChoice, prefixes)
+					if !confirmDownloadSize(cfg, prefixes, duByPrefix) {
+						util.Default.Println("ℹ️  Download cancelled")
+						return
+					}
 					// Single-sync indexing intentionally bypasses remote .sync_ignore.
 					if err := runRemoteIndexingForPull(cfg, true, prefixes); err != nil {
 						util.Default.Printf("❌ Remote indexing (safe_pull) failed: %v\n", err)
@@ -264,6 +304,34 @@ func ForceSingleSyncMenu(cfg *config.Config, localRoot string) {
 // (with fallback) using remote detection, uploads agent and config, and runs
 // remote indexing so the DB is fresh before pulling.
 func runRemoteIndexingForPull(cfg *config.Config, bypassIgnore bool, prefixes []string) error {
+	agentPath, err := buildAgentForSingleSync(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Run remote indexing via existing flow (handles upload agent+config, execute, etc.)
+	_, out, err := RunAgentIndexingFlow(cfg, []string{agentPath}, bypassIgnore, prefixes)
+	if err != nil {
+		util.Default.Printf("🔍 Remote output (partial): %s\n", out)
+		return err
+	}
+	util.Default.Printf("✅ Remote indexing finished.\n")
+	return nil
+}
+
+// runRemoteDuForMenu builds and deploys the agent, then asks it for the disk
+// usage of the given prefixes.
+func runRemoteDuForMenu(cfg *config.Config, prefixes []string) (*RemoteDuReport, error) {
+	agentPath, err := buildAgentForSingleSync(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return RunAgentDuFlow(cfg, []string{agentPath}, prefixes)
+}
+
+// buildAgentForSingleSync builds the agent for the remote target using remote
+// detection, falling back to a prebuilt binary, and returns its local path.
+func buildAgentForSingleSync(cfg *config.Config) (string, error) {
 	// Determine target OS
 	targetOS := cfg.Devsync.OSTarget
 	if strings.TrimSpace(targetOS) == "" {
@@ -278,13 +346,13 @@ func runRemoteIndexingForPull(cfg *config.Config, bypassIgnore bool, prefixes []
 	// Get project root - handles both development (go run) and production modes
 	projectRoot, err := util.GetProjectRoot()
 	if err != nil {
-		return fmt.Errorf("failed to get project root: %v", err)
+		return "", fmt.Errorf("failed to get project root: %v", err)
 	}
 
 	// Connect SSH for remote detection (adapter used by build)
 	sshClient, err := ConnectSSH(cfg)
 	if err != nil {
-		return err
+		return "", err
 	}
 	// Ensure close after build
 	defer sshClient.Close()
@@ -306,19 +374,11 @@ func runRemoteIndexingForPull(cfg *config.Config, bypassIgnore bool, prefixes []
 			util.Default.Printf("ℹ️  Using fallback agent binary: %s\n", fallbackPath)
 			agentPath = fallbackPath
 		} else {
-			return bErr
+			return "", bErr
 		}
 	}
 	util.Default.Printf("✅ Agent ready: %s\n", agentPath)
-
-	// Run remote indexing via existing flow (handles upload agent+config, execute, etc.)
-	_, out, err := RunAgentIndexingFlow(cfg, []string{agentPath}, bypassIgnore, prefixes)
-	if err != nil {
-		util.Default.Printf("🔍 Remote output (partial): %s\n", out)
-		return err
-	}
-	util.Default.Printf("✅ Remote indexing finished.\n")
-	return nil
+	return agentPath, nil
 }
 
 // normalizeToRelativePrefixes converts a list of configured paths (absolute or relative)
@@ -367,3 +427,109 @@ func normalizeToRelativePrefixes(absRoot string, paths []string) []string {
 	}
 	return out
 }
+
+// registeredPathLabel renders a folder menu entry, appending the remote size
+// when known and a warning marker when it exceeds the configured threshold.
+func registeredPathLabel(cfg *config.Config, absRoot, path string, duByPrefix map[string]RemoteDuPrefix) string {
+	rels := normalizeToRelativePrefixes(absRoot, []string{path})
+	if len(rels) == 0 {
+		return path
+	}
+	du, ok := duByPrefix[normalizeManualPrefix(rels[0])]
+	if !ok {
+		return path
+	}
+	if du.Error != "" {
+		return fmt.Sprintf("%s  [remote: not found]", path)
+	}
+	label := fmt.Sprintf("%s  [%s, %d files]", path, FormatByteSize(du.Bytes), du.Files)
+	if warn := cfg.Devsync.ManualTransferWarnBytes(); warn > 0 && du.Bytes > warn {
+		label += " ⚠️"
+	}
+	return label
+}
+
+// printDuReport prints totals, the biggest subdirectories and the largest
+// files of each prefix in the report.
+func printDuReport(report *RemoteDuReport, warnBytes int64) {
+	const maxRows = 5
+	for _, p := range report.Prefixes {
+		name := p.Prefix
+		if name == "" {
+			name = "."
+		}
+		if p.Error != "" {
+			util.Default.Printf("📁 %s: %s\n", name, p.Error)
+			continue
+		}
+		util.Default.Printf("📁 %s: %s in %d files, %d dirs\n", name, FormatByteSize(p.Bytes), p.Files, p.Dirs)
+		if warnBytes > 0 && p.Bytes > warnBytes {
+			util.Default.Printf("   ⚠️  exceeds warning threshold (%s)\n", FormatByteSize(warnBytes))
+		}
+		for i, d := range p.Subdirs {
+			if i >= maxRows {
+				util.Default.Printf("   … %d more\n", len(p.Subdirs)-maxRows)
+				break
+			}
+			util.Default.Printf("   %-10s %6d files  %s/\n", FormatByteSize(d.Bytes), d.Files, d.Path)
+		}
+		for i, f := range p.Largest {
+			if i >= maxRows {
+				break
+			}
+			if i == 0 {
+				util.Default.Println("   largest files:")
+			}
+			util.Default.Printf("   %-10s %s\n", FormatByteSize(f.Size), f.Rel)
+		}
+	}
+}
+
+// confirmDownloadSize asks for confirmation when the remote size of the
+// selected prefixes exceeds the warning threshold. It runs before indexing, so
+// a download the user turns down does not index the remote first.
+// Sizes not yet known are fetched from an agent left by an earlier run, or
+// from a freshly deployed one when there is none.
+func confirmDownloadSize(cfg *config.Config, prefixes []string, duByPrefix map[string]RemoteDuPrefix) bool {
+	warn := cfg.Devsync.ManualTransferWarnBytes()
+	if warn <= 0 {
+		return true
+	}
+
+	var missing []string
+	for _, p := range prefixes {
+		if _, ok := duByPrefix[normalizeManualPrefix(p)]; !ok {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		report, err := RunDeployedAgentDu(cfg, missing)
+		if err != nil {
+			report, err = runRemoteDuForMenu(cfg, missing)
+		}
+		if err != nil {
+			util.Default.Printf("⚠️  Could not determine remote size: %v\n", err)
+			return true
+		}
+		for _, p := range report.Prefixes {
+			duByPrefix[normalizeManualPrefix(p.Prefix)] = p
+		}
+	}
+
+	var total, files int64
+	for _, p := range prefixes {
+		du := duByPrefix[normalizeManualPrefix(p)]
+		total += du.Bytes
+		files += du.Files
+	}
+	if total <= warn {
+		return true
+	}
+
+	util.Default.Printf("⚠️  Selection is %s in %d files (threshold %s)\n", FormatByteSize(total), files, FormatByteSize(warn))
+	choice, err := tui.ShowMenuWithPrints([]string{"Continue download", "Cancel"}, "? Single Sync : Large download, continue?")
+	if err != nil {
+		return false
+	}
+	return choice == "Continue download"
+}
@@ -48,6 +48,15 @@ func (c *IgnoreCache) Match(path string, isDir bool) bool {
 		return true
 	}
 
+	// Per-mapping ignores (devsync.mappings)
+	absPath := path
+	if !filepath.IsAbs(absPath) {
+		absPath = filepath.Join(c.Root, absPath)
+	}
+	if matchMappingIgnores(absPath, isDir) {
+		return true
+	}
+
 	dir := path
 	if !isDir {
 		dir = filepath.Dir(path)
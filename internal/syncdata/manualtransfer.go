@@ -732,7 +732,9 @@ func CompareAndDownloadManualTransfer(cfg *config.Config, localRoot string, pref
 	return CompareAndDownloadByHashWithFilter(cfg, localRoot, prefixes)
 }
 
-// CompareAndDownloadManualTransferParallel downloads files with bounded concurrency (max 5 parallel transfers)
+// CompareAndDownloadManualTransferParallel downloads changed files in a single
+// agent pack stream, falling back to per-file downloads with bounded
+// concurrency for anything the stream did not deliver.
 // This is the parallel version of CompareAndDownloadManualTransfer
 func CompareAndDownloadManualTransferParallel(cfg *config.Config, localRoot string, prefixes []string) ([]string, error) {
 	// if prefixes empty, call existing
@@ -846,13 +848,7 @@ func CompareAndDownloadManualTransferParallel(cfg *config.Config, localRoot stri
 	if concurrency <= 0 {
 		concurrency = 5
 	}
-	var downloadTasks []util.ConcurrentTask
-
-	// worker slot channel: provides stable slot numbers 1..concurrency
-	slotCh := make(chan int, concurrency)
-	for i := 1; i <= concurrency; i++ {
-		slotCh <- i
-	}
+	var pending []string
 
 	for _, rel := range rels {
 		rm := remoteByRel[rel]
@@ -887,25 +883,7 @@ func CompareAndDownloadManualTransferParallel(cfg *config.Config, localRoot stri
 		info, statErr := os.Stat(localPath)
 		if statErr != nil {
 			// File doesn't exist, needs download
-			remotePath := buildRemotePath(cfg, relNorm)
-			rp := remotePath
-			lp := localPath
-			downloadTasks = append(downloadTasks, func() error {
-				id := <-slotCh
-				defer func() { slotCh <- id }()
-				util.Default.Printf("⬇️  %d -> Downloading %s -> %s\n", id, rp, lp)
-				if err := sshCli.DownloadFile(lp, rp); err != nil {
-					util.Default.Printf("❌ %d Failed to download %s: %v\n", id, rp, err)
-					mu.Lock()
-					downloadErrors++
-					mu.Unlock()
-					return err
-				}
-				mu.Lock()
-				downloaded = append(downloaded, lp)
-				mu.Unlock()
-				return nil
-			})
+			pending = append(pending, relNorm)
 			continue
 		}
 		if info.IsDir() {
@@ -925,25 +903,7 @@ func CompareAndDownloadManualTransferParallel(cfg *config.Config, localRoot stri
 
 		if strings.TrimSpace(rm.Hash) == "" || rm.Hash != localHash {
 			// File exists but different, needs download
-			remotePath := buildRemotePath(cfg, relNorm)
-			rp := remotePath
-			lp := localPath
-			downloadTasks = append(downloadTasks, func() error {
-				id := <-slotCh
-				defer func() { slotCh <- id }()
-				util.Default.Printf("⬇️  %d -> Downloading %s -> %s\n", id, rp, lp)
-				if err := sshCli.DownloadFile(lp, rp); err != nil {
-					util.Default.Printf("❌ %d Failed to download %s: %v\n", id, rp, err)
-					mu.Lock()
-					downloadErrors++
-					mu.Unlock()
-					return err
-				}
-				mu.Lock()
-				downloaded = append(downloaded, lp)
-				mu.Unlock()
-				return nil
-			})
+			pending = append(pending, relNorm)
 			continue
 		}
 
@@ -952,7 +912,63 @@ func CompareAndDownloadManualTransferParallel(cfg *config.Config, localRoot stri
 		mu.Unlock()
 	}
 
-	// Execute all download tasks with bounded concurrency
+	// Request all pending files from the agent in one pack stream; anything it
+	// could not deliver (or an agent without `pack`) falls back to per-file
+	// downloads below.
+	var fallback []string
+	if len(pending) > 0 {
+		util.Default.Printf("📦 Requesting %d file(s) from agent in one stream...\n", len(pending))
+		results, packErr := RemoteRunAgentPack(sshCli, cfg, absRoot, prefixes, pending)
+		if packErr != nil {
+			util.Default.Printf("⚠️  Agent pack stream failed, falling back to per-file downloads: %v\n", packErr)
+		}
+		for _, rel := range pending {
+			res, ok := results[rel]
+			switch {
+			case ok && res.Status == "ok":
+				util.Default.Printf("✅ %s (%d bytes)\n", rel, res.Size)
+				downloaded = append(downloaded, res.LocalPath)
+			case ok && res.Status == "skip":
+				util.Default.Printf("⏭️  %s skipped by agent: %s\n", rel, res.Reason)
+				skippedIgnored++
+			case ok:
+				util.Default.Printf("⚠️  %s failed in pack stream: %s\n", rel, res.Reason)
+				fallback = append(fallback, rel)
+			default:
+				fallback = append(fallback, rel)
+			}
+		}
+	}
+
+	var downloadTasks []util.ConcurrentTask
+
+	// worker slot channel: provides stable slot numbers 1..concurrency
+	slotCh := make(chan int, concurrency)
+	for i := 1; i <= concurrency; i++ {
+		slotCh <- i
+	}
+	for _, relNorm := range fallback {
+		rp := buildRemotePath(cfg, relNorm)
+		lp := filepath.Join(absRoot, filepath.FromSlash(relNorm))
+		downloadTasks = append(downloadTasks, func() error {
+			id := <-slotCh
+			defer func() { slotCh <- id }()
+			util.Default.Printf("⬇️  %d -> Downloading %s -> %s\n", id, rp, lp)
+			if err := sshCli.DownloadFile(lp, rp); err != nil {
+				util.Default.Printf("❌ %d Failed to download %s: %v\n", id, rp, err)
+				mu.Lock()
+				downloadErrors++
+				mu.Unlock()
+				return err
+			}
+			mu.Lock()
+			downloaded = append(downloaded, lp)
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	// Execute fallback download tasks with bounded concurrency
 	if err := util.RunConcurrent(downloadTasks, concurrency); err != nil {
 		util.Default.Printf("⚠️  Some downloads failed: %v\n", err)
 	}
@@ -176,3 +176,23 @@ func TestManualTransferNegationCanPassSingleFileUnderIgnoredDir(t *testing.T) {
 		t.Fatalf("expected non-negated file under test/ to stay ignored")
 	}
 }
+
+func TestBuildAgentCommand(t *testing.T) {
+	tests := []struct {
+		remoteDir, osTarget, sub string
+		prefixes                 []string
+		want                     string
+	}{
+		{"/srv/app/.sync_temp", "linux", "pack --files-from -", nil,
+			`cd '/srv/app/' && '/srv/app/.sync_temp/agent' pack --files-from -`},
+		{"/srv/app", "linux", "prune --dry-run", []string{"src", "it's"},
+			`cd '/srv/app' && '/srv/app/.sync_temp/agent' prune --dry-run --manual-transfer 'src,it'\''s'`},
+		{"C:/app/.sync_temp", "windows", "indexing", []string{"src"},
+			`cmd.exe /C cd /d "C:\app" && "C:\app\.sync_temp\agent" indexing --manual-transfer src`},
+	}
+	for _, tt := range tests {
+		if got := buildAgentCommand(tt.remoteDir, tt.osTarget, "agent", tt.sub, tt.prefixes); got != tt.want {
+			t.Errorf("got  %s\nwant %s", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package syncdata
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"make-sync/internal/config"
+	"make-sync/internal/sshclient"
+	"make-sync/internal/util"
+)
+
+var mappingIgnoreState struct {
+	mu    sync.RWMutex
+	roots map[string][]string
+}
+
+// UseMappingIgnores registers the ignores of cfg's devsync.mappings so every
+// IgnoreCache applies them, relative to their mapping's local root, on top
+// of .sync_ignore. Configs without mappings clear the registry.
+func UseMappingIgnores(cfg *config.Config) {
+	roots := make(map[string][]string)
+	if cfg != nil && cfg.HasMappings() {
+		for _, m := range cfg.PathMappings() {
+			if len(m.Ignores) > 0 {
+				roots[m.LocalPath] = m.Ignores
+			}
+		}
+	}
+	mappingIgnoreState.mu.Lock()
+	mappingIgnoreState.roots = roots
+	mappingIgnoreState.mu.Unlock()
+}
+
+// matchMappingIgnores reports whether absPath is ignored by the mapping
+// ignores of the deepest registered root containing it.
+func matchMappingIgnores(absPath string, isDir bool) bool {
+	mappingIgnoreState.mu.RLock()
+	roots := mappingIgnoreState.roots
+	mappingIgnoreState.mu.RUnlock()
+	if len(roots) == 0 {
+		return false
+	}
+
+	var rules []string
+	rel, bestLen := "", -1
+	for root, r := range roots {
+		rr, err := filepath.Rel(root, absPath)
+		if err != nil || rr == ".." || strings.HasPrefix(rr, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(root) > bestLen {
+			rules, rel, bestLen = r, rr, len(root)
+		}
+	}
+	if bestLen < 0 || rel == "." {
+		return false
+	}
+	rel = normalizeManualPrefix(rel)
+	matched := matchManualIgnoreRules(rel, isDir, rules)
+	// keep walking into ignored dirs that hold negated descendants
+	if matched && isDir && manualIgnoreDirHasNegationDescendant(rel, rules) {
+		return false
+	}
+	return matched
+}
+
+// runPullPerMapping runs RunPullWithMode once for every mapping of cfg.
+func runPullPerMapping(cfg *config.Config, sshClient *sshclient.SSHClient, mode string) SafePullResult {
+	UseMappingIgnores(cfg)
+	all := SafePullResult{Success: true}
+	for _, m := range cfg.PathMappings() {
+		util.Default.Printf("📁 Mapping %s: %s ⇄ %s\n", m.Label(), m.LocalPath, m.RemotePath)
+		res := RunPullWithMode(cfg.ForMapping(m), sshClient, mode)
+		all.Output += res.Output
+		all.DownloadedFiles = append(all.DownloadedFiles, res.DownloadedFiles...)
+		if !res.Success {
+			all.Success = false
+			all.Error = res.Error
+			return all
+		}
+	}
+	return all
+}
+
+// runPushPerMapping runs RunPushWithMode once for every mapping of cfg.
+func runPushPerMapping(cfg *config.Config, sshClient *sshclient.SSHClient, mode string) SafePushResult {
+	UseMappingIgnores(cfg)
+	all := SafePushResult{Success: true}
+	for _, m := range cfg.PathMappings() {
+		util.Default.Printf("📁 Mapping %s: %s ⇄ %s\n", m.Label(), m.LocalPath, m.RemotePath)
+		res := RunPushWithMode(cfg.ForMapping(m), sshClient, mode)
+		all.Output += res.Output
+		all.UploadedFiles = append(all.UploadedFiles, res.UploadedFiles...)
+		if !res.Success {
+			all.Success = false
+			all.Error = res.Error
+			return all
+		}
+	}
+	return all
+}
@@ -0,0 +1,172 @@
+package syncdata
+
+import (
+	"archive/tar"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"make-sync/internal/config"
+	"make-sync/internal/sshclient"
+	"make-sync/internal/util"
+)
+
+// PackFileResult is the per-file outcome of a remote `agent pack` stream.
+type PackFileResult struct {
+	Rel       string
+	LocalPath string
+	Size      int64
+	// Status is "ok", "skip" or "error"; Reason carries the agent's skip
+	// reason or error text.
+	Status string
+	Reason string
+}
+
+// RemoteRunAgentPack requests rels from the remote agent as a single tar
+// stream and extracts them below absRoot. Only requested paths are written;
+// each file is written to a temp file and renamed into place. The returned
+// map holds one result per requested rel; rels the agent never reported
+// (e.g. an older agent without `pack`) are absent so callers can fall back
+// to per-file downloads.
+func RemoteRunAgentPack(client *sshclient.SSHClient, cfg *config.Config, absRoot string, prefixes, rels []string) (map[string]*PackFileResult, error) {
+	remotePath := cfg.Devsync.Auth.RemotePath
+	if remotePath == "" {
+		return nil, fmt.Errorf("remote path is not configured")
+	}
+	osTarget := strings.ToLower(strings.TrimSpace(cfg.Devsync.OSTarget))
+	remoteSyncTemp := filepath.Join(remotePath, ".sync_temp")
+	if strings.Contains(osTarget, "win") {
+		remoteSyncTemp = filepath.ToSlash(remoteSyncTemp)
+	}
+
+	localConfig, err := config.GetOrCreateLocalConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local config: %v", err)
+	}
+	cmd := buildAgentCommand(remoteSyncTemp, osTarget, localConfig.GetAgentBinaryName(osTarget), "pack --files-from -", prefixes)
+
+	requested := make(map[string]struct{}, len(rels))
+	for _, r := range rels {
+		requested[filepath.ToSlash(r)] = struct{}{}
+	}
+
+	results := map[string]*PackFileResult{}
+	var mu sync.Mutex
+	setResult := func(r *PackFileResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		// a local extraction failure overrides the agent's "ok"
+		if prev, ok := results[r.Rel]; ok && prev.Status == "error" {
+			return
+		}
+		results[r.Rel] = r
+	}
+
+	// stderr carries PACK|<status>|<rel>|<size or reason> lines
+	stderrR, stderrW := io.Pipe()
+	reportDone := make(chan struct{})
+	go func() {
+		defer close(reportDone)
+		scanner := bufio.NewScanner(stderrR)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			parts := strings.SplitN(line, "|", 4)
+			if len(parts) == 4 && parts[0] == "PACK" {
+				// "ok" entries are confirmed by the tar extractor instead
+				if _, ok := requested[parts[2]]; ok && parts[1] != "ok" {
+					setResult(&PackFileResult{Rel: parts[2], Status: parts[1], Reason: parts[3]})
+				}
+				continue
+			}
+			if line != "" {
+				util.Default.Printf("⚠️  agent pack: %s\n", line)
+			}
+		}
+	}()
+
+	stdoutR, stdoutW := io.Pipe()
+	extractDone := make(chan error, 1)
+	go func() {
+		extractDone <- extractPackStream(stdoutR, absRoot, requested, setResult)
+		// keep draining so the SSH session never blocks on a full pipe
+		_, _ = io.Copy(io.Discard, stdoutR)
+	}()
+
+	stdin := strings.NewReader(strings.Join(rels, "\n") + "\n")
+	runErr := client.RunCommandWithIO(cmd, stdin, stdoutW, stderrW)
+	stdoutW.CloseWithError(runErr)
+	stderrW.Close()
+	extractErr := <-extractDone
+	<-reportDone
+
+	if runErr != nil {
+		return results, fmt.Errorf("remote pack failed: %v", runErr)
+	}
+	if extractErr != nil {
+		return results, extractErr
+	}
+	return results, nil
+}
+
+// extractPackStream reads the tar stream and writes requested entries below absRoot.
+func extractPackStream(r io.Reader, absRoot string, requested map[string]struct{}, setResult func(*PackFileResult)) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read pack stream: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		rel := filepath.ToSlash(hdr.Name)
+		if _, ok := requested[rel]; !ok {
+			// never write paths we did not ask for
+			continue
+		}
+		localPath := filepath.Join(absRoot, filepath.FromSlash(rel))
+		if err := writePackedFile(localPath, tr, os.FileMode(hdr.Mode).Perm()); err != nil {
+			setResult(&PackFileResult{Rel: rel, LocalPath: localPath, Status: "error", Reason: err.Error()})
+			continue
+		}
+		_ = os.Chtimes(localPath, hdr.ModTime, hdr.ModTime)
+		setResult(&PackFileResult{Rel: rel, LocalPath: localPath, Size: hdr.Size, Status: "ok"})
+	}
+}
+
+// writePackedFile writes r to a temp file next to path and renames it into place.
+func writePackedFile(path string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if perm == 0 {
+		perm = 0644
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".pack-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	_ = os.Chmod(tmp.Name(), perm)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
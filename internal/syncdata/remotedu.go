@@ -0,0 +1,115 @@
+package syncdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"make-sync/internal/config"
+	"make-sync/internal/sshclient"
+	"make-sync/internal/util"
+)
+
+// RemoteDuFile mirrors the agent's du file entry.
+type RemoteDuFile struct {
+	Rel  string `json:"rel"`
+	Size int64  `json:"size"`
+}
+
+// RemoteDuDir mirrors the agent's per-directory breakdown entry.
+type RemoteDuDir struct {
+	Path  string `json:"path"`
+	Files int64  `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// RemoteDuPrefix is the remote usage summary of one manual-transfer prefix.
+type RemoteDuPrefix struct {
+	Prefix  string         `json:"prefix"`
+	Files   int64          `json:"files"`
+	Dirs    int64          `json:"dirs"`
+	Bytes   int64          `json:"bytes"`
+	Largest []RemoteDuFile `json:"largest"`
+	Subdirs []RemoteDuDir  `json:"subdirs"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// RemoteDuReport is the JSON document printed by `agent du`.
+type RemoteDuReport struct {
+	Prefixes []RemoteDuPrefix `json:"prefixes"`
+}
+
+// RemoteRunAgentDu runs `agent du` for the given prefixes on an already
+// deployed agent. remoteDir is the remote .sync_temp directory.
+func RemoteRunAgentDu(client *sshclient.SSHClient, remoteDir, osTarget string, prefixes []string) (*RemoteDuReport, error) {
+	localConfig, err := config.GetOrCreateLocalConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local config: %v", err)
+	}
+	cmd := buildAgentCommand(remoteDir, osTarget, localConfig.GetAgentBinaryName(osTarget), "du", prefixes)
+
+	out, err := client.RunCommandWithOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("remote du failed: %v", err)
+	}
+	return parseDuOutput(out)
+}
+
+// parseDuOutput extracts the JSON report line from the agent output.
+func parseDuOutput(out string) (*RemoteDuReport, error) {
+	lines := strings.Split(strings.ReplaceAll(out, "\r\n", "\n"), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		ln := strings.TrimSpace(string(util.StripANSIEscapes([]byte(lines[i]))))
+		if !strings.HasPrefix(ln, "{") {
+			continue
+		}
+		var report RemoteDuReport
+		if err := json.Unmarshal([]byte(ln), &report); err != nil {
+			return nil, fmt.Errorf("failed to parse du report: %v", err)
+		}
+		return &report, nil
+	}
+	return nil, fmt.Errorf("no du report in agent output")
+}
+
+// RunAgentDuFlow deploys the agent (like RunAgentIndexingFlow) and returns
+// its du report for the given prefixes.
+func RunAgentDuFlow(cfg *config.Config, localCandidates []string, prefixes []string) (*RemoteDuReport, error) {
+	sshCli, remoteSyncTemp, osTarget, err := deployAgentForFlow(cfg, localCandidates)
+	if err != nil {
+		return nil, err
+	}
+	defer sshCli.Close()
+	return RemoteRunAgentDu(sshCli, remoteSyncTemp, osTarget, prefixes)
+}
+
+// RunDeployedAgentDu runs du on the agent left behind by a previous flow
+// (e.g. the indexing run that precedes every Single Sync transfer).
+func RunDeployedAgentDu(cfg *config.Config, prefixes []string) (*RemoteDuReport, error) {
+	remotePath := cfg.Devsync.Auth.RemotePath
+	if remotePath == "" {
+		return nil, fmt.Errorf("remote path is not configured")
+	}
+	osTarget := strings.ToLower(strings.TrimSpace(cfg.Devsync.OSTarget))
+	sshCli, err := ConnectSSH(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer sshCli.Close()
+	return RemoteRunAgentDu(sshCli, filepath.ToSlash(filepath.Join(remotePath, ".sync_temp")), osTarget, prefixes)
+}
+
+// FormatByteSize renders n using binary units (e.g. "1.5 GB").
+func FormatByteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,32 @@
+package syncdata
+
+import "testing"
+
+func TestParseDuOutput(t *testing.T) {
+	out := "\r\x1b[K🔧 Using working directory from config: /srv/app\n" +
+		`{"prefixes":[{"prefix":"vendor","files":3,"dirs":1,"bytes":2048,"largest":[{"rel":"vendor/a","size":1024}],"subdirs":[{"path":"lib","files":2,"bytes":1536}]}]}` + "\r\n"
+	report, err := parseDuOutput(out)
+	if err != nil {
+		t.Fatalf("parseDuOutput failed: %v", err)
+	}
+	if len(report.Prefixes) != 1 || report.Prefixes[0].Bytes != 2048 || report.Prefixes[0].Subdirs[0].Path != "lib" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if _, err := parseDuOutput("no json here\n"); err == nil {
+		t.Fatalf("expected error for output without report")
+	}
+}
+
+func TestFormatByteSize(t *testing.T) {
+	cases := map[int64]string{
+		512:                    "512 B",
+		1536:                   "1.5 KB",
+		5 * 1024 * 1024 * 1024: "5.0 GB",
+	}
+	for in, want := range cases {
+		if got := FormatByteSize(in); got != want {
+			t.Fatalf("FormatByteSize(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
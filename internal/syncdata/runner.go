@@ -120,7 +120,7 @@ type RemoteAgentConfig struct {
 func generateRemoteConfig(cfg *config.Config) *RemoteAgentConfig {
 	remoteConfig := &RemoteAgentConfig{}
 	remoteConfig.Devsync.Ignores = cfg.Devsync.Ignores
-	remoteConfig.Devsync.AgentWatchs = cfg.Devsync.AgentWatchs
+	remoteConfig.Devsync.AgentWatchs = cfg.AgentWatchPaths()
 	remoteConfig.Devsync.ManualTransfer = cfg.Devsync.ManualTransfer
 	remoteConfig.Devsync.WorkingDir = cfg.Devsync.Auth.RemotePath
 
@@ -145,6 +145,10 @@ func uploadConfigToRemote(client *sshclient.SSHClient, cfg *config.Config, remot
 			remoteConfig.Devsync.Ignores = ignores
 		}
 	}
+	// A config narrowed to one mapping also carries that mapping's ignores
+	if m := cfg.Devsync.Mapping; m != nil {
+		remoteConfig.Devsync.Ignores = append(remoteConfig.Devsync.Ignores, m.Ignores...)
+	}
 
 	// Convert to JSON
 	configJSON, err := json.MarshalIndent(remoteConfig, "", "  ")
@@ -292,7 +296,7 @@ func UploadAgentBinary(client *sshclient.SSHClient, localBinaryPath, remoteDir,
 	return nil
 }
 
-func RemoteRunAgentIndexing(client *sshclient.SSHClient, remoteDir, osTarget string, bypassIgnore bool, prefixes []string) (string, error) {
+func RemoteRunAgentIndexing(client *sshclient.SSHClient, remoteDir, osTarget string, bypassIgnore bool, prefixes []string, throttle config.IndexingThrottle) (string, error) {
 	isWin := strings.Contains(strings.ToLower(osTarget), "win")
 
 	// Get unique agent binary name from local config
@@ -301,57 +305,15 @@ func RemoteRunAgentIndexing(client *sshclient.SSHClient, remoteDir, osTarget str
 		return "", fmt.Errorf("failed to load local config: %v", err)
 	}
 
-	// Build the binary path based on OS using unique agent name
-	binaryName := localConfig.GetAgentBinaryName(osTarget)
-	var cmd string
-	remoteBase := remoteDir
+	indexingCmd := "indexing"
+	if bypassIgnore {
+		indexingCmd = "indexing --bypass-ignore"
+	}
+	cmd := buildAgentCommand(remoteDir, osTarget, localConfig.GetAgentBinaryName(osTarget), indexingCmd, prefixes)
 	if isWin {
-		winRemoteDir := strings.ReplaceAll(remoteBase, "/", "\\")
-		var agentPath, cdDir string
-		if strings.HasSuffix(winRemoteDir, ".sync_temp") {
-			agentPath = winRemoteDir + "\\" + binaryName
-			cdDir = winRemoteDir[:len(winRemoteDir)-len(".sync_temp")]
-			cdDir = strings.TrimSuffix(cdDir, "\\")
-			if cdDir == "" {
-				cdDir = winRemoteDir
-			} // fallback
-		} else {
-			agentPath = winRemoteDir + "\\.sync_temp\\" + binaryName
-			cdDir = winRemoteDir
-		}
-		indexingCmd := "indexing"
-		if bypassIgnore {
-			indexingCmd = "indexing --bypass-ignore"
-		}
-		if len(prefixes) > 0 {
-			joined := strings.Join(prefixes, ",")
-			indexingCmd = fmt.Sprintf("%s --manual-transfer %s", indexingCmd, joined)
-		}
-		cmd = fmt.Sprintf("cmd.exe /C cd /d \"%s\" && \"%s\" %s", cdDir, agentPath, indexingCmd)
+		cmd += indexingThrottleArgs(throttle, func(v string) string { return v })
 	} else {
-		var agentPath, cdDir string
-		if strings.HasSuffix(remoteBase, ".sync_temp") {
-			agentPath = filepath.Join(remoteBase, binaryName)
-			cdDir = strings.TrimSuffix(remoteBase, ".sync_temp")
-			if cdDir == "" {
-				cdDir = remoteBase
-			}
-		} else {
-			agentPath = filepath.Join(remoteBase, ".sync_temp", binaryName)
-			cdDir = remoteBase
-		}
-		// Ensure forward slashes for Linux
-		agentPath = filepath.ToSlash(agentPath)
-		cdDir = filepath.ToSlash(cdDir)
-		indexingCmd := "indexing"
-		if bypassIgnore {
-			indexingCmd = "indexing --bypass-ignore"
-		}
-		if len(prefixes) > 0 {
-			joined := strings.Join(prefixes, ",")
-			indexingCmd = fmt.Sprintf("%s --manual-transfer %s", indexingCmd, shellQuote(joined))
-		}
-		cmd = fmt.Sprintf("cd %s && %s %s", shellQuote(cdDir), shellQuote(agentPath), indexingCmd)
+		cmd += indexingThrottleArgs(throttle, shellQuote)
 	}
 
 	fmt.Printf("🔍 DEBUG: Executing remote command: %s\n", cmd)
@@ -405,26 +367,18 @@ func shellQuote(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
 }
 
-// RemoteRunAgentPrune runs the agent's prune command on the remote .sync_temp agent
-// remoteDir should be the remote .sync_temp directory (same as used for agent binary)
-func RemoteRunAgentPrune(client *sshclient.SSHClient, remoteDir, osTarget string, bypassIgnore bool, prefixes []string, dryRun bool) (string, error) {
+// buildAgentCommand builds the remote command that runs `agent <sub>` from the
+// remote root, appending `--manual-transfer <prefixes>` when prefixes are given.
+// remoteDir is the remote .sync_temp directory holding the agent binary (or
+// the remote root). Every agent subcommand run over SSH goes through it.
+func buildAgentCommand(remoteDir, osTarget, binaryName, sub string, prefixes []string) string {
 	isWin := strings.Contains(strings.ToLower(osTarget), "win")
-
-	// Get unique agent binary name from local config
-	localConfig, err := config.GetOrCreateLocalConfig()
-	if err != nil {
-		return "", fmt.Errorf("failed to load local config: %v", err)
-	}
-	binaryName := localConfig.GetAgentBinaryName(osTarget)
-
-	var cmd string
 	if isWin {
 		winRemoteDir := strings.ReplaceAll(remoteDir, "/", "\\")
 		var agentPath, cdDir string
 		if strings.HasSuffix(winRemoteDir, ".sync_temp") {
 			agentPath = winRemoteDir + "\\" + binaryName
-			cdDir = winRemoteDir[:len(winRemoteDir)-len(".sync_temp")]
-			cdDir = strings.TrimSuffix(cdDir, "\\")
+			cdDir = strings.TrimSuffix(winRemoteDir[:len(winRemoteDir)-len(".sync_temp")], "\\")
 			if cdDir == "" {
 				cdDir = winRemoteDir
 			}
@@ -432,45 +386,68 @@ func RemoteRunAgentPrune(client *sshclient.SSHClient, remoteDir, osTarget string
 			agentPath = winRemoteDir + "\\.sync_temp\\" + binaryName
 			cdDir = winRemoteDir
 		}
-		pruneCmd := "prune"
-		if bypassIgnore {
-			pruneCmd = pruneCmd + " --bypass-ignore"
-		}
-		if dryRun {
-			pruneCmd = pruneCmd + " --dry-run"
-		}
+		agentCmd := sub
 		if len(prefixes) > 0 {
-			joined := strings.Join(prefixes, ",")
-			pruneCmd = fmt.Sprintf("%s --manual-transfer %s", pruneCmd, joined)
+			agentCmd = fmt.Sprintf("%s --manual-transfer %s", agentCmd, strings.Join(prefixes, ","))
 		}
-		cmd = fmt.Sprintf("cmd.exe /C cd /d \"%s\" && \"%s\" %s", cdDir, agentPath, pruneCmd)
-	} else {
-		var agentPath, cdDir string
-		if strings.HasSuffix(remoteDir, ".sync_temp") {
-			agentPath = filepath.Join(remoteDir, binaryName)
-			cdDir = strings.TrimSuffix(remoteDir, ".sync_temp")
-			if cdDir == "" {
-				cdDir = remoteDir
-			}
-		} else {
-			agentPath = filepath.Join(remoteDir, ".sync_temp", binaryName)
+		return fmt.Sprintf("cmd.exe /C cd /d \"%s\" && \"%s\" %s", cdDir, agentPath, agentCmd)
+	}
+
+	var agentPath, cdDir string
+	if strings.HasSuffix(remoteDir, ".sync_temp") {
+		agentPath = filepath.Join(remoteDir, binaryName)
+		cdDir = strings.TrimSuffix(remoteDir, ".sync_temp")
+		if cdDir == "" {
 			cdDir = remoteDir
 		}
-		agentPath = filepath.ToSlash(agentPath)
-		cdDir = filepath.ToSlash(cdDir)
-		pruneCmd := "prune"
-		if bypassIgnore {
-			pruneCmd = pruneCmd + " --bypass-ignore"
-		}
-		if dryRun {
-			pruneCmd = pruneCmd + " --dry-run"
-		}
-		if len(prefixes) > 0 {
-			joined := strings.Join(prefixes, ",")
-			pruneCmd = fmt.Sprintf("%s --manual-transfer %s", pruneCmd, shellQuote(joined))
-		}
-		cmd = fmt.Sprintf("cd %s && %s %s", shellQuote(cdDir), shellQuote(agentPath), pruneCmd)
+	} else {
+		agentPath = filepath.Join(remoteDir, ".sync_temp", binaryName)
+		cdDir = remoteDir
 	}
+	agentPath = filepath.ToSlash(agentPath)
+	cdDir = filepath.ToSlash(cdDir)
+	agentCmd := sub
+	if len(prefixes) > 0 {
+		agentCmd = fmt.Sprintf("%s --manual-transfer %s", agentCmd, shellQuote(strings.Join(prefixes, ",")))
+	}
+	return fmt.Sprintf("cd %s && %s %s", shellQuote(cdDir), shellQuote(agentPath), agentCmd)
+}
+
+// indexingThrottleArgs renders the agent indexing throttle flags (with a
+// leading space) for the configured limits; quote escapes string values.
+func indexingThrottleArgs(t config.IndexingThrottle, quote func(string) string) string {
+	var b strings.Builder
+	if t.Nice != 0 {
+		fmt.Fprintf(&b, " --nice %d", t.Nice)
+	}
+	if v := strings.TrimSpace(t.IONice); v != "" {
+		fmt.Fprintf(&b, " --ionice %s", quote(v))
+	}
+	if t.Workers > 0 {
+		fmt.Fprintf(&b, " --workers %d", t.Workers)
+	}
+	if t.ReadBytesPerSec > 0 {
+		fmt.Fprintf(&b, " --read-bps %d", t.ReadBytesPerSec)
+	}
+	return b.String()
+}
+
+// RemoteRunAgentPrune runs the agent's prune command on the remote .sync_temp agent
+// remoteDir should be the remote .sync_temp directory (same as used for agent binary)
+func RemoteRunAgentPrune(client *sshclient.SSHClient, remoteDir, osTarget string, bypassIgnore bool, prefixes []string, dryRun bool) (string, error) {
+	// Get unique agent binary name from local config
+	localConfig, err := config.GetOrCreateLocalConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load local config: %v", err)
+	}
+	pruneCmd := "prune"
+	if bypassIgnore {
+		pruneCmd = pruneCmd + " --bypass-ignore"
+	}
+	if dryRun {
+		pruneCmd = pruneCmd + " --dry-run"
+	}
+	cmd := buildAgentCommand(remoteDir, osTarget, localConfig.GetAgentBinaryName(osTarget), pruneCmd, prefixes)
 
 	util.Default.Printf("🔍 DEBUG: Executing remote prune command: %s\n", cmd)
 
@@ -504,17 +481,10 @@ func RemoteRunAgentPrune(client *sshclient.SSHClient, remoteDir, osTarget string
 // replace this with a stub that returns synthetic output.
 var RemoteRunAgentPruneFn = RemoteRunAgentPrune
 
-// RunAgentIndexingFlow encapsulates the full remote indexing orchestration:
-// - locate local agent binary from provided candidates
-// - connect SSH using cfg
-// - optionally upload agent into remote .sync_temp (controlled by skipUpload)
-// - run remote indexing command
-// - download remote indexing_files.db into a local temp file
-// Returns the local path of the downloaded DB, the remote output, or an error.
-// If skipUpload is true, the function will not upload the agent or config and
-// assumes they are already present on the remote (useful when caller already
-// deployed the agent).
-func RunAgentIndexingFlow(cfg *config.Config, localCandidates []string, bypassIgnore bool, prefixes []string) (string, string, error) {
+// deployAgentForFlow locates the local agent binary from the candidates,
+// connects SSH, stops any running agent and uploads the binary plus
+// config.json into the remote .sync_temp. The caller owns the returned client.
+func deployAgentForFlow(cfg *config.Config, localCandidates []string) (*sshclient.SSHClient, string, string, error) {
 	// find local binary
 	var localBinary string
 	for _, c := range localCandidates {
@@ -524,7 +494,7 @@ func RunAgentIndexingFlow(cfg *config.Config, localCandidates []string, bypassIg
 		}
 	}
 	if localBinary == "" {
-		return "", "", fmt.Errorf("agent binary not found in candidates: %v", localCandidates)
+		return nil, "", "", fmt.Errorf("agent binary not found in candidates: %v", localCandidates)
 	}
 
 	// determine remote base path
@@ -551,9 +521,8 @@ func RunAgentIndexingFlow(cfg *config.Config, localCandidates []string, bypassIg
 	// Connect SSH
 	sshCli, err := ConnectSSH(cfg)
 	if err != nil {
-		return "", "", fmt.Errorf("ssh connect failed: %v", err)
+		return nil, "", "", fmt.Errorf("ssh connect failed: %v", err)
 	}
-	defer sshCli.Close()
 
 	// Kill existing agent process before uploading new binary
 	localCfg, err := config.GetOrCreateLocalConfig()
@@ -567,19 +536,40 @@ func RunAgentIndexingFlow(cfg *config.Config, localCandidates []string, bypassIg
 
 	// Upload agent binary into remoteSyncTemp
 	if err := UploadAgentBinary(sshCli, localBinary, remoteSyncTemp, osTarget); err != nil {
-		return "", "", fmt.Errorf("upload agent binary failed: %v", err)
+		sshCli.Close()
+		return nil, "", "", fmt.Errorf("upload agent binary failed: %v", err)
 	}
 	util.Default.Println("✅ Agent binary uploaded")
 
 	// Upload config.json to remote .sync_temp (needed for agent to know working directory)
 	if err := uploadConfigToRemote(sshCli, cfg, remoteSyncTemp, osTarget); err != nil {
-		return "", "", fmt.Errorf("upload config failed: %v", err)
+		sshCli.Close()
+		return nil, "", "", fmt.Errorf("upload config failed: %v", err)
 	}
 	util.Default.Println("✅ Config uploaded")
+	return sshCli, remoteSyncTemp, osTarget, nil
+}
+
+// RunAgentIndexingFlow encapsulates the full remote indexing orchestration:
+// - locate local agent binary from provided candidates
+// - connect SSH using cfg
+// - optionally upload agent into remote .sync_temp (controlled by skipUpload)
+// - run remote indexing command
+// - download remote indexing_files.db into a local temp file
+// Returns the local path of the downloaded DB, the remote output, or an error.
+// If skipUpload is true, the function will not upload the agent or config and
+// assumes they are already present on the remote (useful when caller already
+// deployed the agent).
+func RunAgentIndexingFlow(cfg *config.Config, localCandidates []string, bypassIgnore bool, prefixes []string) (string, string, error) {
+	sshCli, remoteSyncTemp, osTarget, err := deployAgentForFlow(cfg, localCandidates)
+	if err != nil {
+		return "", "", err
+	}
+	defer sshCli.Close()
 
 	// Run remote indexing
 	util.Default.Println("🔍 Running remote agent indexing...")
-	out, err := RemoteRunAgentIndexing(sshCli, remoteSyncTemp, osTarget, bypassIgnore, prefixes)
+	out, err := RemoteRunAgentIndexing(sshCli, remoteSyncTemp, osTarget, bypassIgnore, prefixes, cfg.Devsync.Indexing)
 	if err != nil {
 		return "", out, fmt.Errorf("remote indexing failed: %v", err)
 	}
@@ -134,6 +134,9 @@ func RunSafePull(cfg *config.Config, sshClient *sshclient.SSHClient) SafePullRes
 // strategy based on mode. mode strings expected to contain substrings:
 // "Force" (enable delete semantics), "Bypass" (bypass local ignore patterns).
 func RunPullWithMode(cfg *config.Config, sshClient *sshclient.SSHClient, mode string) SafePullResult {
+	if cfg.HasMappings() {
+		return runPullPerMapping(cfg, sshClient, mode)
+	}
 	util.Default.Println("🔁 pull selected — checking remote agent status...")
 
 	// Determine target OS from config
@@ -356,6 +359,9 @@ func RunSafePush(cfg *config.Config, sshClient *sshclient.SSHClient) SafePushRes
 // strategy based on mode. mode strings expected to contain substrings:
 // "Force" (enable delete semantics), "Bypass" (bypass local ignore patterns).
 func RunPushWithMode(cfg *config.Config, sshClient *sshclient.SSHClient, mode string) SafePushResult {
+	if cfg.HasMappings() {
+		return runPushPerMapping(cfg, sshClient, mode)
+	}
 	util.Default.Println("🔁 push selected — checking remote agent status...")
 
 	// Determine target OS from config
@@ -0,0 +1,322 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	scrollModeView = iota
+	scrollModeSearch
+	scrollModeSave
+)
+
+var (
+	scrollHeaderStyle = lipgloss.NewStyle().Bold(true).Reverse(true)
+	scrollMatchStyle  = lipgloss.NewStyle().Background(lipgloss.Color("11")).Foreground(lipgloss.Color("0"))
+	scrollCursorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Bold(true)
+	scrollMarkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("13"))
+	scrollHelpStyle   = lipgloss.NewStyle().Faint(true)
+)
+
+// scrollbackModel is a pager over plain-text slot output with regex search
+// and saving of a marked range.
+type scrollbackModel struct {
+	title   string
+	lines   []string
+	saveDir string
+
+	width, height int
+	top, cursor   int
+	mark          int // -1 when no range is marked
+
+	re      *regexp.Regexp
+	matches []int
+
+	mode   int
+	input  textinput.Model
+	status string
+}
+
+// ShowScrollback opens a full-screen viewer over lines. Saved ranges go to
+// saveDir unless the user enters an absolute path.
+func ShowScrollback(title string, lines []string, saveDir string) error {
+	in := textinput.New()
+	in.CharLimit = 512
+	m := &scrollbackModel{title: title, lines: lines, saveDir: saveDir, mark: -1, input: in, height: 24, width: 80}
+	m.cursor = len(lines) - 1
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
+
+func (m *scrollbackModel) Init() tea.Cmd { return nil }
+
+// bodyHeight is the number of output lines on screen (header and footer
+// take one line each).
+func (m *scrollbackModel) bodyHeight() int {
+	if m.height < 3 {
+		return 1
+	}
+	return m.height - 2
+}
+
+func (m *scrollbackModel) moveTo(line int) {
+	if line >= len(m.lines) {
+		line = len(m.lines) - 1
+	}
+	if line < 0 {
+		line = 0
+	}
+	m.cursor = line
+	if m.cursor < m.top {
+		m.top = m.cursor
+	}
+	if m.cursor >= m.top+m.bodyHeight() {
+		m.top = m.cursor - m.bodyHeight() + 1
+	}
+}
+
+// selection returns the marked range, or the whole buffer.
+func (m *scrollbackModel) selection() (int, int) {
+	if m.mark < 0 {
+		return 0, len(m.lines) - 1
+	}
+	if m.mark < m.cursor {
+		return m.mark, m.cursor
+	}
+	return m.cursor, m.mark
+}
+
+func (m *scrollbackModel) search(expr string) {
+	m.re, m.matches = nil, nil
+	if expr == "" {
+		m.status = "search cleared"
+		return
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		m.status = "invalid regex: " + err.Error()
+		return
+	}
+	m.re = re
+	for i, l := range m.lines {
+		if re.MatchString(l) {
+			m.matches = append(m.matches, i)
+		}
+	}
+	if len(m.matches) == 0 {
+		m.status = fmt.Sprintf("no match for /%s/", expr)
+		return
+	}
+	m.jumpMatch(true, true)
+}
+
+// jumpMatch moves to the next (or previous) matching line, wrapping around.
+func (m *scrollbackModel) jumpMatch(forward, inclusive bool) {
+	if len(m.matches) == 0 {
+		m.status = "no search"
+		return
+	}
+	idx := -1
+	for i, l := range m.matches {
+		if forward && (l > m.cursor || (inclusive && l == m.cursor)) {
+			idx = i
+			break
+		}
+		if !forward && l < m.cursor {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		idx = 0
+		if !forward {
+			idx = len(m.matches) - 1
+		}
+	}
+	m.moveTo(m.matches[idx])
+	m.status = fmt.Sprintf("match %d/%d", idx+1, len(m.matches))
+}
+
+func (m *scrollbackModel) save(name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		m.status = "save cancelled"
+		return
+	}
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.saveDir, name)
+	}
+	from, to := m.selection()
+	if len(m.lines) == 0 || from > to {
+		m.status = "nothing to save"
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		m.status = "save failed: " + err.Error()
+		return
+	}
+	text := strings.Join(m.lines[from:to+1], "\n") + "\n"
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		m.status = "save failed: " + err.Error()
+		return
+	}
+	m.mark = -1
+	m.status = fmt.Sprintf("saved lines %d-%d to %s", from+1, to+1, path)
+}
+
+func (m *scrollbackModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.input.Width = msg.Width - 12
+		m.moveTo(m.cursor)
+		return m, nil
+	case tea.KeyMsg:
+		if m.mode != scrollModeView {
+			switch msg.String() {
+			case "enter":
+				if m.mode == scrollModeSearch {
+					m.search(m.input.Value())
+				} else {
+					m.save(m.input.Value())
+				}
+				m.mode = scrollModeView
+				m.input.Blur()
+				return m, nil
+			case "esc", "ctrl+c":
+				m.mode = scrollModeView
+				m.input.Blur()
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+
+		m.status = ""
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			if m.mark >= 0 {
+				m.mark = -1
+				return m, nil
+			}
+			return m, tea.Quit
+		case "up", "k":
+			m.moveTo(m.cursor - 1)
+		case "down", "j":
+			m.moveTo(m.cursor + 1)
+		case "pgup", "b":
+			m.moveTo(m.cursor - m.bodyHeight())
+		case "pgdown", "f", " ":
+			m.moveTo(m.cursor + m.bodyHeight())
+		case "home", "g":
+			m.moveTo(0)
+		case "end", "G":
+			m.moveTo(len(m.lines) - 1)
+		case "/":
+			m.mode = scrollModeSearch
+			m.input.Prompt = "/"
+			m.input.SetValue("")
+			return m, m.input.Focus()
+		case "n":
+			m.jumpMatch(true, false)
+		case "N":
+			m.jumpMatch(false, false)
+		case "v":
+			if m.mark >= 0 {
+				m.mark = -1
+			} else {
+				m.mark = m.cursor
+			}
+		case "s":
+			m.mode = scrollModeSave
+			m.input.Prompt = "save to: "
+			m.input.SetValue(strings.ReplaceAll(strings.ToLower(m.title), " ", "-") + ".txt")
+			m.input.CursorEnd()
+			return m, m.input.Focus()
+		}
+	}
+	return m, nil
+}
+
+// highlight marks every match of the search regex in line.
+func (m *scrollbackModel) highlight(line string) string {
+	if m.re == nil {
+		return line
+	}
+	locs := m.re.FindAllStringIndex(line, -1)
+	if len(locs) == 0 {
+		return line
+	}
+	var sb strings.Builder
+	last := 0
+	for _, loc := range locs {
+		if loc[0] == loc[1] {
+			continue
+		}
+		sb.WriteString(line[last:loc[0]])
+		sb.WriteString(scrollMatchStyle.Render(line[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	sb.WriteString(line[last:])
+	return sb.String()
+}
+
+func (m *scrollbackModel) View() string {
+	var sb strings.Builder
+	header := fmt.Sprintf(" %s — line %d/%d ", m.title, m.cursor+1, len(m.lines))
+	if m.mark >= 0 {
+		from, to := m.selection()
+		header += fmt.Sprintf("— marked %d-%d ", from+1, to+1)
+	}
+	sb.WriteString(scrollHeaderStyle.Render(header))
+	sb.WriteString("\n")
+
+	from, to := m.selection()
+	width := m.width - 2
+	if width < 1 {
+		width = 1
+	}
+	for i := m.top; i < m.top+m.bodyHeight(); i++ {
+		if i >= len(m.lines) {
+			sb.WriteString("~\n")
+			continue
+		}
+		gutter := "  "
+		switch {
+		case i == m.cursor:
+			gutter = scrollCursorStyle.Render("▸ ")
+		case m.mark >= 0 && i >= from && i <= to:
+			gutter = scrollMarkStyle.Render("┃ ")
+		}
+		line := strings.ReplaceAll(m.lines[i], "\t", "    ")
+		if r := []rune(line); len(r) > width {
+			line = string(r[:width])
+		}
+		sb.WriteString(gutter + m.highlight(line) + "\n")
+	}
+
+	switch {
+	case m.mode != scrollModeView:
+		sb.WriteString(m.input.View())
+	case m.status != "":
+		sb.WriteString(m.status)
+	default:
+		sb.WriteString(scrollHelpStyle.Render("↑↓ PgUp/PgDn g/G move · / search · n/N next/prev · v mark range · s save · q quit"))
+	}
+	return sb.String()
+}
@@ -50,3 +50,72 @@ func RemoteToLocal(remoteBase, localBase, remotePath string) (string, error) {
 	localPath := filepath.Join(localBase, localRel)
 	return localPath, nil
 }
+
+// PathMapping pairs an absolute local root with the remote root it syncs to.
+type PathMapping struct {
+	Local  string
+	Remote string
+}
+
+// PathMap resolves paths through several local/remote root pairs. When roots
+// are nested the deepest one containing the path wins.
+type PathMap []PathMapping
+
+// MatchLocal returns the index of the mapping whose local root contains
+// absLocalPath and the slash-separated path relative to that root.
+func (m PathMap) MatchLocal(absLocalPath string) (int, string, bool) {
+	best, bestRel, bestLen := -1, "", -1
+	for i, pm := range m {
+		rel, err := filepath.Rel(pm.Local, absLocalPath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(pm.Local) > bestLen {
+			best, bestRel, bestLen = i, filepath.ToSlash(rel), len(pm.Local)
+		}
+	}
+	return best, bestRel, best >= 0
+}
+
+// MatchRemote returns the index of the mapping whose remote root contains
+// remotePath and the slash-separated path relative to that root.
+func (m PathMap) MatchRemote(remotePath string) (int, string, bool) {
+	p := path.Clean(strings.ReplaceAll(remotePath, "\\", "/"))
+	best, bestRel, bestLen := -1, "", -1
+	for i, pm := range m {
+		base := path.Clean(strings.ReplaceAll(pm.Remote, "\\", "/"))
+		var rel string
+		switch {
+		case p == base:
+			rel = "."
+		case base == "/" && strings.HasPrefix(p, "/"):
+			rel = strings.TrimPrefix(p, "/")
+		case strings.HasPrefix(p, base+"/"):
+			rel = strings.TrimPrefix(p, base+"/")
+		default:
+			continue
+		}
+		if len(base) > bestLen {
+			best, bestRel, bestLen = i, rel, len(base)
+		}
+	}
+	return best, bestRel, best >= 0
+}
+
+// LocalToRemote is LocalToRemote for the mapping containing absLocalPath.
+func (m PathMap) LocalToRemote(absLocalPath string) (string, error) {
+	i, _, ok := m.MatchLocal(absLocalPath)
+	if !ok {
+		return "", fmt.Errorf("local path %s is not under any mapped local root", absLocalPath)
+	}
+	return LocalToRemote(m[i].Local, m[i].Remote, absLocalPath)
+}
+
+// RemoteToLocal is RemoteToLocal for the mapping containing remotePath.
+func (m PathMap) RemoteToLocal(remotePath string) (string, error) {
+	i, _, ok := m.MatchRemote(remotePath)
+	if !ok {
+		return "", fmt.Errorf("remote path %s is not under any mapped remote root", remotePath)
+	}
+	return RemoteToLocal(m[i].Remote, m[i].Local, remotePath)
+}
@@ -2,6 +2,8 @@ package util
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"sync"
 )
@@ -12,6 +14,10 @@ import (
 type printerShim struct {
 	mu        sync.Mutex
 	suspended bool
+	// out overrides os.Stdout when set; plain strips terminal control
+	// sequences (used when logging to a file in headless mode)
+	out   io.Writer
+	plain bool
 }
 
 var Default = &printerShim{}
@@ -32,7 +38,7 @@ func (p *printerShim) Print(a ...interface{}) {
 		return
 	}
 	msg := fmt.Sprint(a...)
-	fmt.Print(msg)
+	p.emit(msg)
 }
 
 func (p *printerShim) Printf(format string, a ...interface{}) {
@@ -42,7 +48,16 @@ func (p *printerShim) Printf(format string, a ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf(format, a...)
-	fmt.Print(msg)
+	p.emit(msg)
+}
+
+// MenuPrintf prints even while the printer is suspended. Interactive menus
+// suspend it to keep background output away from the prompt; their own
+// messages go through here.
+func (p *printerShim) MenuPrintf(format string, a ...interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.emit(fmt.Sprintf(format, a...))
 }
 
 func (p *printerShim) Println(a ...interface{}) {
@@ -52,7 +67,7 @@ func (p *printerShim) Println(a ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintln(a...)
-	fmt.Print(msg)
+	p.emit(msg)
 }
 
 func (p *printerShim) ClearScreen() {
@@ -62,7 +77,7 @@ func (p *printerShim) ClearScreen() {
 		return
 	}
 	msg := "\x1b[2J\x1b[H"
-	fmt.Print(msg)
+	p.emit(msg)
 }
 
 func (p *printerShim) PrintBlock(block string, clearLine bool) {
@@ -77,7 +92,7 @@ func (p *printerShim) PrintBlock(block string, clearLine bool) {
 	if !strings.HasSuffix(block, "\n") {
 		block += "\n"
 	}
-	fmt.Print(block)
+	p.emit(block)
 }
 
 func (p *printerShim) ClearLine() {
@@ -87,7 +102,7 @@ func (p *printerShim) ClearLine() {
 		return
 	}
 	msg := "\r\x1b[K"
-	fmt.Print(msg)
+	p.emit(msg)
 }
 
 func (p *printerShim) Suspend() {
@@ -107,3 +122,27 @@ func (p *printerShim) IsSuspended() bool {
 	defer p.mu.Unlock()
 	return p.suspended
 }
+
+// SetOutput redirects printer output to w (nil restores os.Stdout). When
+// plain is true, ANSI sequences and carriage returns are stripped.
+func (p *printerShim) SetOutput(w io.Writer, plain bool) {
+	p.mu.Lock()
+	p.out = w
+	p.plain = plain
+	p.mu.Unlock()
+}
+
+// emit writes msg to the configured output. Caller holds p.mu.
+func (p *printerShim) emit(msg string) {
+	if p.plain {
+		msg = strings.ReplaceAll(string(StripANSIEscapes([]byte(msg))), "\r", "")
+		if msg == "" {
+			return
+		}
+	}
+	var w io.Writer = os.Stdout
+	if p.out != nil {
+		w = p.out
+	}
+	fmt.Fprint(w, msg)
+}
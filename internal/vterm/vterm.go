@@ -0,0 +1,698 @@
+// Package vterm is a small VT100/xterm screen emulator. It keeps a cell grid
+// (main and alternate screen) for the output of one PTY so it can be drawn
+// into a region of the real terminal, e.g. a split pane. It understands the
+// cursor, erase, scroll-region, SGR, hyperlink and mode sequences shells and
+// full-screen programs commonly emit; everything else is ignored.
+package vterm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+	"github.com/mattn/go-runewidth"
+)
+
+// decGraphics maps the DEC special graphics charset (ESC ( 0) to Unicode
+// line drawing characters.
+var decGraphics = map[rune]rune{
+	'`': '◆', 'a': '▒', 'f': '°', 'g': '±', 'j': '┘', 'k': '┐', 'l': '┌',
+	'm': '└', 'n': '┼', 'o': '⎺', 'p': '⎻', 'q': '─', 'r': '⎼', 's': '⎽',
+	't': '├', 'u': '┤', 'v': '┴', 'w': '┬', 'x': '│', 'y': '≤', 'z': '≥',
+	'{': 'π', '|': '≠', '}': '£', '~': '·',
+}
+
+type savedCursor struct {
+	x, y     int
+	pen      cellbuf.Style
+	wrapNext bool
+	origin   bool
+	g0, g1   bool
+}
+
+// Terminal is an in-memory terminal screen. It is safe for concurrent use.
+type Terminal struct {
+	mu sync.Mutex
+
+	width, height int
+	main, alt     *cellbuf.Buffer
+	buf           *cellbuf.Buffer // main or alt
+	tabs          *cellbuf.TabStops
+	parser        *ansi.Parser
+
+	x, y         int
+	wrapNext     bool // cursor sits past the last column, wrap on next print
+	lastX, lastY int  // cell of the last printed rune, for combining marks
+	lastRune     rune
+	pen          cellbuf.Style
+	link         cellbuf.Link
+	saved        savedCursor
+	top, bottom  int // scroll region, inclusive
+
+	autowrap  bool
+	origin    bool
+	insert    bool
+	hidden    bool
+	appCursor bool
+	paste     bool
+	g0, g1    bool // charset is DEC special graphics
+	shifted   bool // SO: G1 in use
+	title     string
+	replies   [][]byte
+	replyFunc func([]byte)
+}
+
+// New returns a terminal of width x height cells.
+func New(width, height int) *Terminal {
+	width, height = max(width, 1), max(height, 1)
+	t := &Terminal{
+		width:    width,
+		height:   height,
+		main:     cellbuf.NewBuffer(width, height),
+		alt:      cellbuf.NewBuffer(width, height),
+		tabs:     cellbuf.DefaultTabStops(width),
+		parser:   ansi.NewParser(),
+		bottom:   height - 1,
+		autowrap: true,
+	}
+	t.buf = t.main
+	t.parser.SetHandler(ansi.Handler{
+		Print:     t.print,
+		Execute:   t.execute,
+		HandleCsi: t.csi,
+		HandleEsc: t.esc,
+		HandleOsc: t.osc,
+	})
+	return t
+}
+
+// SetReplyFunc sets where answers to terminal queries (cursor position,
+// device attributes) are written, usually the PTY's stdin.
+func (t *Terminal) SetReplyFunc(f func([]byte)) {
+	t.mu.Lock()
+	t.replyFunc = f
+	t.mu.Unlock()
+}
+
+// Write feeds PTY output to the terminal.
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	for _, b := range p {
+		t.parser.Advance(b)
+	}
+	replies, reply := t.replies, t.replyFunc
+	t.replies = nil
+	t.mu.Unlock()
+	if reply != nil {
+		for _, r := range replies {
+			reply(r)
+		}
+	}
+	return len(p), nil
+}
+
+// Size returns the terminal size in cells.
+func (t *Terminal) Size() (width, height int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.width, t.height
+}
+
+// Cursor returns the cursor position and whether it is visible.
+func (t *Terminal) Cursor() (x, y int, visible bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return min(t.x, t.width-1), t.y, !t.hidden
+}
+
+// Title returns the window title set with OSC 0/2.
+func (t *Terminal) Title() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.title
+}
+
+// AppCursorKeys reports whether the program enabled application cursor keys
+// (DECCKM); arrow keys must then be sent as ESC O A.
+func (t *Terminal) AppCursorKeys() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.appCursor
+}
+
+// BracketedPaste reports whether the program enabled bracketed paste.
+func (t *Terminal) BracketedPaste() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.paste
+}
+
+// Resize changes the terminal size. Content is kept top-left aligned; when
+// the height shrinks below the cursor, the top lines scroll away so the
+// cursor line stays visible.
+func (t *Terminal) Resize(width, height int) {
+	width, height = max(width, 1), max(height, 1)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if width == t.width && height == t.height {
+		return
+	}
+	if t.y >= height {
+		n := t.y - height + 1
+		t.scrollLines(t.buf, 0, t.height-1, n)
+		t.y -= n
+	}
+	t.main.Resize(width, height)
+	t.alt.Resize(width, height)
+	t.tabs = cellbuf.DefaultTabStops(width)
+	t.width, t.height = width, height
+	t.top, t.bottom = 0, height-1
+	t.wrapNext = false
+	t.x, t.y = min(t.x, width-1), min(t.y, height-1)
+	t.saved.x, t.saved.y = min(t.saved.x, width-1), min(t.saved.y, height-1)
+}
+
+// Draw copies the visible screen into dst at area, clipped to area.
+func (t *Terminal) Draw(dst cellbuf.CellBuffer, area cellbuf.Rectangle) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w, h := min(t.width, area.Dx()), min(t.height, area.Dy())
+	for y := 0; y < h; y++ {
+		line := t.buf.Line(y)
+		for x := 0; x < w; x++ {
+			c := line[x]
+			if c != nil && c.Width == 0 && c.Rune == 0 {
+				continue // placeholder of a wide cell
+			}
+			if c != nil && x+c.Width > w {
+				blank := c.Clone().Blank()
+				dst.SetCell(area.Min.X+x, area.Min.Y+y, blank)
+				continue
+			}
+			dst.SetCell(area.Min.X+x, area.Min.Y+y, c)
+		}
+	}
+}
+
+// String returns the visible text, one line per row, trailing blanks
+// trimmed.
+func (t *Terminal) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines := make([]string, t.height)
+	for y := range lines {
+		lines[y] = t.buf.Line(y).String()
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func (t *Terminal) print(r rune) {
+	if (t.shifted && t.g1) || (!t.shifted && t.g0) {
+		if m, ok := decGraphics[r]; ok {
+			r = m
+		}
+	}
+	w := runewidth.RuneWidth(r)
+	if w == 0 {
+		// combining mark: attach to the previous cell
+		if c := t.buf.Cell(t.lastX, t.lastY); c != nil && c.Rune != 0 {
+			c = c.Clone()
+			c.Comb = append(c.Comb, r)
+			t.buf.SetCell(t.lastX, t.lastY, c)
+		}
+		return
+	}
+	if t.wrapNext && t.autowrap {
+		t.x = 0
+		t.lineFeed()
+	}
+	t.wrapNext = false
+	if t.x+w > t.width {
+		if t.autowrap && w <= t.width {
+			t.x = 0
+			t.lineFeed()
+		} else {
+			t.x = max(t.width-w, 0)
+		}
+	}
+	line := t.buf.Line(t.y)
+	if t.insert {
+		copy(line[t.x+w:], line[t.x:t.width-w])
+		for i := t.x; i < t.x+w; i++ {
+			line[i] = nil
+		}
+	}
+	t.buf.SetCell(t.x, t.y, &cellbuf.Cell{Rune: r, Width: w, Style: t.pen, Link: t.link})
+	t.lastX, t.lastY, t.lastRune = t.x, t.y, r
+	if t.x+w >= t.width {
+		t.x = t.width - 1
+		t.wrapNext = true
+	} else {
+		t.x += w
+	}
+}
+
+func (t *Terminal) execute(b byte) {
+	switch b {
+	case '\b':
+		t.wrapNext = false
+		if t.x > 0 {
+			t.x--
+		}
+	case '\t':
+		t.wrapNext = false
+		t.x = min(t.tabs.Next(t.x), t.width-1)
+	case '\n', '\v', '\f':
+		t.wrapNext = false
+		t.lineFeed()
+	case '\r':
+		t.wrapNext = false
+		t.x = 0
+	case 0x0e: // SO
+		t.shifted = true
+	case 0x0f: // SI
+		t.shifted = false
+	}
+}
+
+func (t *Terminal) esc(cmd ansi.Cmd) {
+	switch cmd.Intermediate() {
+	case '(':
+		t.g0 = cmd.Final() == '0'
+		return
+	case ')':
+		t.g1 = cmd.Final() == '0'
+		return
+	case 0:
+	default:
+		return
+	}
+	switch cmd.Final() {
+	case '7':
+		t.saveCursor()
+	case '8':
+		t.restoreCursor()
+	case 'D':
+		t.wrapNext = false
+		t.lineFeed()
+	case 'E':
+		t.wrapNext = false
+		t.x = 0
+		t.lineFeed()
+	case 'H':
+		t.tabs.Set(t.x)
+	case 'M':
+		t.wrapNext = false
+		t.reverseIndex()
+	case 'c':
+		t.reset()
+	}
+}
+
+func (t *Terminal) osc(cmd int, data []byte) {
+	switch cmd {
+	case 0, 2:
+		if i := strings.IndexByte(string(data), ';'); i >= 0 {
+			t.title = string(data[i+1:])
+		}
+	case 8:
+		t.link = cellbuf.Link{}
+		cellbuf.ReadLink(data, &t.link)
+	}
+}
+
+func (t *Terminal) csi(cmd ansi.Cmd, params ansi.Params) {
+	arg := func(i, def int) int {
+		v, _, _ := params.Param(i, def)
+		return v
+	}
+	// count is a repeat/move parameter: missing or 0 means 1
+	count := func() int {
+		return max(arg(0, 1), 1)
+	}
+
+	if cmd.Intermediate() != 0 {
+		if cmd.Intermediate() == '!' && cmd.Final() == 'p' { // DECSTR
+			t.softReset()
+		}
+		return
+	}
+	switch cmd.Prefix() {
+	case '?':
+		switch cmd.Final() {
+		case 'h', 'l':
+			for i := range params {
+				t.setPrivateMode(arg(i, 0), cmd.Final() == 'h')
+			}
+		}
+		return
+	case '>':
+		if cmd.Final() == 'c' {
+			t.replies = append(t.replies, []byte("\x1b[>0;10;1c"))
+		}
+		return
+	case 0:
+	default:
+		return
+	}
+
+	switch cmd.Final() {
+	case '@': // ICH
+		t.insertCells(count())
+	case 'A': // CUU
+		t.moveTo(t.x, max(t.y-count(), t.minY()))
+	case 'B': // CUD
+		t.moveTo(t.x, min(t.y+count(), t.maxY()))
+	case 'C', 'a': // CUF, HPR
+		t.moveTo(t.x+count(), t.y)
+	case 'D': // CUB
+		t.moveTo(t.x-count(), t.y)
+	case 'E': // CNL
+		t.moveTo(0, min(t.y+count(), t.maxY()))
+	case 'F': // CPL
+		t.moveTo(0, max(t.y-count(), t.minY()))
+	case 'G', '`': // CHA, HPA
+		t.moveTo(count()-1, t.y)
+	case 'H', 'f': // CUP
+		row, col := max(arg(0, 1), 1)-1, max(arg(1, 1), 1)-1
+		if t.origin {
+			row = min(row+t.top, t.bottom)
+		}
+		t.moveTo(col, row)
+	case 'I': // CHT
+		for n := count(); n > 0; n-- {
+			t.x = min(t.tabs.Next(t.x), t.width-1)
+		}
+		t.wrapNext = false
+	case 'Z': // CBT
+		for n := count(); n > 0; n-- {
+			t.x = max(t.tabs.Prev(t.x), 0)
+		}
+		t.wrapNext = false
+	case 'J': // ED
+		t.eraseDisplay(arg(0, 0))
+	case 'K': // EL
+		t.eraseLine(arg(0, 0))
+	case 'L': // IL
+		if t.y >= t.top && t.y <= t.bottom {
+			t.scrollLines(t.buf, t.y, t.bottom, -count())
+			t.x, t.wrapNext = 0, false
+		}
+	case 'M': // DL
+		if t.y >= t.top && t.y <= t.bottom {
+			t.scrollLines(t.buf, t.y, t.bottom, count())
+			t.x, t.wrapNext = 0, false
+		}
+	case 'P': // DCH
+		t.deleteCells(count())
+	case 'S': // SU
+		t.scrollLines(t.buf, t.top, t.bottom, count())
+	case 'T': // SD
+		if len(params) <= 1 {
+			t.scrollLines(t.buf, t.top, t.bottom, -count())
+		}
+	case 'X': // ECH
+		t.wrapNext = false
+		t.fill(t.y, t.x, min(t.x+count(), t.width))
+	case 'b': // REP
+		if t.lastRune != 0 {
+			for n := count(); n > 0; n-- {
+				t.print(t.lastRune)
+			}
+		}
+	case 'c': // DA
+		t.replies = append(t.replies, []byte("\x1b[?62;22c"))
+	case 'd': // VPA
+		row := count() - 1
+		if t.origin {
+			row = min(row+t.top, t.bottom)
+		}
+		t.moveTo(t.x, row)
+	case 'e': // VPR
+		t.moveTo(t.x, min(t.y+count(), t.maxY()))
+	case 'g': // TBC
+		switch arg(0, 0) {
+		case 0:
+			t.tabs.Reset(t.x)
+		case 3:
+			t.tabs.Clear()
+		}
+	case 'h', 'l': // SM, RM
+		for i := range params {
+			if arg(i, 0) == 4 {
+				t.insert = cmd.Final() == 'h'
+			}
+		}
+	case 'm': // SGR
+		cellbuf.ReadStyle(params, &t.pen)
+	case 'n': // DSR
+		switch arg(0, 0) {
+		case 5:
+			t.replies = append(t.replies, []byte("\x1b[0n"))
+		case 6:
+			row := t.y
+			if t.origin {
+				row -= t.top
+			}
+			t.replies = append(t.replies, []byte(fmt.Sprintf("\x1b[%d;%dR", row+1, min(t.x, t.width-1)+1)))
+		}
+	case 'r': // DECSTBM
+		top, bottom := max(arg(0, 1), 1)-1, max(arg(1, t.height), 1)-1
+		bottom = min(bottom, t.height-1)
+		if top < bottom {
+			t.top, t.bottom = top, bottom
+			if t.origin {
+				t.moveTo(0, t.top)
+			} else {
+				t.moveTo(0, 0)
+			}
+		}
+	case 's':
+		t.saveCursor()
+	case 'u':
+		t.restoreCursor()
+	}
+}
+
+func (t *Terminal) setPrivateMode(mode int, on bool) {
+	switch mode {
+	case 1:
+		t.appCursor = on
+	case 6:
+		t.origin = on
+		if on {
+			t.moveTo(0, t.top)
+		} else {
+			t.moveTo(0, 0)
+		}
+	case 7:
+		t.autowrap = on
+	case 25:
+		t.hidden = !on
+	case 47, 1047:
+		t.useAlt(on, mode == 1047 && on)
+	case 1048:
+		if on {
+			t.saveCursor()
+		} else {
+			t.restoreCursor()
+		}
+	case 1049:
+		if on {
+			t.saveCursor()
+			t.useAlt(true, true)
+		} else {
+			t.useAlt(false, false)
+			t.restoreCursor()
+		}
+	case 2004:
+		t.paste = on
+	}
+}
+
+func (t *Terminal) useAlt(on, clear bool) {
+	if on {
+		t.buf = t.alt
+		if clear {
+			t.alt.Clear()
+		}
+	} else {
+		t.buf = t.main
+	}
+	t.wrapNext = false
+}
+
+func (t *Terminal) minY() int {
+	if t.y >= t.top {
+		return t.top
+	}
+	return 0
+}
+
+func (t *Terminal) maxY() int {
+	if t.y <= t.bottom {
+		return t.bottom
+	}
+	return t.height - 1
+}
+
+func (t *Terminal) moveTo(x, y int) {
+	t.x = min(max(x, 0), t.width-1)
+	t.y = min(max(y, 0), t.height-1)
+	t.wrapNext = false
+}
+
+func (t *Terminal) lineFeed() {
+	switch {
+	case t.y == t.bottom:
+		t.scrollLines(t.buf, t.top, t.bottom, 1)
+	case t.y < t.height-1:
+		t.y++
+	}
+}
+
+func (t *Terminal) reverseIndex() {
+	switch {
+	case t.y == t.top:
+		t.scrollLines(t.buf, t.top, t.bottom, -1)
+	case t.y > 0:
+		t.y--
+	}
+}
+
+// blank is the cell erased cells get: a space with the current background
+// (nil when there is none).
+func (t *Terminal) blank() *cellbuf.Cell {
+	if t.pen.Bg == nil {
+		return nil
+	}
+	return &cellbuf.Cell{Rune: ' ', Width: 1, Style: cellbuf.Style{Bg: t.pen.Bg}}
+}
+
+func (t *Terminal) blankLine() cellbuf.Line {
+	line := make(cellbuf.Line, t.width)
+	if b := t.blank(); b != nil {
+		for i := range line {
+			line[i] = b.Clone()
+		}
+	}
+	return line
+}
+
+// scrollLines scrolls rows top..bottom of buf up by n (down when n < 0),
+// filling the uncovered rows with blank lines.
+func (t *Terminal) scrollLines(buf *cellbuf.Buffer, top, bottom, n int) {
+	size := bottom - top + 1
+	if n == 0 || size <= 0 {
+		return
+	}
+	lines := buf.Lines
+	if n > 0 {
+		n = min(n, size)
+		copy(lines[top:bottom+1], lines[top+n:bottom+1])
+		for i := bottom - n + 1; i <= bottom; i++ {
+			lines[i] = t.blankLine()
+		}
+		return
+	}
+	n = min(-n, size)
+	copy(lines[top+n:bottom+1], lines[top:bottom+1-n])
+	for i := top; i < top+n; i++ {
+		lines[i] = t.blankLine()
+	}
+}
+
+// fill erases columns x0..x1-1 of row y.
+func (t *Terminal) fill(y, x0, x1 int) {
+	line := t.buf.Line(y)
+	b := t.blank()
+	for x := max(x0, 0); x < min(x1, len(line)); x++ {
+		if b != nil {
+			line[x] = b.Clone()
+		} else {
+			line[x] = nil
+		}
+	}
+}
+
+func (t *Terminal) eraseLine(mode int) {
+	t.wrapNext = false
+	switch mode {
+	case 0:
+		t.fill(t.y, t.x, t.width)
+	case 1:
+		t.fill(t.y, 0, t.x+1)
+	case 2:
+		t.fill(t.y, 0, t.width)
+	}
+}
+
+func (t *Terminal) eraseDisplay(mode int) {
+	t.wrapNext = false
+	switch mode {
+	case 0:
+		t.fill(t.y, t.x, t.width)
+		for y := t.y + 1; y < t.height; y++ {
+			t.fill(y, 0, t.width)
+		}
+	case 1:
+		for y := 0; y < t.y; y++ {
+			t.fill(y, 0, t.width)
+		}
+		t.fill(t.y, 0, t.x+1)
+	case 2, 3:
+		for y := 0; y < t.height; y++ {
+			t.fill(y, 0, t.width)
+		}
+	}
+}
+
+func (t *Terminal) insertCells(n int) {
+	t.wrapNext = false
+	line := t.buf.Line(t.y)
+	n = min(n, t.width-t.x)
+	copy(line[t.x+n:], line[t.x:t.width-n])
+	t.fill(t.y, t.x, t.x+n)
+}
+
+func (t *Terminal) deleteCells(n int) {
+	t.wrapNext = false
+	line := t.buf.Line(t.y)
+	n = min(n, t.width-t.x)
+	copy(line[t.x:], line[t.x+n:])
+	t.fill(t.y, t.width-n, t.width)
+}
+
+func (t *Terminal) saveCursor() {
+	t.saved = savedCursor{x: t.x, y: t.y, pen: t.pen, wrapNext: t.wrapNext, origin: t.origin, g0: t.g0, g1: t.g1}
+}
+
+func (t *Terminal) restoreCursor() {
+	s := t.saved
+	t.x, t.y = min(s.x, t.width-1), min(s.y, t.height-1)
+	t.pen, t.wrapNext, t.origin, t.g0, t.g1 = s.pen, s.wrapNext, s.origin, s.g0, s.g1
+}
+
+// softReset is DECSTR: modes and pen back to defaults, screen kept.
+func (t *Terminal) softReset() {
+	t.pen = cellbuf.Style{}
+	t.link = cellbuf.Link{}
+	t.top, t.bottom = 0, t.height-1
+	t.autowrap, t.origin, t.insert, t.hidden, t.appCursor = true, false, false, false, false
+	t.g0, t.g1, t.shifted = false, false, false
+	t.saved = savedCursor{}
+}
+
+// reset is RIS: everything back to the initial state.
+func (t *Terminal) reset() {
+	t.softReset()
+	t.main.Clear()
+	t.alt.Clear()
+	t.buf = t.main
+	t.tabs = cellbuf.DefaultTabStops(t.width)
+	t.x, t.y, t.wrapNext = 0, 0, false
+	t.paste = false
+	t.title = ""
+}
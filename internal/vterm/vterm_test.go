@@ -0,0 +1,63 @@
+package vterm
+
+import (
+	"testing"
+)
+
+func TestTerminalWrapsAndScrolls(t *testing.T) {
+	term := New(5, 3)
+	term.Write([]byte("abcdefg\r\nline2\r\nline3\r\nx"))
+	want := "line2\nline3\nx"
+	if got := term.String(); got != want {
+		t.Fatalf("screen = %q, want %q", got, want)
+	}
+	if x, y, _ := term.Cursor(); x != 1 || y != 2 {
+		t.Fatalf("cursor = %d,%d, want 1,2", x, y)
+	}
+}
+
+func TestTerminalCursorAndErase(t *testing.T) {
+	term := New(10, 3)
+	term.Write([]byte("hello\r\nworld\x1b[1;3H\x1b[K\x1b[2;1H\x1b[2P"))
+	want := "he\nrld"
+	if got := term.String(); got != want {
+		t.Fatalf("screen = %q, want %q", got, want)
+	}
+}
+
+func TestTerminalScrollRegionAndAltScreen(t *testing.T) {
+	term := New(6, 4)
+	term.Write([]byte("top\r\n1\r\n2\r\nbottom"))
+	term.Write([]byte("\x1b[2;3r\x1b[3;1H\n"))
+	want := "top\n2\n\nbottom"
+	if got := term.String(); got != want {
+		t.Fatalf("screen = %q, want %q", got, want)
+	}
+
+	term.Write([]byte("\x1b[?1049h\x1b[Hvim"))
+	if got := term.String(); got != "vim" {
+		t.Fatalf("alt screen = %q", got)
+	}
+	term.Write([]byte("\x1b[?1049l"))
+	if got := term.String(); got != want {
+		t.Fatalf("main screen not restored: %q", got)
+	}
+}
+
+func TestTerminalRepliesAndResize(t *testing.T) {
+	term := New(10, 5)
+	var replies []string
+	term.SetReplyFunc(func(b []byte) { replies = append(replies, string(b)) })
+	term.Write([]byte("a\r\nb\r\nc\r\nd\x1b[6n"))
+	if len(replies) != 1 || replies[0] != "\x1b[4;2R" {
+		t.Fatalf("replies = %q", replies)
+	}
+
+	term.Resize(4, 2)
+	if got := term.String(); got != "c\nd" {
+		t.Fatalf("after resize = %q", got)
+	}
+	if x, y, _ := term.Cursor(); x != 1 || y != 1 {
+		t.Fatalf("cursor after resize = %d,%d", x, y)
+	}
+}
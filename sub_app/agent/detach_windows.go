@@ -0,0 +1,34 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Windows process creation flags (not always present in syscall)
+const (
+	DETACHED_PROCESS         = 0x00000008
+	CREATE_NEW_PROCESS_GROUP = 0x00000200
+)
+
+// spawnDetached starts exe as a detached process without a console so it
+// survives the SSH session that launched it. Output is redirected to logFile.
+func spawnDetached(exe string, args []string, logFile *os.File) (int, error) {
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: DETACHED_PROCESS | CREATE_NEW_PROCESS_GROUP,
+		HideWindow:    true,
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+	return pid, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// duDefaultTop is the number of largest files reported per prefix.
+const duDefaultTop = 10
+
+// DuFile is a single file entry in a du report.
+type DuFile struct {
+	Rel  string `json:"rel"`
+	Size int64  `json:"size"`
+}
+
+// DuDir aggregates the files below one first-level directory of a prefix.
+// Files directly inside the prefix are reported under ".".
+type DuDir struct {
+	Path  string `json:"path"`
+	Files int64  `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// DuPrefix is the usage summary of one manual-transfer prefix.
+type DuPrefix struct {
+	Prefix  string   `json:"prefix"`
+	Files   int64    `json:"files"`
+	Dirs    int64    `json:"dirs"`
+	Bytes   int64    `json:"bytes"`
+	Largest []DuFile `json:"largest"`
+	Subdirs []DuDir  `json:"subdirs"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// DuReport is printed as a single JSON line by `agent du`.
+type DuReport struct {
+	Prefixes []DuPrefix `json:"prefixes"`
+}
+
+// performDu walks each prefix (or the whole working dir when none are given)
+// under the same ignore rules as pack and summarizes its disk usage.
+func performDu(prefixes []string, bypassIgnore bool, top int) (DuReport, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return DuReport{}, err
+	}
+	if top <= 0 {
+		top = duDefaultTop
+	}
+	scope := newPackScope(root, prefixes, bypassIgnore)
+	starts := scope.prefixes
+	if len(starts) == 0 {
+		starts = []string{""}
+	}
+
+	report := DuReport{Prefixes: []DuPrefix{}}
+	for _, p := range starts {
+		report.Prefixes = append(report.Prefixes, duPrefix(scope, p, top))
+	}
+	return report, nil
+}
+
+// duPrefix summarizes a single prefix relative to scope.root.
+func duPrefix(scope *packScope, prefix string, top int) DuPrefix {
+	res := DuPrefix{Prefix: prefix, Largest: []DuFile{}, Subdirs: []DuDir{}}
+	start := filepath.Join(scope.root, filepath.FromSlash(prefix))
+	if _, err := os.Stat(start); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	subdirs := map[string]*DuDir{}
+	_ = filepath.WalkDir(start, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, rerr := filepath.Rel(scope.root, path)
+		if rerr != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if path == start {
+				return nil
+			}
+			if rel == ".sync_temp" || (scope.ic != nil && scope.ic.MatchWithManualTransfer(path, true)) {
+				return filepath.SkipDir
+			}
+			res.Dirs++
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if scope.ic != nil && scope.ic.MatchWithManualTransfer(path, false) {
+			return nil
+		}
+		info, ierr := d.Info()
+		if ierr != nil {
+			return nil
+		}
+		size := info.Size()
+		res.Files++
+		res.Bytes += size
+		res.Largest = appendLargest(res.Largest, DuFile{Rel: rel, Size: size}, top)
+
+		// group by the first path component below the prefix
+		inner := rel
+		if prefix != "" {
+			inner = strings.TrimPrefix(rel, prefix+"/")
+		}
+		group := "."
+		if i := strings.IndexByte(inner, '/'); i > 0 {
+			group = inner[:i]
+		}
+		sd, ok := subdirs[group]
+		if !ok {
+			sd = &DuDir{Path: group}
+			subdirs[group] = sd
+		}
+		sd.Files++
+		sd.Bytes += size
+		return nil
+	})
+
+	for _, sd := range subdirs {
+		res.Subdirs = append(res.Subdirs, *sd)
+	}
+	sort.Slice(res.Subdirs, func(i, j int) bool {
+		if res.Subdirs[i].Bytes != res.Subdirs[j].Bytes {
+			return res.Subdirs[i].Bytes > res.Subdirs[j].Bytes
+		}
+		return res.Subdirs[i].Path < res.Subdirs[j].Path
+	})
+	return res
+}
+
+// appendLargest keeps list sorted by size (descending) and at most top long.
+func appendLargest(list []DuFile, f DuFile, top int) []DuFile {
+	if len(list) >= top && f.Size <= list[len(list)-1].Size {
+		return list
+	}
+	i := sort.Search(len(list), func(i int) bool { return list[i].Size < f.Size })
+	list = append(list, DuFile{})
+	copy(list[i+1:], list[i:])
+	list[i] = f
+	if len(list) > top {
+		list = list[:top]
+	}
+	return list
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPerformDu(t *testing.T) {
+	dir, err := os.MkdirTemp("", "agent-du-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]int{
+		"vendor/a.txt":       10,
+		"vendor/lib/b.bin":   300,
+		"vendor/lib/c.bin":   200,
+		"vendor/other/d.txt": 50,
+		"outside/e.txt":      1000,
+	}
+	for rel, size := range files {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, make([]byte, size), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	oldwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer os.Chdir(oldwd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	report, err := performDu([]string{"vendor"}, true, 2)
+	if err != nil {
+		t.Fatalf("performDu failed: %v", err)
+	}
+	if len(report.Prefixes) != 1 {
+		t.Fatalf("expected 1 prefix, got %d", len(report.Prefixes))
+	}
+	p := report.Prefixes[0]
+	if p.Files != 4 || p.Bytes != 560 || p.Dirs != 2 {
+		t.Fatalf("unexpected totals: files=%d bytes=%d dirs=%d", p.Files, p.Bytes, p.Dirs)
+	}
+	if len(p.Largest) != 2 || p.Largest[0].Rel != "vendor/lib/b.bin" || p.Largest[1].Rel != "vendor/lib/c.bin" {
+		t.Fatalf("unexpected largest files: %+v", p.Largest)
+	}
+	if len(p.Subdirs) != 3 || p.Subdirs[0].Path != "lib" || p.Subdirs[0].Bytes != 500 || p.Subdirs[2].Path != "." {
+		t.Fatalf("unexpected subdirs: %+v", p.Subdirs)
+	}
+}
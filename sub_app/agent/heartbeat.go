@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/rjeczalik/notify"
+
+	"sync-agent/internal/util"
+)
+
+// Event handler progress, read by the heartbeat loop. Values are UnixNano
+// timestamps; zero means "never" (lastEventAt) or "idle" (handlingSince).
+var (
+	lastEventAt   atomic.Int64
+	handlingSince atomic.Int64
+)
+
+// eventQueue is the notify channel once the watcher is set up; nil while the
+// agent still waits for watch paths.
+var eventQueue atomic.Pointer[chan notify.EventInfo]
+
+var heartbeatStarted atomic.Bool
+
+// queueDepth returns the number of events waiting for the handler.
+func queueDepth() int {
+	if q := eventQueue.Load(); q != nil {
+		return len(*q)
+	}
+	return 0
+}
+
+// startHeartbeat periodically prints a HEARTBEAT line carrying the event queue
+// depth and the time of the last handled event. A zero or negative interval
+// disables heartbeats (older clients do not send one). Only the first call
+// with a positive interval starts the loop.
+func startHeartbeat(interval time.Duration) {
+	if interval <= 0 || !heartbeatStarted.CompareAndSwap(false, true) {
+		return
+	}
+
+	util.Default.ClearLine()
+	util.Default.Printf("💓 Heartbeat enabled every %s\n", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-mainCtx.Done():
+				return
+			case now := <-ticker.C:
+				// An event stuck in the handler for longer than one interval means
+				// the agent is blocked (e.g. hashing on a slow disk). Withhold the
+				// beat so the client can detect the hang.
+				if isHandlerStalled(now, interval) {
+					continue
+				}
+				line := formatHeartbeat(now, queueDepth(), lastEventAt.Load())
+				util.Default.ClearLine()
+				util.Default.Printf("%s\n", line)
+				os.Stdout.Sync()
+				// The detached watcher has no client on stdout; `tail` forwards
+				// heartbeats from this file instead.
+				if journalEnabled {
+					_ = os.WriteFile(heartbeatFile, []byte(line+"\n"), 0644)
+				}
+			}
+		}
+	}()
+}
+
+// isHandlerStalled reports whether the current event has been in the handler
+// for longer than the given interval.
+func isHandlerStalled(now time.Time, interval time.Duration) bool {
+	since := handlingSince.Load()
+	if since == 0 {
+		return false
+	}
+	return now.Sub(time.Unix(0, since)) > interval
+}
+
+// formatHeartbeat builds the line: [timestamp] HEARTBEAT|queue_depth|last_event
+// where last_event is "-" when no event has been handled yet.
+func formatHeartbeat(now time.Time, queueDepth int, lastEvent int64) string {
+	last := "-"
+	if lastEvent > 0 {
+		last = time.Unix(0, lastEvent).Format("2006-01-02 15:04:05")
+	}
+	return fmt.Sprintf("[%s] HEARTBEAT|%d|%s", now.Format("2006-01-02 15:04:05"), queueDepth, last)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatHeartbeat(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local)
+
+	line := formatHeartbeat(now, 3, 0)
+	if !strings.HasSuffix(line, "HEARTBEAT|3|-") {
+		t.Fatalf("expected no last event marker, got %q", line)
+	}
+
+	last := now.Add(-time.Minute)
+	line = formatHeartbeat(now, 0, last.UnixNano())
+	want := "[2025-01-02 03:04:05] HEARTBEAT|0|2025-01-02 03:03:05"
+	if line != want {
+		t.Fatalf("expected %q, got %q", want, line)
+	}
+}
+
+func TestIsHandlerStalled(t *testing.T) {
+	defer handlingSince.Store(0)
+	now := time.Now()
+
+	handlingSince.Store(0)
+	if isHandlerStalled(now, time.Second) {
+		t.Fatalf("idle handler must not be reported as stalled")
+	}
+
+	handlingSince.Store(now.Add(-500 * time.Millisecond).UnixNano())
+	if isHandlerStalled(now, time.Second) {
+		t.Fatalf("handler busy for less than one interval must not be stalled")
+	}
+
+	handlingSince.Store(now.Add(-3 * time.Second).UnixNano())
+	if !isHandlerStalled(now, time.Second) {
+		t.Fatalf("handler busy for longer than one interval must be stalled")
+	}
+}
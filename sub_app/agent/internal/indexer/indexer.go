@@ -4,14 +4,12 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	_ "modernc.org/sqlite"
-
-	"github.com/cespare/xxhash/v2"
 )
 
 // FileMeta holds basic metadata for a file
@@ -42,6 +40,9 @@ func BuildIndex(root string, bypassIgnore bool) (IndexMap, error) {
 		ic = NewSimpleIgnoreCache(root)
 	}
 
+	var mu sync.Mutex
+	pool := newHashPool(currentThrottle())
+
 	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
 		if err != nil {
 			// skip problematic entries but continue
@@ -85,19 +86,28 @@ func BuildIndex(root string, bypassIgnore bool) (IndexMap, error) {
 		}
 
 		if !info.IsDir() {
-			h, err := hashFile(p)
-			if err == nil {
-				meta.Hash = h
-			} else {
-				// if hashing fails, continue without hash
-				fmt.Fprintf(os.Stderr, "warning: failed to hash %s: %v\n", p, err)
-			}
+			// hash on the throttled worker pool; the entry is stored once hashed
+			pool.submit(p, func(h string, err error) {
+				if err == nil {
+					meta.Hash = h
+				} else {
+					// if hashing fails, continue without hash
+					fmt.Fprintf(os.Stderr, "warning: failed to hash %s: %v\n", p, err)
+				}
+				mu.Lock()
+				idx[meta.Path] = meta
+				mu.Unlock()
+			})
+			return nil
 		}
 
 		// use absolute path as the map key (recommended)
+		mu.Lock()
 		idx[meta.Path] = meta
+		mu.Unlock()
 		return nil
 	})
+	pool.wait()
 
 	return idx, err
 }
@@ -119,6 +129,9 @@ func BuildIndexSubtree(root, start string, bypassIgnore bool) (IndexMap, error)
 		ic = NewSimpleIgnoreCache(root)
 	}
 
+	var mu sync.Mutex
+	pool := newHashPool(currentThrottle())
+
 	// Walk starting at start
 	err = filepath.WalkDir(start, func(p string, d os.DirEntry, err error) error {
 		if err != nil {
@@ -164,18 +177,27 @@ func BuildIndexSubtree(root, start string, bypassIgnore bool) (IndexMap, error)
 		}
 
 		if !info.IsDir() {
-			h, err := hashFile(p)
-			if err == nil {
-				meta.Hash = h
-			} else {
-				// if hashing fails, continue without hash
-				fmt.Fprintf(os.Stderr, "warning: failed to hash %s: %v\n", p, err)
-			}
+			// hash on the throttled worker pool; the entry is stored once hashed
+			pool.submit(p, func(h string, err error) {
+				if err == nil {
+					meta.Hash = h
+				} else {
+					// if hashing fails, continue without hash
+					fmt.Fprintf(os.Stderr, "warning: failed to hash %s: %v\n", p, err)
+				}
+				mu.Lock()
+				idx[meta.Path] = meta
+				mu.Unlock()
+			})
+			return nil
 		}
 
+		mu.Lock()
 		idx[meta.Path] = meta
+		mu.Unlock()
 		return nil
 	})
+	pool.wait()
 
 	// If start is a single file and not a directory, ensure it was included
 	if !stInfo.IsDir() {
@@ -185,19 +207,6 @@ func BuildIndexSubtree(root, start string, bypassIgnore bool) (IndexMap, error)
 	return idx, err
 }
 
-func hashFile(path string) (string, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return "", err
-	}
-	defer f.Close()
-	h := xxhash.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return "", err
-	}
-	return fmt.Sprintf("%x", h.Sum(nil)), nil
-}
-
 // SaveIndexFile writes the index as JSON to dbPath (overwrites)
 func SaveIndexFile(dbPath string, idx IndexMap) error {
 	tmp := dbPath + ".tmp"
@@ -0,0 +1,145 @@
+package indexer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Throttle limits the resources indexing uses while hashing file contents.
+type Throttle struct {
+	// Workers is the maximum number of files hashed concurrently (<= 0 means 1).
+	Workers int
+	// ReadBytesPerSec caps the combined read rate of all workers (0 = unlimited).
+	ReadBytesPerSec int64
+}
+
+var (
+	throttleMu     sync.RWMutex
+	activeThrottle = Throttle{Workers: 1}
+)
+
+// SetThrottle configures hashing limits used by BuildIndex and BuildIndexSubtree.
+func SetThrottle(t Throttle) {
+	if t.Workers <= 0 {
+		t.Workers = 1
+	}
+	if t.ReadBytesPerSec < 0 {
+		t.ReadBytesPerSec = 0
+	}
+	throttleMu.Lock()
+	activeThrottle = t
+	throttleMu.Unlock()
+}
+
+func currentThrottle() Throttle {
+	throttleMu.RLock()
+	defer throttleMu.RUnlock()
+	return activeThrottle
+}
+
+// rateLimiter paces reads so that, summed over all callers, no more than
+// rate bytes per second are consumed.
+type rateLimiter struct {
+	mu   sync.Mutex
+	rate int64
+	next time.Time
+}
+
+// wait blocks until the previously reserved bytes are "paid for" and then
+// reserves n more.
+func (l *rateLimiter) wait(n int) {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.rate))
+	l.mu.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// limitedReader reads in chunks of at most limitedChunk bytes through a rateLimiter.
+type limitedReader struct {
+	r io.Reader
+	l *rateLimiter
+}
+
+const limitedChunk = 64 * 1024
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > limitedChunk {
+		p = p[:limitedChunk]
+	}
+	lr.l.wait(len(p))
+	return lr.r.Read(p)
+}
+
+// hashPool hashes files with a bounded number of workers and an optional
+// shared read-rate limit.
+type hashPool struct {
+	jobs    chan hashJob
+	wg      sync.WaitGroup
+	limiter *rateLimiter
+}
+
+type hashJob struct {
+	path string
+	done func(hash string, err error)
+}
+
+func newHashPool(t Throttle) *hashPool {
+	workers := t.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	hp := &hashPool{jobs: make(chan hashJob, workers*2)}
+	if t.ReadBytesPerSec > 0 {
+		hp.limiter = &rateLimiter{rate: t.ReadBytesPerSec}
+	}
+	for i := 0; i < workers; i++ {
+		hp.wg.Add(1)
+		go func() {
+			defer hp.wg.Done()
+			for job := range hp.jobs {
+				job.done(hp.hash(job.path))
+			}
+		}()
+	}
+	return hp
+}
+
+// submit queues path for hashing; done runs on a worker goroutine.
+func (hp *hashPool) submit(path string, done func(hash string, err error)) {
+	hp.jobs <- hashJob{path: path, done: done}
+}
+
+// wait blocks until all submitted files are hashed.
+func (hp *hashPool) wait() {
+	close(hp.jobs)
+	hp.wg.Wait()
+}
+
+func (hp *hashPool) hash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if hp.limiter != nil {
+		r = &limitedReader{r: f, l: hp.limiter}
+	}
+	h := xxhash.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,55 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBuildIndexWithThrottle(t *testing.T) {
+	dir, err := os.MkdirTemp("", "indexer-throttle-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for i, name := range []string{"a.txt", "b.txt", "sub/c.txt", "sub/d.txt"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, make([]byte, 64*1024*(i+1)), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	defer SetThrottle(Throttle{})
+
+	SetThrottle(Throttle{Workers: 1})
+	serial, err := BuildIndex(dir, true)
+	if err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+
+	// 640 KiB at 2 MiB/s should take roughly 300ms with 4 workers sharing the cap
+	SetThrottle(Throttle{Workers: 4, ReadBytesPerSec: 2 * 1024 * 1024})
+	start := time.Now()
+	parallel, err := BuildIndex(dir, true)
+	if err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("expected read cap to slow hashing, took %s", elapsed)
+	}
+
+	if len(serial) != len(parallel) {
+		t.Fatalf("expected %d entries, got %d", len(serial), len(parallel))
+	}
+	for k, v := range serial {
+		if parallel[k].Hash != v.Hash {
+			t.Fatalf("hash mismatch for %s: %q vs %q", k, v.Hash, parallel[k].Hash)
+		}
+		if !v.IsDir && v.Hash == "" {
+			t.Fatalf("expected hash for %s", k)
+		}
+	}
+}
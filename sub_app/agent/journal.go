@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sync-agent/internal/util"
+)
+
+// Journal files live in the working dir's .sync_temp so they survive SSH
+// disconnects together with the detached watcher.
+const (
+	journalFile     = ".sync_temp/agent_journal.log"
+	journalRotated  = ".sync_temp/agent_journal.log.1"
+	watchPidFile    = ".sync_temp/agent_watch.pid"
+	watchLogFile    = ".sync_temp/agent_watch.log"
+	heartbeatFile   = ".sync_temp/agent_heartbeat"
+	journalMaxBytes = 8 * 1024 * 1024
+)
+
+// eventJournal appends event lines prefixed with a monotonically increasing
+// sequence number ("<seq> <line>"). Clients replay from their last cursor.
+type eventJournal struct {
+	mu   sync.Mutex
+	f    *os.File
+	seq  int64
+	size int64
+}
+
+// activeJournal is set when the watcher runs in journal (detached) mode
+var activeJournal *eventJournal
+
+// openJournal opens the journal for appending and restores the last sequence
+// number so cursors stay valid across agent restarts.
+func openJournal() (*eventJournal, error) {
+	if err := os.MkdirAll(filepath.Dir(journalFile), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal dir: %v", err)
+	}
+
+	seq := lastJournalSeq(journalFile)
+	if seq == 0 {
+		seq = lastJournalSeq(journalRotated)
+	}
+
+	f, err := os.OpenFile(journalFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %v", err)
+	}
+	var size int64
+	if info, err := f.Stat(); err == nil {
+		size = info.Size()
+	}
+	return &eventJournal{f: f, seq: seq, size: size}, nil
+}
+
+// Append writes one line to the journal and returns its sequence number.
+func (j *eventJournal) Append(line string) int64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.size >= journalMaxBytes {
+		j.rotate()
+	}
+
+	j.seq++
+	entry := fmt.Sprintf("%d %s\n", j.seq, strings.TrimRight(line, "\n"))
+	n, err := j.f.WriteString(entry)
+	j.size += int64(n)
+	if err != nil {
+		util.Default.ClearLine()
+		util.Default.Printf("⚠️  Failed to append journal entry: %v\n", err)
+	}
+	return j.seq
+}
+
+// rotate keeps a single previous generation so slightly stale cursors can
+// still be replayed. Caller holds j.mu.
+func (j *eventJournal) rotate() {
+	j.f.Close()
+	_ = os.Rename(journalFile, journalRotated)
+	f, err := os.OpenFile(journalFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		util.Default.ClearLine()
+		util.Default.Printf("⚠️  Failed to rotate journal: %v\n", err)
+		// keep appending to the rotated file rather than losing events
+		f, _ = os.OpenFile(journalRotated, os.O_WRONLY|os.O_APPEND, 0644)
+	}
+	j.f = f
+	j.size = 0
+}
+
+// Close flushes and closes the journal file.
+func (j *eventJournal) Close() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f != nil {
+		j.f.Sync()
+		j.f.Close()
+		j.f = nil
+	}
+}
+
+// emitEventLine prints a structured event line and records it in the journal
+// when the watcher runs detached.
+func emitEventLine(line string) {
+	util.Default.ClearLine()
+	util.Default.Printf("%s\n", line)
+	if activeJournal != nil {
+		activeJournal.Append(line)
+	}
+}
+
+// parseJournalEntry splits "<seq> <line>" into its parts.
+func parseJournalEntry(entry string) (int64, string, bool) {
+	entry = strings.TrimRight(entry, "\r\n")
+	sp := strings.IndexByte(entry, ' ')
+	if sp <= 0 {
+		return 0, "", false
+	}
+	seq, err := strconv.ParseInt(entry[:sp], 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return seq, entry[sp+1:], true
+}
+
+// lastJournalSeq returns the sequence number of the last complete entry in path.
+func lastJournalSeq(path string) int64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	var last int64
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if seq, _, ok := parseJournalEntry(scanner.Text()); ok {
+			last = seq
+		}
+	}
+	return last
+}
+
+// replayJournalFile prints entries of path with seq > cursor and returns the
+// highest sequence printed (or cursor when nothing was printed).
+func replayJournalFile(path string, cursor int64, out io.Writer) int64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return cursor
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		seq, _, ok := parseJournalEntry(scanner.Text())
+		if !ok || seq <= cursor {
+			continue
+		}
+		fmt.Fprintf(out, "%s\n", scanner.Text())
+		cursor = seq
+	}
+	return cursor
+}
+
+// writeWatchPid records the detached watcher PID for `watch --detach` and `tail`.
+func writeWatchPid() {
+	_ = os.WriteFile(watchPidFile, []byte(strconv.Itoa(os.Getpid())), 0644)
+}
+
+// readWatchPid returns the PID recorded by the detached watcher, or 0.
+func readWatchPid() int {
+	data, err := os.ReadFile(watchPidFile)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+// configNewerThanWatcher reports whether .sync_temp/config.json was modified
+// after the detached watcher wrote its PID file.
+func configNewerThanWatcher() bool {
+	pidInfo, err := os.Stat(watchPidFile)
+	if err != nil {
+		return false
+	}
+	cfgInfo, err := os.Stat(".sync_temp/config.json")
+	if err != nil {
+		return false
+	}
+	return cfgInfo.ModTime().After(pidInfo.ModTime())
+}
+
+// startDetachedWatch launches `watch --journal` as a detached background
+// process unless one is already running, then prints its PID and returns.
+func startDetachedWatch() error {
+	if pid := readWatchPid(); pid > 0 && pid != os.Getpid() && isProcessAlive(pid) {
+		if !configNewerThanWatcher() {
+			fmt.Printf("AGENT_PID:%d\n", pid)
+			util.Default.ClearLine()
+			util.Default.Printf("♻️  Detached watcher already running (pid %d)\n", pid)
+			return nil
+		}
+		// Config was re-synced since the watcher started; restart it so new
+		// watch paths and settings take effect. The journal keeps its sequence.
+		util.Default.ClearLine()
+		util.Default.Printf("🔁 Config changed, restarting detached watcher (pid %d)\n", pid)
+		if proc, err := os.FindProcess(pid); err == nil {
+			_ = proc.Kill()
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to resolve executable: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(watchLogFile), 0755); err != nil {
+		return fmt.Errorf("failed to create .sync_temp: %v", err)
+	}
+	logFile, err := os.OpenFile(watchLogFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open watcher log: %v", err)
+	}
+	defer logFile.Close()
+
+	pid, err := spawnDetached(exePath, []string{"watch", "--journal"}, logFile)
+	if err != nil {
+		return fmt.Errorf("failed to start detached watcher: %v", err)
+	}
+
+	fmt.Printf("AGENT_PID:%d\n", pid)
+	util.Default.ClearLine()
+	util.Default.Printf("🚀 Detached watcher started (pid %d, log %s)\n", pid, watchLogFile)
+	return nil
+}
+
+// tailStart returns the cursor a tail from cursor starts at, given the last
+// journal seq. A negative cursor starts at the end. A cursor ahead of the
+// journal means the journal was reset (agent redeployed, .sync_temp
+// cleared): every entry is new to the client, so reset is set and the tail
+// replays from 0.
+func tailStart(cursor, last int64) (start int64, reset bool) {
+	switch {
+	case cursor < 0:
+		return last, false
+	case cursor > last:
+		return 0, true
+	}
+	return cursor, false
+}
+
+// performTail replays journal entries after cursor and then follows the
+// journal, forwarding heartbeats from the detached watcher. See tailStart
+// for where it starts. It returns when the watcher process is gone or the
+// context is cancelled.
+func performTail(cursor int64) {
+	last := lastJournalSeq(journalFile)
+	if last == 0 {
+		last = lastJournalSeq(journalRotated)
+	}
+	start, reset := tailStart(cursor, last)
+	if reset {
+		util.Default.ClearLine()
+		util.Default.Printf("⚠️  Cursor %d is ahead of journal (%d), journal was reset — replaying from the start\n", cursor, last)
+	}
+	cursor = start
+	fmt.Printf("JOURNAL_CURSOR:%d\n", cursor)
+
+	// Replay the previous generation first when the cursor predates rotation
+	cursor = replayJournalFile(journalRotated, cursor, os.Stdout)
+
+	var (
+		offset        int64
+		partial       string
+		lastHeartbeat string
+		lastPidCheck  = time.Now().Add(3 * time.Second) // grace period for a freshly spawned watcher
+	)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-mainCtx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		offset, partial, cursor = followJournal(offset, partial, cursor)
+
+		if hb, err := os.ReadFile(heartbeatFile); err == nil {
+			if s := strings.TrimSpace(string(hb)); s != "" && s != lastHeartbeat {
+				lastHeartbeat = s
+				fmt.Println(s)
+			}
+		}
+
+		if time.Since(lastPidCheck) >= 2*time.Second {
+			lastPidCheck = time.Now()
+			if pid := readWatchPid(); pid == 0 || !isProcessAlive(pid) {
+				ts := time.Now().Format("2006-01-02 15:04:05")
+				fmt.Printf("[%s] ERROR|watcher_exited|%d\n", ts, pid)
+				return
+			}
+		}
+		os.Stdout.Sync()
+	}
+}
+
+// followJournal prints newly appended complete entries of the current journal
+// starting at offset. It detects rotation (file shrank) and restarts from 0.
+func followJournal(offset int64, partial string, cursor int64) (int64, string, int64) {
+	f, err := os.Open(journalFile)
+	if err != nil {
+		return offset, partial, cursor
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return offset, partial, cursor
+	}
+	if info.Size() < offset {
+		// journal rotated; the new file only holds entries after the rotation
+		offset, partial = 0, ""
+	}
+	if info.Size() == offset {
+		return offset, partial, cursor
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, partial, cursor
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return offset, partial, cursor
+	}
+	offset += int64(len(data))
+
+	chunk := partial + string(data)
+	lines := strings.Split(chunk, "\n")
+	partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		seq, _, ok := parseJournalEntry(line)
+		if !ok || seq <= cursor {
+			continue
+		}
+		fmt.Println(line)
+		cursor = seq
+	}
+	return offset, partial, cursor
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestJournalAppendAndReplay(t *testing.T) {
+	tmp, err := os.MkdirTemp("", "agent-journal-test-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	oldwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer os.Chdir(oldwd)
+
+	j, err := openJournal()
+	if err != nil {
+		t.Fatalf("openJournal failed: %v", err)
+	}
+	for _, line := range []string{"[t] EVENT|Create|/a", "[t] HASH|/a|1", "[t] EVENT|Remove|/b"} {
+		j.Append(line)
+	}
+	j.Close()
+
+	// Sequence numbers continue after reopening
+	j, err = openJournal()
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if seq := j.Append("[t] EVENT|Write|/c"); seq != 4 {
+		t.Fatalf("expected seq 4 after reopen, got %d", seq)
+	}
+	j.Close()
+
+	var out bytes.Buffer
+	last := replayJournalFile(journalFile, 2, &out)
+	if last != 4 {
+		t.Fatalf("expected replay to end at seq 4, got %d", last)
+	}
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(got) != 2 || got[0] != "3 [t] EVENT|Remove|/b" || got[1] != "4 [t] EVENT|Write|/c" {
+		t.Fatalf("unexpected replay output: %q", got)
+	}
+}
+
+func TestParseJournalEntry(t *testing.T) {
+	seq, line, ok := parseJournalEntry("12 [t] EVENT|Create|/a b\n")
+	if !ok || seq != 12 || line != "[t] EVENT|Create|/a b" {
+		t.Fatalf("unexpected parse result: %d %q %v", seq, line, ok)
+	}
+	if _, _, ok := parseJournalEntry("[t] EVENT|Create|/a"); ok {
+		t.Fatalf("expected non-journal line to be rejected")
+	}
+}
+
+func TestTailStart(t *testing.T) {
+	tests := []struct {
+		cursor, last int64
+		start        int64
+		reset        bool
+	}{
+		{-1, 42, 42, false},
+		{10, 42, 10, false},
+		{42, 42, 42, false},
+		{50, 42, 0, true},
+		{50, 0, 0, true},
+	}
+	for _, tt := range tests {
+		start, reset := tailStart(tt.cursor, tt.last)
+		if start != tt.start || reset != tt.reset {
+			t.Errorf("tailStart(%d, %d) = %d, %v, want %d, %v", tt.cursor, tt.last, start, reset, tt.start, tt.reset)
+		}
+	}
+}
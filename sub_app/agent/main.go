@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -27,6 +28,8 @@ type AgentConfig struct {
 		SizeLimit   int      `json:"size_limit"`
 		AgentWatchs []string `json:"agent_watchs"`
 		WorkingDir  string   `json:"working_dir"`
+		// HeartbeatInterval is the heartbeat period in seconds (0 = disabled)
+		HeartbeatInterval int `json:"heartbeat_interval"`
 	} `json:"devsync"`
 }
 
@@ -36,6 +39,8 @@ var (
 	mainCancel   context.CancelFunc
 	shutdownMu   sync.Mutex
 	globalConfig *AgentConfig
+	// journalEnabled is set for `watch --journal` (the detached watcher)
+	journalEnabled bool
 )
 
 // loadConfig loads configuration from .sync_temp/config.json in current dir or executable dir
@@ -165,8 +170,9 @@ func main() {
 		gracefulShutdown()
 	}()
 
-	// On Windows, start parent watcher to auto-exit if parent dies
-	if runtime.GOOS == "windows" {
+	// On Windows, start parent watcher to auto-exit if parent dies.
+	// The detached journal watcher must outlive its launcher, so skip it there.
+	if runtime.GOOS == "windows" && !hasArg(os.Args[1:], "--journal") {
 		startParentWatcher()
 	}
 
@@ -181,6 +187,20 @@ func main() {
 		manualFlagPresent := false
 		// prune dry-run flag
 		dryRun := false
+		// watch: --detach launches a background journal watcher, --journal runs it
+		detach := false
+		journalMode := false
+		// tail: --cursor <seq> resumes after the given journal sequence (-1 = from end)
+		cursor := int64(-1)
+		// pack: --files-from <file|-> lists relative paths to include
+		filesFrom := ""
+		// du: --top <n> limits the largest-files list per prefix
+		top := 0
+		// indexing throttle: --nice, --ionice, --workers, --read-bps
+		nice := 0
+		ionice := ""
+		workers := 0
+		readBps := int64(0)
 		args := os.Args[2:]
 		for i := 0; i < len(args); i++ {
 			arg := args[i]
@@ -192,6 +212,68 @@ func main() {
 				dryRun = true
 				continue
 			}
+			if arg == "--detach" {
+				detach = true
+				continue
+			}
+			if arg == "--journal" {
+				journalMode = true
+				continue
+			}
+			if arg == "--files-from" {
+				if i+1 < len(args) {
+					filesFrom = args[i+1]
+					i++ // skip value
+				}
+				continue
+			}
+			if arg == "--nice" || arg == "--workers" {
+				if i+1 < len(args) {
+					if n, err := strconv.Atoi(args[i+1]); err == nil {
+						if arg == "--nice" {
+							nice = n
+						} else {
+							workers = n
+						}
+					}
+					i++ // skip value
+				}
+				continue
+			}
+			if arg == "--ionice" {
+				if i+1 < len(args) {
+					ionice = args[i+1]
+					i++ // skip value
+				}
+				continue
+			}
+			if arg == "--read-bps" {
+				if i+1 < len(args) {
+					if n, err := strconv.ParseInt(args[i+1], 10, 64); err == nil {
+						readBps = n
+					}
+					i++ // skip value
+				}
+				continue
+			}
+			if arg == "--top" {
+				if i+1 < len(args) {
+					if n, err := strconv.Atoi(args[i+1]); err == nil {
+						top = n
+					}
+					i++ // skip value
+				}
+				continue
+			}
+			if arg == "--cursor" {
+				if i+1 < len(args) {
+					if n, err := strconv.ParseInt(args[i+1], 10, 64); err == nil {
+						cursor = n
+					}
+					i++ // skip value
+				}
+				continue
+			}
 			if arg == "--manual-transfer" || arg == "--manual_transfer" {
 				manualFlagPresent = true
 				// try to read a following value if present and not another flag
@@ -221,8 +303,32 @@ func main() {
 			displayConfig()
 			return
 		case "watch":
+			if detach {
+				// Launch (or reuse) a background watcher that journals events
+				if _, err := loadConfigAndChangeDir(); err != nil {
+					util.Default.ClearLine()
+					util.Default.Printf("⚠️  Config setup failed: %v\n", err)
+					os.Exit(1)
+				}
+				if err := startDetachedWatch(); err != nil {
+					util.Default.ClearLine()
+					util.Default.Printf("❌ %v\n", err)
+					os.Exit(1)
+				}
+				return
+			}
+			journalEnabled = journalMode
 			startWatching()
 			return
+		case "tail":
+			// Replay journal entries after --cursor and follow new ones
+			if _, err := loadConfigAndChangeDir(); err != nil {
+				util.Default.ClearLine()
+				util.Default.Printf("⚠️  Config setup failed: %v\n", err)
+				os.Exit(1)
+			}
+			performTail(cursor)
+			return
 		case "indexing":
 			// perform indexing now and write .sync_temp/indexing_files.db
 			// Load config and change working directory first, then perform indexing
@@ -238,6 +344,7 @@ func main() {
 				util.Default.ClearLine()
 				util.Default.Printf("🔄 Bypass ignore mode enabled via --bypass-ignore flag\n")
 			}
+			applyIndexingThrottle(nice, ionice, workers, readBps)
 			performIndexing(config, bypassOverride, manualFlagPresent, manualPrefixes)
 			return
 		case "prune":
@@ -267,6 +374,69 @@ func main() {
 				util.Default.Printf("! failed: %s -> %v\n", f.Path, f.Error)
 			}
 			return
+		case "pack":
+			// stdout carries the tar stream; keep all log output off it
+			util.Default.Suspend()
+			if _, err := loadConfigAndChangeDir(); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR|config|%v\n", err)
+				os.Exit(1)
+			}
+			var rels []string
+			if filesFrom != "" {
+				var in io.Reader = os.Stdin
+				if filesFrom != "-" {
+					f, err := os.Open(filesFrom)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "ERROR|files_from|%v\n", err)
+						os.Exit(1)
+					}
+					defer f.Close()
+					in = f
+				}
+				list, err := readFileList(in)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR|files_from|%v\n", err)
+					os.Exit(1)
+				}
+				// non-nil even when empty: an empty list packs nothing
+				rels = append([]string{}, list...)
+			}
+			if err := performPack(manualPrefixes, bypassOverride, rels, os.Stdout, os.Stderr); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR|pack|%v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "du":
+			// stdout carries a single JSON report line; keep log output off it
+			util.Default.Suspend()
+			if _, err := loadConfigAndChangeDir(); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR|config|%v\n", err)
+				os.Exit(1)
+			}
+			report, err := performDu(manualPrefixes, bypassOverride, top)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR|du|%v\n", err)
+				os.Exit(1)
+			}
+			data, err := json.Marshal(report)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR|du|%v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		case "unpack":
+			// extract a tar stream from stdin into the working dir
+			if _, err := loadConfigAndChangeDir(); err != nil {
+				util.Default.ClearLine()
+				util.Default.Printf("⚠️  Config setup failed: %v\n", err)
+				os.Exit(1)
+			}
+			if err := performUnpack(manualPrefixes, bypassOverride, os.Stdin, os.Stdout); err != nil {
+				fmt.Printf("ERROR|unpack|%v\n", err)
+				os.Exit(1)
+			}
+			return
 		case "help":
 			fmt.Println("Sync Agent v1.0.0")
 			fmt.Println("")
@@ -275,11 +445,23 @@ func main() {
 			fmt.Println("  version      - Print agent version")
 			fmt.Println("  config       - Display current configuration")
 			fmt.Println("  watch        - Start file watching mode")
+			fmt.Println("  tail         - Replay and follow the detached watcher journal")
+			fmt.Println("  pack         - Write files as a tar stream to stdout (report on stderr)")
+			fmt.Println("  unpack       - Extract a tar stream from stdin into the working dir")
+			fmt.Println("  du           - Print file counts and sizes of prefixes as JSON")
 			fmt.Println("  indexing     - Perform one-time indexing and exit")
 			fmt.Println("  help         - Show this help message")
 			fmt.Println("")
 			fmt.Println("Flags:")
 			fmt.Println("  --bypass-ignore  Bypass .sync_ignore patterns during indexing (temporary override)")
+			fmt.Println("  --detach         (watch) Run the watcher in the background, journaling events")
+			fmt.Println("  --cursor <seq>   (tail) Replay journal entries after sequence <seq>")
+			fmt.Println("  --files-from <f> (pack) Read relative paths from file <f>, '-' for stdin")
+			fmt.Println("  --top <n>        (du) Number of largest files to list per prefix")
+			fmt.Println("  --nice <n>       (indexing) Lower CPU priority (Windows: below normal, idle for >= 15)")
+			fmt.Println("  --ionice <spec>  (indexing) IO priority: idle, best-effort[:0-7] (Windows: idle = background mode)")
+			fmt.Println("  --workers <n>    (indexing) Maximum number of files hashed concurrently (default 1)")
+			fmt.Println("  --read-bps <n>   (indexing) Cap on bytes read per second while hashing")
 			fmt.Println("")
 			fmt.Println("Examples:")
 			fmt.Println("  ./sync-agent indexing                    # Index respecting ignore patterns")
@@ -297,6 +479,16 @@ func main() {
 	startWatching()
 }
 
+// hasArg reports whether flag appears in args.
+func hasArg(args []string, flag string) bool {
+	for _, a := range args {
+		if a == flag {
+			return true
+		}
+	}
+	return false
+}
+
 // gracefulShutdown initiates coordinated shutdown
 func gracefulShutdown() {
 	shutdownMu.Lock()
@@ -308,6 +500,13 @@ func gracefulShutdown() {
 	// Give goroutines a moment to clean up
 	time.Sleep(100 * time.Millisecond)
 
+	if activeJournal != nil {
+		activeJournal.Close()
+		if readWatchPid() == os.Getpid() {
+			os.Remove(watchPidFile)
+		}
+	}
+
 	fmt.Println("✅ Agent shutdown complete")
 	os.Exit(0)
 }
@@ -336,6 +535,24 @@ func startWatching() {
 	// Store config globally for use in handleFileEvent
 	globalConfig = config
 
+	// Emit heartbeats so the client can detect a hung agent, also while the
+	// agent waits for watch paths or notify setup keeps failing
+	startHeartbeat(time.Duration(config.Devsync.HeartbeatInterval) * time.Second)
+
+	// Detached mode: journal events so clients can replay them after reconnecting
+	if journalEnabled {
+		j, err := openJournal()
+		if err != nil {
+			util.Default.ClearLine()
+			util.Default.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		activeJournal = j
+		writeWatchPid()
+		util.Default.ClearLine()
+		util.Default.Printf("📒 Journaling events to %s (seq=%d)\n", journalFile, j.seq)
+	}
+
 	// Get current working directory after config loading
 	workingDir, err := os.Getwd()
 	if err != nil {
@@ -400,6 +617,8 @@ func startWatching() {
 								newPaths[i] = wp
 							}
 						}
+						// no-op when the heartbeat already runs
+						startHeartbeat(time.Duration(cfg.Devsync.HeartbeatInterval) * time.Second)
 						util.Default.ClearLine()
 						util.Default.Printf("✅ Detected new watch paths: %v — starting watcher\n", newPaths)
 						setupWatcher(newPaths)
@@ -594,10 +813,15 @@ func tryNotifyWatcher(watchPaths []string) bool {
 			if e == nil {
 				continue
 			}
+			handlingSince.Store(time.Now().UnixNano())
 			handleFileEvent(e)
+			handlingSince.Store(0)
+			lastEventAt.Store(time.Now().UnixNano())
 		}
 	}()
 
+	eventQueue.Store(&c)
+
 	// Registration goroutine: try to register each path independently
 	go func() {
 		// Track which paths have been successfully registered
@@ -679,8 +903,7 @@ func tryNotifyWatcher(watchPaths []string) bool {
 func handleFileEvent(event notify.EventInfo) {
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 	// Format output for easy parsing by make-sync
-	util.Default.ClearLine()
-	util.Default.Printf("[%s] EVENT|%s|%s\n", timestamp, event.Event().String(), event.Path())
+	emitEventLine(fmt.Sprintf("[%s] EVENT|%s|%s", timestamp, event.Event().String(), event.Path()))
 
 	// Calculate file hash using xxHash (only for files that exist)
 	if info, err := os.Stat(event.Path()); err == nil && !info.IsDir() {
@@ -689,22 +912,18 @@ func handleFileEvent(event notify.EventInfo) {
 			fileSizeMB := float64(info.Size()) / (1024 * 1024)
 			limitMB := float64(globalConfig.Devsync.SizeLimit)
 			if fileSizeMB > limitMB {
-				util.Default.ClearLine()
-				util.Default.Printf("[%s] SKIP_SIZE|%s|%.2fMB|limit:%.0fMB\n", timestamp, event.Path(), fileSizeMB, limitMB)
+				emitEventLine(fmt.Sprintf("[%s] SKIP_SIZE|%s|%.2fMB|limit:%.0fMB", timestamp, event.Path(), fileSizeMB, limitMB))
 				return
 			}
 		}
 
 		if hash, err := calculateFileHash(event.Path()); err == nil {
-			util.Default.ClearLine()
-			util.Default.Printf("[%s] HASH|%s|%s\n", timestamp, event.Path(), hash)
+			emitEventLine(fmt.Sprintf("[%s] HASH|%s|%s", timestamp, event.Path(), hash))
 		} else {
-			util.Default.ClearLine()
-			util.Default.Printf("[%s] ERROR|hash_failed|%s|%v\n", timestamp, event.Path(), err)
+			emitEventLine(fmt.Sprintf("[%s] ERROR|hash_failed|%s|%v", timestamp, event.Path(), err))
 		}
 	} else if err != nil {
-		util.Default.ClearLine()
-		util.Default.Printf("[%s] ERROR|stat_failed|%s|%v\n", timestamp, event.Path(), err)
+		emitEventLine(fmt.Sprintf("[%s] ERROR|stat_failed|%s|%v", timestamp, event.Path(), err))
 	}
 
 	// Flush output immediately
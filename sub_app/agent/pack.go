@@ -0,0 +1,245 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sync-agent/internal/indexer"
+)
+
+// Pack/unpack stream files as tar so the client can move many files over a
+// single SSH channel. Per-file results are reported as lines:
+//
+//	PACK|ok|<rel>|<size>     PACK|skip|<rel>|<reason>     PACK|error|<rel>|<err>
+//	UNPACK|ok|<rel>|<size>   UNPACK|skip|<rel>|<reason>   UNPACK|error|<rel>|<err>
+//
+// pack writes the tar stream to stdout, so its report goes to stderr.
+
+// packScope decides which relative paths may be packed or unpacked: they must
+// be clean, inside root, under one of the manual-transfer prefixes (when
+// given), and not ignored by SimpleIgnoreCache.MatchWithManualTransfer.
+type packScope struct {
+	root     string
+	prefixes []string
+	ic       *indexer.SimpleIgnoreCache
+}
+
+func newPackScope(root string, prefixes []string, bypassIgnore bool) *packScope {
+	s := &packScope{root: root}
+	for _, p := range prefixes {
+		p = stringsTrimSlashes(filepath.ToSlash(p))
+		s.prefixes = append(s.prefixes, p)
+	}
+	if !bypassIgnore {
+		s.ic = indexer.NewSimpleIgnoreCache(root)
+	}
+	return s
+}
+
+// check normalizes rel and returns the absolute path, or a skip reason.
+func (s *packScope) check(rel string) (string, string, string) {
+	rel = stringsTrimSlashes(filepath.ToSlash(strings.TrimSpace(rel)))
+	if rel == "" {
+		return "", "", "empty"
+	}
+	clean := filepath.ToSlash(filepath.Clean(rel))
+	if clean != rel || clean == ".." || strings.HasPrefix(clean, "../") || filepath.IsAbs(rel) {
+		return rel, "", "outside_root"
+	}
+	if clean == ".sync_temp" || strings.HasPrefix(clean, ".sync_temp/") {
+		return rel, "", "sync_temp"
+	}
+	if len(s.prefixes) > 0 {
+		inScope := false
+		for _, p := range s.prefixes {
+			if p == "" || clean == p || strings.HasPrefix(clean, p+"/") {
+				inScope = true
+				break
+			}
+		}
+		if !inScope {
+			return rel, "", "outside_prefixes"
+		}
+	}
+	abs := filepath.Join(s.root, filepath.FromSlash(clean))
+	if s.ic != nil && s.ic.MatchWithManualTransfer(abs, false) {
+		return rel, "", "ignored"
+	}
+	return clean, abs, ""
+}
+
+// readFileList reads newline separated relative paths from r.
+func readFileList(r io.Reader) ([]string, error) {
+	var rels []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			rels = append(rels, line)
+		}
+	}
+	return rels, scanner.Err()
+}
+
+// walkPrefixes lists all regular files below the given prefixes (or root).
+func walkPrefixes(scope *packScope) []string {
+	starts := scope.prefixes
+	if len(starts) == 0 {
+		starts = []string{""}
+	}
+	var rels []string
+	seen := map[string]struct{}{}
+	for _, p := range starts {
+		start := filepath.Join(scope.root, filepath.FromSlash(p))
+		_ = filepath.WalkDir(start, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			rel, rerr := filepath.Rel(scope.root, path)
+			if rerr != nil || rel == "." {
+				return nil
+			}
+			rel = filepath.ToSlash(rel)
+			if d.IsDir() {
+				if rel == ".sync_temp" || (scope.ic != nil && scope.ic.MatchWithManualTransfer(path, true)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if _, ok := seen[rel]; !ok && d.Type().IsRegular() {
+				seen[rel] = struct{}{}
+				rels = append(rels, rel)
+			}
+			return nil
+		})
+	}
+	return rels
+}
+
+// performPack writes the requested files (or every file under the prefixes
+// when rels is nil) to out as a tar stream and reports each file to report.
+func performPack(prefixes []string, bypassIgnore bool, rels []string, out io.Writer, report io.Writer) error {
+	root, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working dir: %v", err)
+	}
+	scope := newPackScope(root, prefixes, bypassIgnore)
+	if rels == nil {
+		rels = walkPrefixes(scope)
+	}
+
+	tw := tar.NewWriter(out)
+	for _, raw := range rels {
+		rel, abs, reason := scope.check(raw)
+		if reason != "" {
+			fmt.Fprintf(report, "PACK|skip|%s|%s\n", rel, reason)
+			continue
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			fmt.Fprintf(report, "PACK|error|%s|%v\n", rel, err)
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			fmt.Fprintf(report, "PACK|skip|%s|not_regular_file\n", rel)
+			continue
+		}
+		f, err := os.Open(abs)
+		if err != nil {
+			fmt.Fprintf(report, "PACK|error|%s|%v\n", rel, err)
+			continue
+		}
+		hdr := &tar.Header{
+			Name:     rel,
+			Mode:     int64(info.Mode().Perm()),
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write tar header for %s: %v", rel, err)
+		}
+		// Copy exactly the announced size; a file that shrank mid-copy would
+		// corrupt the stream, so treat that as fatal.
+		n, err := io.CopyN(tw, f, info.Size())
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to stream %s (%d/%d bytes): %v", rel, n, info.Size(), err)
+		}
+		fmt.Fprintf(report, "PACK|ok|%s|%d\n", rel, n)
+	}
+	return tw.Close()
+}
+
+// performUnpack extracts a tar stream from in below the working dir, applying
+// the same scope rules as pack, and reports each entry to report.
+func performUnpack(prefixes []string, bypassIgnore bool, in io.Reader, report io.Writer) error {
+	root, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working dir: %v", err)
+	}
+	scope := newPackScope(root, prefixes, bypassIgnore)
+
+	tr := tar.NewReader(in)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar stream: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		rel, abs, reason := scope.check(hdr.Name)
+		if reason != "" {
+			fmt.Fprintf(report, "UNPACK|skip|%s|%s\n", rel, reason)
+			continue
+		}
+		if err := writeFileAtomic(abs, tr, os.FileMode(hdr.Mode).Perm()); err != nil {
+			fmt.Fprintf(report, "UNPACK|error|%s|%v\n", rel, err)
+			continue
+		}
+		_ = os.Chtimes(abs, hdr.ModTime, hdr.ModTime)
+		fmt.Fprintf(report, "UNPACK|ok|%s|%d\n", rel, hdr.Size)
+	}
+}
+
+// writeFileAtomic writes r to a temp file next to path and renames it into place.
+func writeFileAtomic(path string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if perm == 0 {
+		perm = 0644
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".unpack-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
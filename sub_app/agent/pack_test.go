@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPackUnpackRoundTrip(t *testing.T) {
+	src, err := os.MkdirTemp("", "agent-pack-src-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(src)
+	dst, err := os.MkdirTemp("", "agent-pack-dst-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dst)
+
+	files := map[string]string{
+		"vendor/a.txt":     "alpha",
+		"vendor/sub/b.txt": "beta",
+		"other/c.txt":      "gamma",
+	}
+	for rel, content := range files {
+		p := filepath.Join(src, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	oldwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer os.Chdir(oldwd)
+
+	if err := os.Chdir(src); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	var stream, report bytes.Buffer
+	rels := []string{"vendor/a.txt", "vendor/sub/b.txt", "other/c.txt", "../escape.txt", "vendor/missing.txt"}
+	if err := performPack([]string{"vendor"}, false, rels, &stream, &report); err != nil {
+		t.Fatalf("performPack failed: %v", err)
+	}
+	rep := report.String()
+	for _, want := range []string{
+		"PACK|ok|vendor/a.txt|5",
+		"PACK|ok|vendor/sub/b.txt|4",
+		"PACK|skip|other/c.txt|outside_prefixes",
+		"PACK|skip|../escape.txt|outside_root",
+		"PACK|error|vendor/missing.txt|",
+	} {
+		if !strings.Contains(rep, want) {
+			t.Fatalf("expected report to contain %q, got:\n%s", want, rep)
+		}
+	}
+
+	if err := os.Chdir(dst); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	var unpackReport bytes.Buffer
+	if err := performUnpack(nil, false, &stream, &unpackReport); err != nil {
+		t.Fatalf("performUnpack failed: %v", err)
+	}
+	for _, rel := range []string{"vendor/a.txt", "vendor/sub/b.txt"} {
+		got, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(rel)))
+		if err != nil || string(got) != files[rel] {
+			t.Fatalf("expected %s to be unpacked with %q, got %q (%v)", rel, files[rel], got, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "other", "c.txt")); err == nil {
+		t.Fatalf("other/c.txt must not be unpacked")
+	}
+}
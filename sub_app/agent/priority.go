@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sync-agent/internal/indexer"
+	"sync-agent/internal/util"
+)
+
+// IO priority classes as used by Linux ioprio_set (and ionice -c)
+const (
+	ioClassBestEffort = 2
+	ioClassIdle       = 3
+)
+
+// parseIONice parses an ionice spec: "idle", "best-effort", "best-effort:<0-7>"
+// or a bare best-effort level "<0-7>". An empty spec leaves IO priority alone.
+func parseIONice(spec string) (int, int, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" {
+		return 0, 0, nil
+	}
+	if spec == "idle" {
+		return ioClassIdle, 0, nil
+	}
+	levelStr := spec
+	if strings.HasPrefix(spec, "best-effort") {
+		levelStr = strings.TrimPrefix(strings.TrimPrefix(spec, "best-effort"), ":")
+		if levelStr == "" {
+			return ioClassBestEffort, 7, nil
+		}
+	}
+	level, err := strconv.Atoi(levelStr)
+	if err != nil || level < 0 || level > 7 {
+		return 0, 0, fmt.Errorf("invalid ionice %q (want idle, best-effort[:0-7] or 0-7)", spec)
+	}
+	return ioClassBestEffort, level, nil
+}
+
+// applyIndexingThrottle lowers process priority and configures the indexer
+// hashing limits. Priority failures are reported but not fatal.
+func applyIndexingThrottle(nice int, ionice string, workers int, readBps int64) {
+	ioClass, ioLevel, err := parseIONice(ionice)
+	if err != nil {
+		util.Default.ClearLine()
+		util.Default.Printf("⚠️  %v\n", err)
+	}
+	if nice != 0 || ioClass != 0 {
+		if err := applyProcessPriority(nice, ioClass, ioLevel); err != nil {
+			util.Default.ClearLine()
+			util.Default.Printf("⚠️  Failed to lower process priority: %v\n", err)
+		} else {
+			util.Default.ClearLine()
+			util.Default.Printf("🐢 Process priority lowered (nice=%d ionice=%q)\n", nice, ionice)
+		}
+	}
+
+	indexer.SetThrottle(indexer.Throttle{Workers: workers, ReadBytesPerSec: readBps})
+	if workers > 1 || readBps > 0 {
+		util.Default.ClearLine()
+		util.Default.Printf("🐢 Hashing throttle: workers=%d read_bytes_per_sec=%d\n", workers, readBps)
+	}
+}
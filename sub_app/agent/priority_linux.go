@@ -0,0 +1,31 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+)
+
+// applyProcessPriority lowers the CPU (nice) and IO (ionice) priority of the
+// agent process. Zero values leave the respective priority untouched.
+func applyProcessPriority(nice int, ioClass, ioLevel int) error {
+	if nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0, nice); err != nil {
+			return fmt.Errorf("setpriority(%d): %v", nice, err)
+		}
+	}
+	if ioClass != 0 {
+		prio := uintptr(ioClass<<ioprioClassShift | ioLevel)
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, 0, prio); errno != 0 {
+			return fmt.Errorf("ioprio_set(class=%d, level=%d): %v", ioClass, ioLevel, errno)
+		}
+	}
+	return nil
+}
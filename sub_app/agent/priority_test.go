@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestParseIONice(t *testing.T) {
+	cases := []struct {
+		spec         string
+		class, level int
+		wantErr      bool
+	}{
+		{"", 0, 0, false},
+		{"idle", ioClassIdle, 0, false},
+		{"best-effort", ioClassBestEffort, 7, false},
+		{"best-effort:4", ioClassBestEffort, 4, false},
+		{"5", ioClassBestEffort, 5, false},
+		{"8", 0, 0, true},
+		{"realtime", 0, 0, true},
+	}
+	for _, c := range cases {
+		class, level, err := parseIONice(c.spec)
+		if (err != nil) != c.wantErr {
+			t.Fatalf("parseIONice(%q) err=%v, wantErr=%v", c.spec, err, c.wantErr)
+		}
+		if err == nil && (class != c.class || level != c.level) {
+			t.Fatalf("parseIONice(%q) = (%d,%d), want (%d,%d)", c.spec, class, level, c.class, c.level)
+		}
+	}
+}
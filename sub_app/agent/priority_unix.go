@@ -0,0 +1,20 @@
+//go:build !windows && !linux
+// +build !windows,!linux
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// applyProcessPriority lowers the CPU (nice) priority of the agent process.
+// IO priority classes are Linux specific and ignored here.
+func applyProcessPriority(nice int, ioClass, ioLevel int) error {
+	if nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0, nice); err != nil {
+			return fmt.Errorf("setpriority(%d): %v", nice, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// Windows priority classes used as the nice/ionice equivalent
+const (
+	BELOW_NORMAL_PRIORITY_CLASS   = 0x00004000
+	IDLE_PRIORITY_CLASS           = 0x00000040
+	PROCESS_MODE_BACKGROUND_BEGIN = 0x00100000
+	niceIdleThreshold             = 15
+)
+
+var procSetPriorityClass = syscall.NewLazyDLL("kernel32.dll").NewProc("SetPriorityClass")
+
+// applyProcessPriority maps nice to a priority class (below normal, or idle
+// for nice >= 15) and the idle IO class to background processing mode, which
+// also lowers IO and memory priority.
+func applyProcessPriority(nice int, ioClass, ioLevel int) error {
+	self, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return err
+	}
+	if nice > 0 {
+		class := uintptr(BELOW_NORMAL_PRIORITY_CLASS)
+		if nice >= niceIdleThreshold {
+			class = IDLE_PRIORITY_CLASS
+		}
+		if r, _, e := procSetPriorityClass.Call(uintptr(self), class); r == 0 {
+			return fmt.Errorf("SetPriorityClass(%#x): %v", class, e)
+		}
+	}
+	if ioClass == ioClassIdle {
+		if r, _, e := procSetPriorityClass.Call(uintptr(self), PROCESS_MODE_BACKGROUND_BEGIN); r == 0 {
+			return fmt.Errorf("SetPriorityClass(background): %v", e)
+		}
+	}
+	return nil
+}